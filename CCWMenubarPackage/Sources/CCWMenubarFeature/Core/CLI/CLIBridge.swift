@@ -106,6 +106,15 @@ public actor CLIBridge {
         return try decoder.decode([String: DepStatus].self, from: output)
     }
 
+    public func doctor(fix: Bool = false) async throws -> [DoctorIssue] {
+        var args = ["doctor", "--json"]
+        if fix {
+            args.append("--fix")
+        }
+        let output = try await execute(args)
+        return try decoder.decode([DoctorIssue].self, from: output)
+    }
+
     public func getConfig() async throws -> CCWConfig {
         let configPath = FileManager.default.homeDirectoryForCurrentUser
             .appendingPathComponent(".ccw/config.json")
@@ -4,4 +4,24 @@ public struct DepStatus: Codable, Sendable {
     public let installed: Bool
     public let path: String
     public let optional: Bool?
+    public let claude: ClaudeDetection?
+    public let error: String?
+}
+
+public struct ClaudeDetection: Codable, Sendable {
+    public let version: String?
+    public let source: String
+    public let capabilities: Capabilities
+
+    public struct Capabilities: Codable, Sendable {
+        public let supportsResume: Bool
+        public let supportsSessionID: Bool
+        public let sessionNameFlag: String?
+
+        public enum CodingKeys: String, CodingKey {
+            case supportsResume = "supports_resume"
+            case supportsSessionID = "supports_session_id"
+            case sessionNameFlag = "session_name_flag"
+        }
+    }
 }
@@ -0,0 +1,23 @@
+import Foundation
+
+public struct DoctorIssue: Codable, Sendable, Hashable {
+    public let code: String
+    public let workspace: String?
+    public let path: String?
+    public let session: String?
+    public let message: String
+    public let fix: String?
+    public let fixed: Bool
+    public let fixError: String?
+
+    public enum CodingKeys: String, CodingKey {
+        case code
+        case workspace
+        case path
+        case session
+        case message
+        case fix
+        case fixed
+        case fixError = "fix_error"
+    }
+}
@@ -10,6 +10,10 @@ public struct Workspace: Codable, Sendable, Hashable {
     public let tmuxSession: String
     public let createdAt: Date
     public let lastAccessedAt: Date
+    public let tags: [String]?
+    public let notes: String?
+    public let archived: Bool?
+    public let parent: String?
 
     public enum CodingKeys: String, CodingKey {
         case repo
@@ -21,6 +25,10 @@ public struct Workspace: Codable, Sendable, Hashable {
         case tmuxSession = "tmux_session"
         case createdAt = "created_at"
         case lastAccessedAt = "last_accessed_at"
+        case tags
+        case notes
+        case archived
+        case parent
     }
 }
 
@@ -29,12 +37,23 @@ public struct WorkspaceStatus: Codable, Identifiable, Sendable, Hashable {
     public let workspace: Workspace
     public let sessionAlive: Bool
     public let hasClients: Bool
+    /// What the workspace's agents are doing: "working", "idle", "waiting",
+    /// "errored", "stopped" or "unknown". Nil when the session isn't running.
+    public let activity: String?
+    public let agents: [AgentActivity]?
 
     public enum CodingKeys: String, CodingKey {
         case id = "ID"
         case workspace = "Workspace"
         case sessionAlive = "SessionAlive"
         case hasClients = "HasClients"
+        case activity = "Activity"
+        case agents = "Agents"
+    }
+
+    /// Whether an agent is blocked on the user.
+    public var needsAttention: Bool {
+        activity == "waiting" || activity == "errored"
     }
 
     public var state: WorkspaceState {
@@ -44,6 +63,13 @@ public struct WorkspaceStatus: Codable, Identifiable, Sendable, Hashable {
     }
 }
 
+public struct AgentActivity: Codable, Sendable, Hashable {
+    public let pane: String
+    public let agent: String
+    public let state: String
+    public let reason: String?
+}
+
 public enum WorkspaceState {
     case connected
     case alive
@@ -32,6 +32,38 @@ private func makeDecoder() -> JSONDecoder {
     #expect(status.workspace.repoPath == "/Users/me/dev/repo")
     #expect(status.sessionAlive == true)
     #expect(status.hasClients == false)
+    #expect(status.activity == nil)
+}
+
+@Test func decodeWorkspaceActivity() throws {
+    let json = """
+    {
+      "ID": "repo/branch",
+      "Workspace": {
+        "repo": "repo",
+        "repo_path": "/Users/me/dev/repo",
+        "branch": "branch",
+        "base_branch": "main",
+        "worktree_path": "/Users/me/dev/repo-branch",
+        "claude_session": "ccw-repo-branch",
+        "tmux_session": "ccw-repo-branch",
+        "created_at": "2024-01-01T12:00:00Z",
+        "last_accessed_at": "2024-01-10T09:30:00Z"
+      },
+      "SessionAlive": true,
+      "HasClients": false,
+      "Activity": "waiting",
+      "Agents": [
+        { "pane": "left", "agent": "claude", "state": "waiting", "reason": "permission prompt" },
+        { "pane": "right", "agent": "codex", "state": "idle" }
+      ]
+    }
+    """
+    let data = Data(json.utf8)
+    let status = try makeDecoder().decode(WorkspaceStatus.self, from: data)
+    #expect(status.needsAttention)
+    #expect(status.agents?.count == 2)
+    #expect(status.agents?[1].reason == nil)
 }
 
 @Test func decodeStaleList() throws {
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Register worktrees and tmux sessions created outside ccw",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		repoFilter, _ := cmd.Flags().GetString("repo")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		candidates, err := mgr.AdoptCandidates(cmd.Context(), repoFilter)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "nothing to adopt")
+			return nil
+		}
+
+		chosen := candidates
+		if !all {
+			if f, ok := cmd.InOrStdin().(*os.File); ok && !term.IsTerminal(int(f.Fd())) {
+				return fmt.Errorf("found %d worktrees to adopt; pass --all when not running interactively", len(candidates))
+			}
+			chosen, err = pickCandidates(cmd, candidates)
+			if err != nil {
+				return err
+			}
+			if len(chosen) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "nothing adopted")
+				return nil
+			}
+		}
+
+		if err := mgr.Adopt(cmd.Context(), chosen); err != nil {
+			return err
+		}
+		for _, c := range chosen {
+			fmt.Fprintf(cmd.OutOrStdout(), "adopted %s\n", c.ID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().Bool("all", false, "Adopt every worktree found without asking")
+	adoptCmd.Flags().String("repo", "", "Only scan this repository")
+}
+
+// pickCandidates lists the candidates and reads a selection such as "1,3",
+// "2-4" or "all". An empty answer adopts nothing.
+func pickCandidates(cmd *cobra.Command, candidates []workspace.AdoptCandidate) ([]workspace.AdoptCandidate, error) {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tWORKSPACE\tSESSION\tWORKTREE")
+	for i, c := range candidates {
+		session := c.Session
+		if session == "" {
+			session = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, c.ID, session, c.Workspace.WorktreePath)
+	}
+	w.Flush()
+
+	fmt.Fprint(cmd.OutOrStdout(), "Adopt which? (e.g. 1,3 or 2-4 or all) ")
+	reader := bufio.NewReader(cmd.InOrStdin())
+	answer, _ := reader.ReadString('\n')
+
+	indices, err := parseSelection(strings.TrimSpace(answer), len(candidates))
+	if err != nil {
+		return nil, err
+	}
+	chosen := make([]workspace.AdoptCandidate, 0, len(indices))
+	for _, i := range indices {
+		chosen = append(chosen, candidates[i])
+	}
+	return chosen, nil
+}
+
+// parseSelection turns "1,3-4" into zero-based indices below n.
+func parseSelection(answer string, n int) ([]int, error) {
+	if answer == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(answer, "all") {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	seen := map[int]bool{}
+	var indices []int
+	for _, part := range strings.Split(answer, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid selection %q", part)
+			}
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("selection %q out of range 1-%d", part, n)
+		}
+		for i := start; i <= end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indices = append(indices, i-1)
+			}
+		}
+	}
+	return indices, nil
+}
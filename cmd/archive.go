@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [workspace]",
+	Short: "Free a workspace's worktree and session but keep its branch (defaults to the current one)",
+	Long: `Archive kills the workspace's tmux session and removes its worktree
+directory. The branch and the registry entry, including the agent
+conversation, are kept so ` + "`ccw restore`" + ` can bring the workspace back.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+
+		if err := mgr.ArchiveWorkspace(cmd.Context(), id, workspace.ArchiveOptions{Force: force}); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "archived workspace %s (restore with `ccw restore %s`)\n", id, id)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.Flags().Bool("force", false, "Archive even if the worktree has uncommitted changes (they are lost)")
+}
@@ -3,8 +3,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/claude"
+	"github.com/ccw/ccw/internal/config"
 	"github.com/ccw/ccw/internal/deps"
+	"github.com/ccw/ccw/internal/forge"
 	"github.com/spf13/cobra"
 )
 
@@ -12,15 +17,42 @@ type DepStatus struct {
 	Installed bool   `json:"installed"`
 	Path      string `json:"path"`
 	Optional  bool   `json:"optional,omitempty"`
+	// Claude is the detected version and capabilities, on the claude entry
+	// only.
+	Claude *claude.Detection `json:"claude,omitempty"`
+	// Error says why the config could not be loaded, on the config entry
+	// only.
+	Error string `json:"error,omitempty"`
 }
 
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check dependencies",
-	Args:  cobra.NoArgs,
+	Long: `Check that the tools ccw uses are installed, and that its config loads.
+A broken config is reported as the "config" entry rather than stopping the
+check.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// The manager needs a loadable config, so check without one.
+		store, err := config.NewStore(os.Getenv("CCW_HOME"))
+		if err != nil {
+			return err
+		}
 		result := make(map[string]DepStatus)
-		for _, d := range deps.DefaultDependencies() {
+		agents := agent.Default()
+		cfg, err := store.Load()
+		if err != nil {
+			result["config"] = DepStatus{Path: store.Path(), Error: err.Error()}
+		} else {
+			result["config"] = DepStatus{Installed: true, Path: store.Path()}
+			agents = agent.FromConfig(cfg.Agents)
+		}
+
+		all := append(deps.DefaultDependencies(), forge.Dependencies()...)
+		for _, a := range agents.All() {
+			all = append(all, a.Dependency())
+		}
+		for _, d := range all {
 			res := deps.Check(d)
 			result[d.Name] = DepStatus{
 				Installed: res.Found,
@@ -29,6 +61,13 @@ var checkCmd = &cobra.Command{
 			}
 		}
 
+		if st, ok := result["claude"]; ok && st.Installed {
+			if d, err := claude.Detect(cmd.Context(), store.Root()); err == nil {
+				st.Claude = &d
+				result["claude"] = st
+			}
+		}
+
 		showJSON, _ := cmd.Flags().GetBool("json")
 		if showJSON {
 			return json.NewEncoder(cmd.OutOrStdout()).Encode(result)
@@ -36,6 +75,18 @@ var checkCmd = &cobra.Command{
 		for name, st := range result {
 			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%t\t%s\n", name, st.Installed, st.Path)
 		}
+		if st := result["config"]; st.Error != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nconfig: %s\n", st.Error)
+		}
+		if st := result["claude"]; st.Claude != nil {
+			d := st.Claude
+			version := d.Version
+			if version == "" {
+				version = "unknown version"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\nclaude %s (capabilities from %s): resume=%t session-id=%t name-flag=%s\n",
+				version, d.Source, d.Capabilities.SupportsResume, d.Capabilities.SupportsSessionID, valueOrDash(d.Capabilities.SessionNameFlag))
+		}
 		return nil
 	},
 }
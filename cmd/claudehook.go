@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var claudeHookCmd = &cobra.Command{
+	Use:   "claude-hook",
+	Short: "Record a Claude Code hook event for the activity shown by ccw ls",
+	Long: `Read a Claude Code hook event as JSON on stdin and record what it says
+about the agent's activity (working, idle, waiting for permission) for the
+workspace the hook runs in. Outside a ccw workspace it does nothing.
+
+Register it for the UserPromptSubmit, PreToolUse, Stop and Notification
+events in ~/.claude/settings.json; see the README.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+		return mgr.RecordClaudeHook(cmd.Context(), cmd.InOrStdin())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(claudeHookCmd)
+}
@@ -1,10 +1,8 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 
-	"github.com/ccw/ccw/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -18,26 +16,23 @@ var closeCmd = &cobra.Command{
 			return err
 		}
 
-		var id string
-		if len(args) == 1 {
-			id = args[0]
-		} else {
-			curID, _, err := mgr.FindCurrent(cmd.Context())
-			if err != nil {
-				if errors.Is(err, workspace.ErrNoCurrentWorkspace) {
-					return fmt.Errorf("not inside a ccw workspace; pass a workspace id (ccw close <workspace>) or cd into one")
-				}
-				return err
-			}
-			id = curID
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "closing workspace %s\n", id)
+		events := startEvents(cmd, mgr)
+		if events == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "closing workspace %s\n", id)
+		}
 
 		if err := mgr.CloseWorkspace(cmd.Context(), id); err != nil {
 			return err
 		}
 
+		if events != nil {
+			return events.done(id, nil)
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "closed workspace %s\n", id)
 		return nil
 	},
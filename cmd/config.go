@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ccw/ccw/internal/config"
@@ -66,6 +67,16 @@ func printConfig(cmd *cobra.Command, cfg config.Config) {
 		fmt.Sprintf("layout.right=%s", cfg.Layout.Right),
 		fmt.Sprintf("claude_dangerously_skip_permissions=%t", cfg.ClaudeDangerouslySkipPerms),
 		fmt.Sprintf("onboarded=%t", cfg.Onboarded),
+		fmt.Sprintf("sync_strategy=%s", cfg.SyncStrategy),
+		fmt.Sprintf("agent_ready_timeout=%d", cfg.AgentReadyTimeout),
+	}
+	hosts := make([]string, 0, len(cfg.Forges))
+	for host := range cfg.Forges {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		builder = append(builder, fmt.Sprintf("forges.%s=%s", host, cfg.Forges[host]))
 	}
 	fmt.Fprintln(cmd.OutOrStdout(), strings.Join(builder, "\n"))
 }
@@ -86,7 +97,14 @@ func configValue(cfg config.Config, key string) (string, error) {
 		return fmt.Sprintf("%t", cfg.ClaudeDangerouslySkipPerms), nil
 	case "onboarded":
 		return fmt.Sprintf("%t", cfg.Onboarded), nil
+	case "sync_strategy":
+		return cfg.SyncStrategy, nil
+	case "agent_ready_timeout":
+		return fmt.Sprintf("%d", cfg.AgentReadyTimeout), nil
 	default:
+		if host, ok := strings.CutPrefix(key, "forges."); ok && host != "" {
+			return cfg.Forges[strings.ToLower(host)], nil
+		}
 		return "", fmt.Errorf("unknown config key: %s", key)
 	}
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the registry against git worktrees, branches and tmux",
+	Long: `Cross-check every workspace against its worktree, branch and tmux session,
+and look for worktree directories and sessions that no workspace owns.
+
+With --fix, missing worktrees are recreated (or the workspace is dropped when
+its branch is gone too), stale git worktree records are pruned, lost
+worktrees are re-registered and orphan sessions are killed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fix, _ := cmd.Flags().GetBool("fix")
+		showJSON, _ := cmd.Flags().GetBool("json")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		issues, err := mgr.Doctor(cmd.Context(), workspace.DoctorOptions{Fix: fix})
+		if err != nil {
+			return err
+		}
+
+		if showJSON {
+			if issues == nil {
+				issues = []workspace.DoctorIssue{}
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(issues)
+		}
+
+		out := cmd.OutOrStdout()
+		if len(issues) == 0 {
+			fmt.Fprintln(out, "no problems found")
+			return nil
+		}
+
+		fixable := 0
+		for _, issue := range issues {
+			subject := issue.Workspace
+			if subject == "" {
+				subject = issue.Path
+			}
+			fmt.Fprintf(out, "%s %s: %s\n", color.New(color.FgYellow).Sprint(issue.Code), subject, issue.Message)
+			switch {
+			case issue.Fixed:
+				fmt.Fprintf(out, "  %s %s\n", color.New(color.FgGreen).Sprint("fixed:"), issue.Fix)
+			case issue.FixError != "":
+				fmt.Fprintf(out, "  %s %s: %s\n", color.New(color.FgRed).Sprint("fix failed:"), issue.Fix, issue.FixError)
+			case issue.Fix != "":
+				fixable++
+				fmt.Fprintf(out, "  fix: %s\n", issue.Fix)
+			}
+		}
+		if fixable > 0 {
+			fmt.Fprintf(out, "\nrun `ccw doctor --fix` to apply %d fix(es)\n", fixable)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix", false, "Repair what can be repaired")
+	doctorCmd.Flags().Bool("json", false, "Output as JSON")
+}
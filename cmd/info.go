@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -39,9 +40,41 @@ var infoCmd = &cobra.Command{
 		fmt.Fprintf(w, "Worktree:\t%s\n", status.Workspace.WorktreePath)
 		fmt.Fprintf(w, "Branch:\t%s\n", status.Workspace.Branch)
 		fmt.Fprintf(w, "Base:\t%s\n", status.Workspace.BaseBranch)
+		if status.Workspace.Parent != "" {
+			fmt.Fprintf(w, "Stacked On:\t%s\n", status.Workspace.Parent)
+		}
 		fmt.Fprintf(w, "Claude Session:\t%s\n", status.Workspace.ClaudeSession)
 		fmt.Fprintf(w, "Tmux Session:\t%s\n", status.Workspace.TmuxSession)
 		fmt.Fprintf(w, "Session Alive:\t%t\n", status.SessionAlive)
+		for _, a := range status.Agents {
+			fmt.Fprintf(w, "Agent %s:\t%s (%s)\n", a.Pane, a.State, valueOrDash(a.Reason))
+		}
+		if status.Workspace.Archived {
+			fmt.Fprintf(w, "Archived:\t%s\n", status.Workspace.ArchivedAt.Format(time.RFC3339))
+		}
+		if pr := status.Workspace.PR; pr != nil {
+			fmt.Fprintf(w, "PR:\t#%d %s (%s, as of %s)\n", pr.Number, pr.State, pr.URL, pr.CheckedAt.Format(time.RFC3339))
+		} else {
+			fmt.Fprintf(w, "PR:\t-\n")
+		}
+		if g := status.Git; g != nil {
+			fmt.Fprintf(w, "Changes:\t%d uncommitted\n", g.Changes)
+			if g.HasUpstream {
+				fmt.Fprintf(w, "Upstream:\t%d ahead, %d behind origin/%s\n", g.AheadUpstream, g.BehindUpstream, status.Workspace.Branch)
+			} else {
+				fmt.Fprintf(w, "Upstream:\tnot pushed\n")
+			}
+			fmt.Fprintf(w, "Base Status:\t%d ahead, %d behind\n", g.AheadBase, g.BehindBase)
+			if !g.LastCommitAt.IsZero() {
+				fmt.Fprintf(w, "Last Commit:\t%s (%s)\n", g.LastCommitSubject, g.LastCommitAt.Format(time.RFC3339))
+			}
+			fmt.Fprintf(w, "Merged:\t%t\n", g.Merged)
+			if g.Error != "" {
+				fmt.Fprintf(w, "Git Error:\t%s\n", g.Error)
+			}
+		}
+		fmt.Fprintf(w, "Tags:\t%s\n", valueOrDash(strings.Join(status.Workspace.Tags, ", ")))
+		fmt.Fprintf(w, "Notes:\t%s\n", valueOrDash(strings.Join(strings.Fields(status.Workspace.Notes), " ")))
 		fmt.Fprintf(w, "Created:\t%s\n", status.Workspace.CreatedAt.Format(time.RFC3339))
 		fmt.Fprintf(w, "Last Accessed:\t%s\n", status.Workspace.LastAccessedAt.Format(time.RFC3339))
 		w.Flush()
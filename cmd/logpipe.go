@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var logPipeCmd = &cobra.Command{
+	Use:   "log-pipe <file>",
+	Short: "Append stdin to a pane log, rotating it when it grows too big",
+	Long: `Append stdin to a pane log until it ends. ccw pipes each pane's output
+through this to keep its log under ~/.ccw/logs from growing without bound.`,
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return workspace.AppendLog(cmd.InOrStdin(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logPipeCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [workspace]",
+	Short: "Print or follow a workspace pane's output log",
+	Long: `Every pane's output is logged under ~/.ccw/logs/<session>/ while its
+session runs. The logs are kept when the session is closed or crashes, so an
+agent's last output can still be read, and are deleted by ccw rm. A log that
+grows past 10 MB is moved to <pane>.log.1, replacing the previous one.
+
+Logs hold the raw terminal output, so they are best read in a terminal.
+Defaults to the first agent pane; --pane takes a pane number, "agent",
+"shell", an agent name or a layout pane name.`,
+	Example: `  ccw logs myrepo/feature -f
+  ccw logs --pane shell --lines 0`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+
+		var opts workspace.LogOptions
+		opts.Pane, _ = cmd.Flags().GetString("pane")
+		opts.Lines, _ = cmd.Flags().GetInt("lines")
+		opts.Follow, _ = cmd.Flags().GetBool("follow")
+
+		path, err := mgr.PaneLog(cmd.Context(), id, opts.Pane)
+		if err != nil {
+			return err
+		}
+		return workspace.StreamLog(cmd.Context(), cmd.OutOrStdout(), path, opts)
+	},
+}
+
+func init() {
+	logsCmd.Flags().String("pane", "", "Pane to show (default: the first agent pane)")
+	logsCmd.Flags().IntP("lines", "n", 200, "Print the last N lines of the log (0 for all of it)")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing output as it's logged")
+	rootCmd.AddCommand(logsCmd)
+}
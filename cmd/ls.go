@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -24,20 +25,18 @@ var lsCmd = &cobra.Command{
 		showAll, _ := cmd.Flags().GetBool("all")
 		showJSON, _ := cmd.Flags().GetBool("json")
 		repoFilter, _ := cmd.Flags().GetString("repo")
+		tagFilter, _ := cmd.Flags().GetStringSlice("tag")
+		showTree, _ := cmd.Flags().GetBool("tree")
 
-		statuses, err := mgr.ListWorkspaces(cmd.Context())
+		statuses, err := mgr.ListWorkspaces(cmd.Context(), workspace.ListOptions{GitStatus: showAll, Activity: true})
 		if err != nil {
 			return err
 		}
 
 		// Track global indices before filtering (1-based, matching lookupWorkspace)
-		type indexedStatus struct {
-			Index  int
-			Status workspace.WorkspaceStatus
-		}
 		indexed := make([]indexedStatus, 0, len(statuses))
 		for i, st := range statuses {
-			if repoFilter == "" || st.Workspace.Repo == repoFilter {
+			if (repoFilter == "" || st.Workspace.Repo == repoFilter) && st.Workspace.HasTags(tagFilter...) {
 				indexed = append(indexed, indexedStatus{Index: i + 1, Status: st})
 			}
 		}
@@ -55,28 +54,39 @@ var lsCmd = &cobra.Command{
 
 		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
 		if showAll {
-			fmt.Fprintln(w, "#\tWORKSPACE\tSTATUS\tLAST ACCESSED\tWORKTREE\tBRANCH")
+			fmt.Fprintln(w, "#\tWORKSPACE\tSTATUS\tAGENT\tPR\tLAST ACCESSED\tWORKTREE\tBRANCH\tGIT\tLAST COMMIT\tTAGS")
 		} else {
-			fmt.Fprintln(w, "#\tWORKSPACE\tSTATUS\tLAST ACCESSED")
+			fmt.Fprintln(w, "#\tWORKSPACE\tSTATUS\tAGENT\tPR\tLAST ACCESSED")
+		}
+
+		if showTree {
+			indexed = stackOrder(indexed)
 		}
 
 		for _, is := range indexed {
 			st := is.Status
+			name := st.ID
+			if showTree && is.Depth > 0 {
+				name = strings.Repeat("   ", is.Depth-1) + "└─ " + name
+			}
 			status := "dead"
 			if st.SessionAlive {
 				status = "alive"
 			}
 			coloredStatus := status
-			if st.SessionAlive {
+			switch {
+			case st.Workspace.Archived:
+				coloredStatus = color.New(color.FgHiBlack).Sprint("archived")
+			case st.SessionAlive:
 				coloredStatus = color.New(color.FgGreen).Sprint(status)
-			} else {
+			default:
 				coloredStatus = color.New(color.FgRed).Sprint(status)
 			}
 			last := st.Workspace.LastAccessedAt.Format(time.RFC3339)
 			if showAll {
-				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", is.Index, st.ID, coloredStatus, last, st.Workspace.WorktreePath, st.Workspace.Branch)
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", is.Index, name, coloredStatus, activityLabel(st), prLabel(st.Workspace), last, st.Workspace.WorktreePath, st.Workspace.Branch, gitLabel(st.Git), lastCommitLabel(st.Git), valueOrDash(strings.Join(st.Workspace.Tags, ",")))
 			} else {
-				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", is.Index, st.ID, coloredStatus, last)
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", is.Index, name, coloredStatus, activityLabel(st), prLabel(st.Workspace), last)
 			}
 		}
 
@@ -88,6 +98,125 @@ var lsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(lsCmd)
 	lsCmd.Flags().BoolP("all", "a", false, "Show all details")
-	lsCmd.Flags().Bool("json", false, "Output as JSON")
+	lsCmd.Flags().Bool("json", false, "Output as JSON (with -a, including git status)")
 	lsCmd.Flags().String("repo", "", "Filter by repository")
+	lsCmd.Flags().Bool("tree", false, "Show stacked workspaces under the workspace they're stacked on")
+	lsCmd.Flags().StringSlice("tag", nil, "Only show workspaces with this tag (repeatable; all must match)")
+}
+
+type indexedStatus struct {
+	Index  int
+	Status workspace.WorkspaceStatus
+	// Depth is how deep in a stack the workspace sits, for --tree.
+	Depth int
+}
+
+// stackOrder lists each workspace followed by the ones stacked on it,
+// depth first. Workspaces whose parent isn't listed are roots.
+func stackOrder(rows []indexedStatus) []indexedStatus {
+	listed := make(map[string]bool, len(rows))
+	children := make(map[string][]indexedStatus)
+	for _, r := range rows {
+		listed[r.Status.ID] = true
+	}
+	var roots []indexedStatus
+	for _, r := range rows {
+		if parent := r.Status.Workspace.Parent; parent != "" && listed[parent] {
+			children[parent] = append(children[parent], r)
+		} else {
+			roots = append(roots, r)
+		}
+	}
+
+	ordered := make([]indexedStatus, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	var visit func(r indexedStatus, depth int)
+	visit = func(r indexedStatus, depth int) {
+		if seen[r.Status.ID] {
+			return
+		}
+		seen[r.Status.ID] = true
+		r.Depth = depth
+		ordered = append(ordered, r)
+		for _, c := range children[r.Status.ID] {
+			visit(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		visit(r, 0)
+	}
+	// A hand-edited registry could contain a cycle; don't drop its members.
+	for _, r := range rows {
+		visit(r, 0)
+	}
+	return ordered
+}
+
+// gitLabel condenses a GitStatus into one column, e.g.
+// "2 changed, origin +1/-0, base +3/-5".
+func gitLabel(g *workspace.GitStatus) string {
+	if g == nil {
+		return "-"
+	}
+	parts := []string{"clean"}
+	if g.Changes > 0 {
+		parts[0] = fmt.Sprintf("%d changed", g.Changes)
+	}
+	if g.HasUpstream {
+		parts = append(parts, fmt.Sprintf("origin +%d/-%d", g.AheadUpstream, g.BehindUpstream))
+	} else {
+		parts = append(parts, "unpushed")
+	}
+	parts = append(parts, fmt.Sprintf("base +%d/-%d", g.AheadBase, g.BehindBase))
+	if g.Merged {
+		parts = append(parts, "merged")
+	}
+	if g.Error != "" {
+		parts = append(parts, "error")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// activityLabel shows what a workspace's agents are doing, colored by how
+// much they need the user.
+func activityLabel(st workspace.WorkspaceStatus) string {
+	switch st.Activity {
+	case "":
+		return "-"
+	case workspace.ActivityWaiting:
+		return color.New(color.FgYellow, color.Bold).Sprint(st.Activity)
+	case workspace.ActivityErrored:
+		return color.New(color.FgRed).Sprint(st.Activity)
+	case workspace.ActivityWorking:
+		return color.New(color.FgCyan).Sprint(st.Activity)
+	case workspace.ActivityIdle:
+		return color.New(color.FgGreen).Sprint(st.Activity)
+	}
+	return color.New(color.FgHiBlack).Sprint(st.Activity)
+}
+
+func lastCommitLabel(g *workspace.GitStatus) string {
+	if g == nil || g.LastCommitAt.IsZero() {
+		return "-"
+	}
+	subject := g.LastCommitSubject
+	if r := []rune(subject); len(r) > 40 {
+		subject = string(r[:39]) + "…"
+	}
+	return fmt.Sprintf("%s (%s)", subject, timeAgo(g.LastCommitAt))
+}
+
+// timeAgo renders how long ago t was at a coarse resolution.
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
 }
@@ -1,9 +1,29 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
 )
 
 func newManager() (*workspace.Manager, error) {
 	return workspace.NewManager("", nil)
 }
+
+// workspaceArg returns the workspace named in args, or the one the caller is
+// inside when args is empty.
+func workspaceArg(cmd *cobra.Command, mgr *workspace.Manager, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	id, _, err := mgr.FindCurrent(cmd.Context())
+	if err != nil {
+		if errors.Is(err, workspace.ErrNoCurrentWorkspace) {
+			return "", fmt.Errorf("%w; pass a workspace id (%s <workspace>) or cd into one", err, cmd.CommandPath())
+		}
+		return "", err
+	}
+	return id, nil
+}
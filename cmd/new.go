@@ -4,42 +4,84 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ccw/ccw/internal/config"
 	"github.com/ccw/ccw/internal/deps"
 	"github.com/ccw/ccw/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var newCmd = &cobra.Command{
-	Use:   "new <repo> <branch>",
+	Use:   "new <repo> [branch]",
 	Short: "Create a new workspace",
-	Args:  cobra.ExactArgs(2),
+	Args:  cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		repo := args[0]
-		branch := args[1]
-
 		base, _ := cmd.Flags().GetString("base")
 		noAttach, _ := cmd.Flags().GetBool("no-attach")
 		noFetch, _ := cmd.Flags().GetBool("no-fetch")
 		message, _ := cmd.Flags().GetString("message")
+		fromPR, _ := cmd.Flags().GetInt("from-pr")
+		checkoutExisting, _ := cmd.Flags().GetBool("checkout-existing")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		note, _ := cmd.Flags().GetString("note")
+		on, _ := cmd.Flags().GetString("on")
+		promptFile, _ := cmd.Flags().GetString("prompt-file")
+		template, _ := cmd.Flags().GetString("template")
+		vars, _ := cmd.Flags().GetStringToString("var")
+
+		repo := args[0]
+		var branch string
+		if len(args) == 2 {
+			branch = args[1]
+		}
+		switch {
+		case fromPR > 0 && branch != "":
+			return &workspace.Error{Code: workspace.CodeInvalidArgument, Err: fmt.Errorf("--from-pr takes the branch from the PR; don't pass a branch")}
+		case fromPR == 0 && branch == "":
+			return &workspace.Error{Code: workspace.CodeInvalidArgument, Err: fmt.Errorf("a branch is required unless --from-pr is given")}
+		}
 
 		mgr, err := newManager()
 		if err != nil {
 			return err
 		}
 
-		warnOptionalDeps(cmd)
+		events := startEvents(cmd, mgr)
+		onCopied := func(copied []workspace.CopiedPath) {
+			printCopySummary(cmd, copied)
+		}
+		if events != nil {
+			// The stream has a files_copied event instead, and there's no
+			// terminal to attach.
+			onCopied = nil
+			noAttach = true
+		} else {
+			warnOptionalDeps(cmd, mgr)
+		}
 
 		ws, err := mgr.CreateWorkspace(cmd.Context(), repo, branch, workspace.CreateOptions{
-			BaseBranch: base,
-			NoAttach:   noAttach,
-			NoFetch:    noFetch,
-			Message:    message,
+			BaseBranch:       base,
+			NoAttach:         noAttach,
+			NoFetch:          noFetch,
+			Message:          message,
+			OnFilesCopied:    onCopied,
+			CheckoutExisting: checkoutExisting,
+			FromPR:           fromPR,
+			Tags:             tags,
+			Notes:            note,
+			On:               on,
+			PromptFile:       promptFile,
+			PromptTemplate:   template,
+			PromptVars:       vars,
 		})
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "created workspace %s at %s\n", workspace.WorkspaceID(repo, branch), ws.WorktreePath)
+		id := workspace.WorkspaceID(ws.Repo, ws.Branch)
+		if events != nil {
+			return events.done(id, ws)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "created workspace %s at %s\n", id, ws.WorktreePath)
 		return nil
 	},
 }
@@ -49,13 +91,21 @@ func init() {
 
 	newCmd.Flags().StringP("base", "b", "", "Base branch to create from (default: main)")
 	newCmd.Flags().Bool("no-attach", false, "Create but don't attach to session")
-	newCmd.Flags().StringP("message", "m", "", "Initial prompt to send to Claude Code")
+	newCmd.Flags().StringP("message", "m", "", "Initial prompt for the agent")
+	newCmd.Flags().String("prompt-file", "", "Read the initial prompt from a file (a template, like --template)")
+	newCmd.Flags().StringP("template", "t", "", "Use a prompt template from ~/.ccw/prompts (see ccw prompts)")
+	newCmd.Flags().StringToString("var", nil, "Set a prompt template variable, e.g. --var Issue=42 (repeatable)")
 	newCmd.Flags().Bool("no-fetch", false, "Skip fetch/prune of base (not recommended)")
+	newCmd.Flags().Int("from-pr", 0, "Check out the head branch of this PR/MR number")
+	newCmd.Flags().Bool("checkout-existing", false, "Use an existing local or remote branch instead of creating one")
+	newCmd.Flags().StringSlice("tag", nil, "Tag the workspace (repeatable, or comma-separated)")
+	newCmd.Flags().String("note", "", "Describe the workspace (default: the prompt, from --message or a template)")
+	newCmd.Flags().String("on", "", "Stack on another workspace: branch from its branch and sync onto it")
 }
 
-func warnOptionalDeps(cmd *cobra.Command) {
+func warnOptionalDeps(cmd *cobra.Command, mgr *workspace.Manager) {
 	var hints []string
-	for _, dep := range deps.DefaultDependencies() {
+	for _, dep := range mgr.AgentDependencies() {
 		if !dep.Optional {
 			continue
 		}
@@ -73,3 +123,21 @@ func warnOptionalDeps(cmd *cobra.Command) {
 		fmt.Fprintf(cmd.OutOrStdout(), "warning: optional dependencies missing: %s\n", strings.Join(hints, "; "))
 	}
 }
+
+func printCopySummary(cmd *cobra.Command, copied []workspace.CopiedPath) {
+	out := cmd.OutOrStdout()
+	for _, c := range copied {
+		name := c.Path
+		if c.IsDir {
+			name += "/"
+		}
+		switch {
+		case c.Mode == config.CopyModeSymlink:
+			fmt.Fprintf(out, "linked %s\n", name)
+		case c.IsDir:
+			fmt.Fprintf(out, "copied %s (%d files)\n", name, c.Files)
+		default:
+			fmt.Fprintf(out, "copied %s\n", name)
+		}
+	}
+}
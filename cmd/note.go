@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note <workspace> [text...]",
+	Short: "Show or set a workspace's notes",
+	Long: `Set a workspace's free-text notes. With no text, print them; with --clear,
+remove them.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clearNotes, _ := cmd.Flags().GetBool("clear")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 && !clearNotes {
+			_, ws, err := mgr.GetWorkspace(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if ws.Notes != "" {
+				fmt.Fprintln(cmd.OutOrStdout(), ws.Notes)
+			}
+			return nil
+		}
+		if clearNotes && len(args) > 1 {
+			return fmt.Errorf("--clear takes no text")
+		}
+
+		if _, err := mgr.SetNotes(cmd.Context(), args[0], strings.Join(args[1:], " ")); err != nil {
+			return err
+		}
+		if clearNotes {
+			fmt.Fprintf(cmd.OutOrStdout(), "cleared notes for %s\n", args[0])
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "updated notes for %s\n", args[0])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.Flags().Bool("clear", false, "Remove the notes")
+}
@@ -22,6 +22,7 @@ var openCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		events := startEvents(cmd, mgr)
 
 		if err := mgr.OpenWorkspace(cmd.Context(), id, workspace.OpenOptions{
 			ResumeClaude:  !noResume,
@@ -29,11 +30,17 @@ var openCmd = &cobra.Command{
 			ForceAttach:   forceAttach,
 		}); err != nil {
 			if errors.Is(err, workspace.ErrWorkspaceAlreadyOpen) {
-				return fmt.Errorf("workspace %s is already open (use --focus to focus the existing window)", id)
+				return &workspace.Error{
+					Code: workspace.CodeWorkspaceAlreadyOpen,
+					Err:  fmt.Errorf("workspace %s is already open (use --focus to focus the existing window)", id),
+				}
 			}
 			return err
 		}
 
+		if events != nil {
+			return events.done(id, nil)
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "opened workspace %s\n", id)
 		return nil
 	},
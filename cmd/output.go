@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Values for the global --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// eventStreamCmds are the commands that support --output=json.
+var eventStreamCmds = map[string]bool{
+	"new":   true,
+	"open":  true,
+	"close": true,
+	"rm":    true,
+	"stale": true,
+}
+
+func outputMode(cmd *cobra.Command) string {
+	mode, _ := cmd.Flags().GetString("output")
+	return mode
+}
+
+func checkOutputFlag(cmd *cobra.Command) error {
+	switch mode := outputMode(cmd); mode {
+	case "", outputText:
+		return nil
+	case outputJSON:
+		if !eventStreamCmds[cmd.Name()] {
+			return &workspace.Error{Code: workspace.CodeInvalidArgument, Err: fmt.Errorf("--output=json is not supported by %s", cmd.CommandPath())}
+		}
+		return nil
+	default:
+		return &workspace.Error{Code: workspace.CodeInvalidArgument, Err: fmt.Errorf("unknown --output %q (want %s or %s)", mode, outputText, outputJSON)}
+	}
+}
+
+// eventStream writes a command's progress as newline-delimited JSON: the
+// manager's events as they happen, then one "done" object, or an "error"
+// object (see writeErrorEvent) if the command fails.
+type eventStream struct {
+	enc *json.Encoder
+}
+
+// startEvents returns the event stream for --output=json, or nil in text
+// mode.
+func startEvents(cmd *cobra.Command, mgr *workspace.Manager) *eventStream {
+	if outputMode(cmd) != outputJSON {
+		return nil
+	}
+	s := &eventStream{enc: json.NewEncoder(cmd.OutOrStdout())}
+	mgr.SetEventHandler(func(e workspace.Event) {
+		_ = s.enc.Encode(e)
+	})
+	return s
+}
+
+type doneEvent struct {
+	Type      string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Workspace string    `json:"workspace,omitempty"`
+	Result    any       `json:"result,omitempty"`
+}
+
+// done ends the stream successfully.
+func (s *eventStream) done(id string, result any) error {
+	return s.enc.Encode(doneEvent{Type: "done", Time: time.Now().UTC(), Workspace: id, Result: result})
+}
+
+// failed reports an error that doesn't end the stream, such as one workspace
+// of several failing to be removed.
+func (s *eventStream) failed(id string, err error) {
+	_ = s.enc.Encode(newErrorEvent(id, err))
+}
+
+type errorEvent struct {
+	Type      string      `json:"event"`
+	Time      time.Time   `json:"time"`
+	Workspace string      `json:"workspace,omitempty"`
+	Error     errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newErrorEvent(id string, err error) errorEvent {
+	return errorEvent{
+		Type:      "error",
+		Time:      time.Now().UTC(),
+		Workspace: id,
+		Error:     errorDetail{Code: workspace.ErrorCode(err), Message: err.Error()},
+	}
+}
+
+// writeErrorEvent ends a --output=json stream with the command's error.
+func writeErrorEvent(w io.Writer, err error) {
+	_ = json.NewEncoder(w).Encode(newErrorEvent("", err))
+}
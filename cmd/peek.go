@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var peekCmd = &cobra.Command{
+	Use:   "peek [workspace]",
+	Short: "Print a workspace pane's recent output without attaching",
+	Long: `Print what a pane of a running workspace is showing, with recent
+scrollback, without attaching to its session. Defaults to the first agent
+pane; --pane takes a pane number, "agent", "shell", an agent name or a
+layout pane name.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+
+		var opts workspace.PeekOptions
+		opts.Pane, _ = cmd.Flags().GetString("pane")
+		opts.Lines, _ = cmd.Flags().GetInt("lines")
+
+		content, err := mgr.PeekWorkspace(cmd.Context(), id, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), content)
+		return nil
+	},
+}
+
+func init() {
+	peekCmd.Flags().String("pane", "", "Pane to show (default: the first agent pane)")
+	peekCmd.Flags().Int("lines", 200, "Lines of scrollback to include")
+	rootCmd.AddCommand(peekCmd)
+}
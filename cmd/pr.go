@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Create, view and open a workspace's pull request",
+}
+
+var prCreateCmd = &cobra.Command{
+	Use:   "create [workspace]",
+	Short: "Push the branch and open a pull request into the base branch",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title, _ := cmd.Flags().GetString("title")
+		body, _ := cmd.Flags().GetString("body")
+		draft, _ := cmd.Flags().GetBool("draft")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+
+		pr, err := mgr.CreatePR(cmd.Context(), id, workspace.PROptions{
+			Title: title,
+			Body:  body,
+			Draft: draft,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "created PR #%d for %s: %s\n", pr.Number, id, pr.URL)
+		return nil
+	},
+}
+
+var prViewCmd = &cobra.Command{
+	Use:   "view [workspace]",
+	Short: "Show pull request state, checks and review decision",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+
+		pr, err := mgr.ViewPR(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		showJSON, _ := cmd.Flags().GetBool("json")
+		if showJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(pr)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "PR:\t#%d %s\n", pr.Number, pr.Title)
+		fmt.Fprintf(w, "State:\t%s\n", pr.State)
+		fmt.Fprintf(w, "Checks:\t%s\n", valueOrDash(pr.Checks))
+		fmt.Fprintf(w, "Review:\t%s\n", valueOrDash(pr.ReviewDecision))
+		fmt.Fprintf(w, "URL:\t%s\n", pr.URL)
+		w.Flush()
+		return nil
+	},
+}
+
+var prOpenCmd = &cobra.Command{
+	Use:   "open [workspace]",
+	Short: "Open the pull request in the browser",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+		id, err := workspaceArg(cmd, mgr, args)
+		if err != nil {
+			return err
+		}
+		return mgr.OpenPR(cmd.Context(), id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prCreateCmd, prViewCmd, prOpenCmd)
+
+	prCreateCmd.Flags().StringP("title", "t", "", "PR title (default: from the initial message or commits)")
+	prCreateCmd.Flags().StringP("body", "b", "", "PR body (default: from the initial message or commits)")
+	prCreateCmd.Flags().Bool("draft", false, "Open the PR as a draft")
+
+	prViewCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// prLabel is the short PR column shown by ls, from the state recorded the
+// last time ccw looked, with how long ago that was: it may have changed.
+func prLabel(ws workspace.Workspace) string {
+	if ws.PR == nil {
+		return "-"
+	}
+	if ws.PR.CheckedAt.IsZero() {
+		return fmt.Sprintf("#%d %s", ws.PR.Number, ws.PR.State)
+	}
+	return fmt.Sprintf("#%d %s (%s)", ws.PR.Number, ws.PR.State, timeAgo(ws.PR.CheckedAt))
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List prompt templates for ccw new --template",
+	Long: `List the prompt templates in ~/.ccw/prompts.
+
+A template is a text or markdown file whose name (without .md or .txt) is
+passed to ccw new --template. It is a Go template that can use {{.Repo}},
+{{.Branch}}, {{.BaseBranch}}, {{.Workspace}} and any --var KEY=VALUE, e.g.
+
+  Fix issue #{{.Issue}}. Start by reading it with gh issue view {{.Issue}}.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		names, err := mgr.PromptTemplates()
+		if err != nil {
+			return err
+		}
+
+		showJSON, _ := cmd.Flags().GetBool("json")
+		if showJSON {
+			if names == nil {
+				names = []string{}
+			}
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(names)
+		}
+		if len(names) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "no prompt templates in %s\n", mgr.PromptsDir())
+			return nil
+		}
+		for _, name := range names {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.Flags().Bool("json", false, "Output as JSON")
+}
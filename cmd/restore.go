@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <workspace>",
+	Short: "Recreate an archived workspace and resume its session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		noAttach, _ := cmd.Flags().GetBool("no-attach")
+		noResume, _ := cmd.Flags().GetBool("no-resume")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		ws, err := mgr.RestoreWorkspace(cmd.Context(), args[0], workspace.RestoreOptions{
+			NoAttach: noAttach,
+			NoResume: noResume,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "restored workspace %s at %s\n", args[0], ws.WorktreePath)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().Bool("no-attach", false, "Do not attach to the session")
+	restoreCmd.Flags().Bool("no-resume", false, "Start a fresh Claude Code session")
+}
@@ -27,6 +27,8 @@ var rmCmd = &cobra.Command{
 			return err
 		}
 
+		events := startEvents(cmd, mgr)
+
 		var id string
 		if len(args) == 1 {
 			id = args[0]
@@ -34,17 +36,20 @@ var rmCmd = &cobra.Command{
 			curID, _, err := mgr.FindCurrent(cmd.Context())
 			if err != nil {
 				if errors.Is(err, workspace.ErrNoCurrentWorkspace) {
-					return fmt.Errorf("not inside a ccw workspace; pass a workspace id (ccw rm <workspace>) or cd into one")
+					return fmt.Errorf("%w; pass a workspace id (ccw rm <workspace>) or cd into one", err)
 				}
 				return err
 			}
 			id = curID
-			fmt.Fprintf(cmd.OutOrStdout(), "removing current workspace: %s\n", id)
+			if events == nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "removing current workspace: %s\n", id)
+			}
 		}
 
-		// Build confirmation function for interactive prompts
+		// Build confirmation function for interactive prompts. There's no
+		// one to ask in JSON mode, so unmerged branches fail with a code.
 		var confirmFunc func(message string, files []string) bool
-		if !force && !yes {
+		if !force && !yes && events == nil {
 			confirmFunc = func(message string, files []string) bool {
 				yellow := color.New(color.FgYellow)
 				yellow.Fprintf(cmd.OutOrStdout(), "Warning: %s\n", message)
@@ -74,6 +79,9 @@ var rmCmd = &cobra.Command{
 			return err
 		}
 
+		if events != nil {
+			return events.done(id, nil)
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "removed workspace %s\n", id)
 		return nil
 	},
@@ -18,12 +18,22 @@ var (
 		"version":    true,
 		"help":       true,
 		"completion": true,
+		// Diagnoses setups, including ones whose config doesn't load.
+		"check": true,
+		// Runs inside Claude Code hooks, which can't answer prompts.
+		"claude-hook": true,
+		// Runs behind tmux pipe-pane, with no terminal.
+		"log-pipe": true,
 	}
 )
 
 func Execute() error {
 	rootCmd.SilenceUsage = true
-	return rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil && outputMode(cmd) == outputJSON {
+		writeErrorEvent(cmd.OutOrStdout(), err)
+	}
+	return err
 }
 
 var rootCmd = &cobra.Command{
@@ -31,6 +41,14 @@ var rootCmd = &cobra.Command{
 	Short: "Claude Code Workspace manager",
 	Long:  "ccw is a CLI tool for managing Claude Code workspaces with git worktrees and tmux sessions.",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFlag(cmd); err != nil {
+			return err
+		}
+		if outputMode(cmd) == outputJSON {
+			// Errors are reported in the event stream instead.
+			cmd.Root().SilenceErrors = true
+		}
+
 		// Skip onboarding for exempt commands
 		if onboardingExemptCmds[cmd.Name()] {
 			return nil
@@ -60,6 +78,7 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().String("output", outputText, "output format for new, open, close, rm and stale: text or json (newline-delimited events)")
 	rootCmd.AddCommand(versionCmd)
 }
 
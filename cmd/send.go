@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send [workspace...] <text>",
+	Short: "Type text into the agent of one or more running workspaces",
+	Long: `Type text into a pane of one or more running workspaces, as if you had
+attached and typed it yourself. Without a workspace, sends to the current one.
+
+By default the text goes to the first agent pane in the layout; --pane picks
+another: "shell" for the first plain shell pane, an agent name such as
+"codex", or a pane name from the layout.
+
+With --wait, ccw waits for each agent to finish responding and prints its
+reply.`,
+	Example: `  ccw send myrepo/feature "run the tests and summarize" --wait
+  ccw send --tag backend "rebase onto main and fix conflicts"
+  ccw send --all --pane shell "git status"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		showJSON, _ := cmd.Flags().GetBool("json")
+		var opts workspace.SendOptions
+		opts.Pane, _ = cmd.Flags().GetString("pane")
+		opts.Wait, _ = cmd.Flags().GetBool("wait")
+		opts.Timeout, _ = cmd.Flags().GetDuration("timeout")
+
+		text, ids := args[len(args)-1], args[:len(args)-1]
+		switch {
+		case (all || len(tags) > 0) && len(ids) > 0:
+			return fmt.Errorf("pass workspaces or --all/--tag, not both")
+		case all || len(tags) > 0:
+			if ids, err = runningWorkspaces(cmd, mgr, tags); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return fmt.Errorf("no running workspaces match")
+			}
+		case len(ids) == 0:
+			id, err := workspaceArg(cmd, mgr, nil)
+			if err != nil {
+				return err
+			}
+			ids = []string{id}
+		}
+
+		results, err := mgr.SendToWorkspaces(cmd.Context(), ids, text, opts)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Error != "" {
+				failed++
+			}
+		}
+
+		if showJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			out := cmd.OutOrStdout()
+			for i, r := range results {
+				switch {
+				case r.Error != "":
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", r.ID, r.Error)
+				case opts.Wait:
+					if i > 0 {
+						fmt.Fprintln(out)
+					}
+					fmt.Fprintf(out, "==> %s (%s)\n%s\n", r.ID, r.Pane, r.Reply)
+				default:
+					fmt.Fprintf(out, "sent to %s (%s)\n", r.ID, r.Pane)
+				}
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to send to %d of %d workspaces", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// runningWorkspaces returns the ids of workspaces with a live session that
+// have all of tags.
+func runningWorkspaces(cmd *cobra.Command, mgr *workspace.Manager, tags []string) ([]string, error) {
+	statuses, err := mgr.ListWorkspaces(cmd.Context(), workspace.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, st := range statuses {
+		if st.SessionAlive && !st.Workspace.Archived && st.Workspace.HasTags(tags...) {
+			ids = append(ids, st.ID)
+		}
+	}
+	return ids, nil
+}
+
+func init() {
+	sendCmd.Flags().Bool("all", false, "Send to every running workspace")
+	sendCmd.Flags().StringSlice("tag", nil, "Send to running workspaces with this tag (repeatable; all must match)")
+	sendCmd.Flags().String("pane", workspace.PaneAgent, `Pane to type into: "agent", "shell", an agent name, a layout pane name or a pane number`)
+	sendCmd.Flags().Bool("wait", false, "Wait for each agent to finish and print its reply")
+	sendCmd.Flags().Duration("timeout", 0, "How long --wait waits for a reply (default 10m)")
+	sendCmd.Flags().Bool("json", false, "Output results as JSON")
+	rootCmd.AddCommand(sendCmd)
+}
@@ -23,11 +23,17 @@ var staleCmd = &cobra.Command{
 			return err
 		}
 
+		events := startEvents(cmd, mgr)
+
 		stale, err := mgr.StaleWorkspaces(cmd.Context(), force)
 		if err != nil {
 			return err
 		}
 
+		if events != nil && !remove {
+			return events.done("", stale)
+		}
+
 		if showJSON {
 			enc := json.NewEncoder(cmd.OutOrStdout())
 			enc.SetIndent("", "  ")
@@ -46,10 +52,17 @@ var staleCmd = &cobra.Command{
 		}
 
 		var errs []error
+		removed := []string{}
 		for _, st := range stale {
 			if err := mgr.RemoveWorkspace(cmd.Context(), st.ID, workspace.RemoveOptions{Force: force}); err != nil {
 				errs = append(errs, fmt.Errorf("%s: %w", st.ID, err))
-			} else {
+				if events != nil {
+					events.failed(st.ID, err)
+				}
+				continue
+			}
+			removed = append(removed, st.ID)
+			if events == nil {
 				fmt.Fprintf(cmd.OutOrStdout(), "removed %s\n", st.ID)
 			}
 		}
@@ -58,6 +71,9 @@ var staleCmd = &cobra.Command{
 			return errors.Join(errs...)
 		}
 
+		if events != nil {
+			return events.done("", removed)
+		}
 		return nil
 	},
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ccw/ccw/internal/workspace"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [workspace...]",
+	Short: "Rebase or merge workspace branches onto their updated base (defaults to the current one)",
+	Long: `Sync fetches origin and brings each workspace branch up to date with
+origin/<base>, by rebasing (the default) or merging. Set the default with
+` + "`ccw config sync_strategy merge`" + ` or override it with --strategy.
+
+Workspaces with uncommitted changes are skipped, and a rebase or merge that
+conflicts is aborted so the worktree is left as it was. Use --push to push
+updated branches with --force-with-lease; a branch missing commits that are
+already on origin is left alone until you pull them.
+
+Workspaces stacked with ` + "`ccw new --on`" + ` are synced after their parent, onto
+its branch, replaying only their own commits.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		strategy, _ := cmd.Flags().GetString("strategy")
+		push, _ := cmd.Flags().GetBool("push")
+		noFetch, _ := cmd.Flags().GetBool("no-fetch")
+		showJSON, _ := cmd.Flags().GetBool("json")
+
+		if all && len(args) > 0 {
+			return fmt.Errorf("pass workspaces or --all, not both")
+		}
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		ids := args
+		if !all && len(ids) == 0 {
+			id, err := workspaceArg(cmd, mgr, nil)
+			if err != nil {
+				return err
+			}
+			ids = []string{id}
+		}
+
+		results, err := mgr.SyncWorkspaces(cmd.Context(), ids, workspace.SyncOptions{
+			Strategy: strings.ToLower(strategy),
+			Push:     push,
+			NoFetch:  noFetch,
+		})
+		if err != nil {
+			return err
+		}
+
+		if showJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			printSyncResults(cmd, results)
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Failed() {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d workspace(s) could not be synced", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func printSyncResults(cmd *cobra.Command, results []workspace.SyncResult) {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tBASE\tRESULT\tAHEAD/BEHIND\tDETAILS")
+	for _, r := range results {
+		status := r.Status
+		switch {
+		case r.Failed():
+			status = color.New(color.FgRed).Sprint(status)
+		case r.Status == workspace.SyncUpdated:
+			status = color.New(color.FgGreen).Sprint(status)
+		}
+		counts := "-"
+		if r.Status == workspace.SyncUpdated || r.Status == workspace.SyncUpToDate {
+			counts = fmt.Sprintf("+%d/-%d", r.Ahead, r.Behind)
+		}
+		details := r.Message
+		if len(r.Conflicts) > 0 {
+			details = "conflicts: " + strings.Join(r.Conflicts, ", ")
+		}
+		if r.Pushed {
+			details = "pushed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.ID, valueOrDash(r.Base), status, counts, valueOrDash(details))
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().Bool("all", false, "Sync every workspace")
+	syncCmd.Flags().String("strategy", "", "rebase or merge (default: the sync_strategy config, else rebase)")
+	syncCmd.Flags().Bool("push", false, "Push updated branches with --force-with-lease")
+	syncCmd.Flags().Bool("no-fetch", false, "Sync onto the already-fetched origin/<base>")
+	syncCmd.Flags().Bool("json", false, "Output results as JSON")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <workspace> [tag...]",
+	Short: "Add or remove workspace tags",
+	Long: `Add tags to a workspace, or remove them with --rm. With no tags, print
+the workspace's current tags.`,
+	Example: `  ccw tag api-auth review urgent
+  ccw tag api-auth --rm urgent`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remove, _ := cmd.Flags().GetBool("rm")
+
+		mgr, err := newManager()
+		if err != nil {
+			return err
+		}
+
+		var add, drop []string
+		if remove {
+			drop = args[1:]
+		} else {
+			add = args[1:]
+		}
+		ws, err := mgr.UpdateTags(cmd.Context(), args[0], add, drop)
+		if err != nil {
+			return err
+		}
+
+		if len(ws.Tags) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no tags")
+			return nil
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), strings.Join(ws.Tags, " "))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.Flags().Bool("rm", false, "Remove the given tags instead of adding them")
+}
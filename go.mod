@@ -3,9 +3,12 @@ module github.com/ccw/ccw
 go 1.24.0
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.10.2
 	github.com/fatih/color v1.18.0
 	github.com/gofrs/flock v0.13.0
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/term v0.37.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -14,5 +17,4 @@ require (
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/term v0.37.0 // indirect
 )
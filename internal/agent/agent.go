@@ -0,0 +1,202 @@
+// Package agent abstracts the coding-agent CLIs that ccw launches inside
+// workspace panes. Claude and Codex are built in; additional agents can be
+// declared in config without touching this package.
+package agent
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/deps"
+)
+
+// Agent is a coding-agent CLI that can be started in a tmux pane.
+type Agent interface {
+	// Name is the identifier used in config and layouts (e.g. "claude").
+	Name() string
+	// Dependency describes the binary the agent needs on PATH.
+	Dependency() deps.Dependency
+	// Detect probes the installed CLI for optional features. It is
+	// best-effort: failures leave the agent on conservative defaults.
+	Detect(ctx context.Context)
+	// LaunchCommand returns the shell command that starts a fresh session.
+	LaunchCommand(opts LaunchOptions) string
+	// ResumeCommand returns the shell command that continues the previous
+	// session for the workspace, or starts a fresh one if the agent cannot
+	// resume.
+	ResumeCommand(opts LaunchOptions) string
+}
+
+// LaunchOptions carries per-workspace settings into launch commands.
+type LaunchOptions struct {
+	// SessionName is the workspace's tmux-safe name.
+	SessionName string
+	// SessionID is the agent conversation to start or resume, for agents
+	// that track conversations by ID. Others ignore it.
+	SessionID string
+	// SkipPermissions asks the agent to auto-approve tool use when it
+	// supports doing so.
+	SkipPermissions bool
+	// Prompt is the first message of a fresh session. LaunchCommand passes
+	// it as an argument if the agent AcceptsPrompt; otherwise it is ignored
+	// and ccw types it into the pane instead.
+	Prompt string
+}
+
+// PromptAcceptor is implemented by agents whose launch command can take the
+// initial prompt as a positional argument.
+type PromptAcceptor interface {
+	AcceptsPrompt() bool
+}
+
+// AcceptsPrompt reports whether a's LaunchCommand includes
+// LaunchOptions.Prompt.
+func AcceptsPrompt(a Agent) bool {
+	p, ok := a.(PromptAcceptor)
+	return ok && p.AcceptsPrompt()
+}
+
+// ReadyReporter is implemented by agents that show recognizable text once
+// they accept input, such as the hints under an input box.
+type ReadyReporter interface {
+	ReadyMarkers() []string
+}
+
+// ReadyMarkers returns the text that shows a is ready for input, or nil if
+// it has none; its pane output settling is then the best sign available.
+func ReadyMarkers(a Agent) []string {
+	if r, ok := a.(ReadyReporter); ok {
+		return r.ReadyMarkers()
+	}
+	return nil
+}
+
+// BusyReporter is implemented by agents that show recognizable text while
+// they are working on a request.
+type BusyReporter interface {
+	BusyMarkers() []string
+}
+
+// BusyMarkers returns the text that shows a is working, or nil if it has
+// none.
+func BusyMarkers(a Agent) []string {
+	if r, ok := a.(BusyReporter); ok {
+		return r.BusyMarkers()
+	}
+	return nil
+}
+
+// PermissionReporter is implemented by agents that show recognizable text
+// while they wait for the user to approve an action.
+type PermissionReporter interface {
+	PermissionMarkers() []string
+}
+
+// PermissionMarkers returns the text that shows a is waiting for approval,
+// or nil if it has none.
+func PermissionMarkers(a Agent) []string {
+	if r, ok := a.(PermissionReporter); ok {
+		return r.PermissionMarkers()
+	}
+	return nil
+}
+
+// ErrorReporter is implemented by agents that show recognizable text when a
+// request fails.
+type ErrorReporter interface {
+	ErrorMarkers() []string
+}
+
+// ErrorMarkers returns the text that shows a request of a failed, or nil if
+// it has none.
+func ErrorMarkers(a Agent) []string {
+	if r, ok := a.(ErrorReporter); ok {
+		return r.ErrorMarkers()
+	}
+	return nil
+}
+
+// withPrompt appends prompt to command as a single-quoted shell argument.
+func withPrompt(command, prompt string) string {
+	if prompt == "" {
+		return command
+	}
+	return command + " '" + strings.ReplaceAll(prompt, `'`, `'\''`) + "'"
+}
+
+// Registry holds the agents available to a Manager, keyed by name.
+type Registry struct {
+	agents map[string]Agent
+}
+
+// NewRegistry returns a registry containing the given agents. Later agents
+// replace earlier ones with the same name.
+func NewRegistry(agents ...Agent) *Registry {
+	r := &Registry{agents: make(map[string]Agent, len(agents))}
+	for _, a := range agents {
+		r.Register(a)
+	}
+	return r
+}
+
+// Default returns a registry with the built-in agents.
+func Default() *Registry {
+	return NewRegistry(NewClaude(), NewCodex())
+}
+
+// FromConfig returns the built-in agents plus any declared in config. A
+// configured agent with a built-in name replaces the built-in.
+func FromConfig(cfgs map[string]config.AgentConfig) *Registry {
+	return Default().With(cfgs)
+}
+
+// With returns a copy of the registry with the configured agents added or
+// replaced. Agents that are not overridden are shared with r, so any
+// capabilities they have already detected carry over.
+func (r *Registry) With(cfgs map[string]config.AgentConfig) *Registry {
+	out := &Registry{agents: make(map[string]Agent, len(r.agents)+len(cfgs))}
+	for name, a := range r.agents {
+		out.agents[name] = a
+	}
+	for name, cfg := range cfgs {
+		out.Register(NewCommand(name, cfg))
+	}
+	return out
+}
+
+// Register adds or replaces an agent.
+func (r *Registry) Register(a Agent) {
+	r.agents[a.Name()] = a
+}
+
+// SetCacheDir tells agents that cache what Detect learns where to keep it.
+func (r *Registry) SetCacheDir(dir string) {
+	for _, a := range r.agents {
+		if c, ok := a.(interface{ SetCacheDir(string) }); ok {
+			c.SetCacheDir(dir)
+		}
+	}
+}
+
+// Get looks up an agent by name.
+func (r *Registry) Get(name string) (Agent, bool) {
+	a, ok := r.agents[name]
+	return a, ok
+}
+
+// All returns every registered agent sorted by name.
+func (r *Registry) All() []Agent {
+	names := make([]string, 0, len(r.agents))
+	for name := range r.agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := make([]Agent, 0, len(names))
+	for _, name := range names {
+		all = append(all, r.agents[name])
+	}
+	return all
+}
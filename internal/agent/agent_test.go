@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/config"
+)
+
+func TestDefaultRegistryHasBuiltins(t *testing.T) {
+	r := Default()
+	for _, name := range []string{"claude", "codex"} {
+		if _, ok := r.Get(name); !ok {
+			t.Fatalf("expected built-in agent %q", name)
+		}
+	}
+
+	codex, _ := r.Get("codex")
+	if !codex.Dependency().Optional {
+		t.Fatalf("expected codex to be optional")
+	}
+	claudeAgent, _ := r.Get("claude")
+	if claudeAgent.Dependency().Optional {
+		t.Fatalf("expected claude to be required")
+	}
+}
+
+func TestClaudeLaunchAndResume(t *testing.T) {
+	c := NewClaude()
+	const id = "0b6c3a1e-2f4d-4c5e-9a7b-1d2e3f4a5b6c"
+	opts := LaunchOptions{SessionName: "demo", SessionID: id, SkipPermissions: true}
+
+	if got := c.LaunchCommand(opts); got != "claude --dangerously-skip-permissions --session-id "+id {
+		t.Fatalf("unexpected launch command: %s", got)
+	}
+	if got := c.ResumeCommand(opts); got != "claude --dangerously-skip-permissions --resume "+id {
+		t.Fatalf("unexpected resume command: %s", got)
+	}
+	opts.SessionID = ""
+	if got := c.ResumeCommand(opts); got != "claude --dangerously-skip-permissions --continue" {
+		t.Fatalf("expected resume without an id to continue, got %s", got)
+	}
+}
+
+func TestFromConfigAddsAndOverridesAgents(t *testing.T) {
+	r := FromConfig(map[string]config.AgentConfig{
+		"aider": {Command: "aider --no-auto-commits", ResumeCommand: "aider --restore-chat-history", Optional: true},
+		"codex": {Command: "codex --full-auto", Binary: "codex"},
+	})
+
+	aider, ok := r.Get("aider")
+	if !ok {
+		t.Fatalf("expected configured agent to be registered")
+	}
+	if dep := aider.Dependency(); dep.Name != "aider" || !dep.Optional {
+		t.Fatalf("unexpected aider dependency: %+v", dep)
+	}
+	if got := aider.ResumeCommand(LaunchOptions{}); got != "aider --restore-chat-history" {
+		t.Fatalf("unexpected resume command: %s", got)
+	}
+
+	codex, _ := r.Get("codex")
+	if got := codex.LaunchCommand(LaunchOptions{}); got != "codex --full-auto" {
+		t.Fatalf("expected config to override built-in codex, got %s", got)
+	}
+	if got := codex.ResumeCommand(LaunchOptions{}); got != "codex --full-auto" {
+		t.Fatalf("expected resume to fall back to launch command, got %s", got)
+	}
+
+	var names []string
+	for _, a := range r.All() {
+		names = append(names, a.Name())
+	}
+	if strings.Join(names, ",") != "aider,claude,codex" {
+		t.Fatalf("unexpected agent order: %v", names)
+	}
+}
+
+func TestLaunchCommandPrompt(t *testing.T) {
+	opts := LaunchOptions{Prompt: "fix it's bug"}
+	const quoted = ` 'fix it'\''s bug'`
+
+	if got := NewClaude().LaunchCommand(opts); got != "claude"+quoted {
+		t.Fatalf("unexpected claude command: %s", got)
+	}
+	if got := NewClaude().ResumeCommand(opts); strings.Contains(got, "bug") {
+		t.Fatalf("expected resume to ignore the prompt: %s", got)
+	}
+	if got := NewCodex().LaunchCommand(opts); got != codexCommand+quoted {
+		t.Fatalf("unexpected codex command: %s", got)
+	}
+
+	plain := NewCommand("aider", config.AgentConfig{Command: "aider"})
+	if AcceptsPrompt(plain) || plain.LaunchCommand(opts) != "aider" {
+		t.Fatalf("expected plain command agent to leave the prompt out")
+	}
+	withArg := NewCommand("aider", config.AgentConfig{Command: "aider --message", PromptArg: true})
+	if !AcceptsPrompt(withArg) || withArg.LaunchCommand(opts) != "aider --message"+quoted {
+		t.Fatalf("unexpected prompt_arg command: %s", withArg.LaunchCommand(opts))
+	}
+}
+
+func TestActivityMarkers(t *testing.T) {
+	if len(PermissionMarkers(NewClaude())) == 0 || len(ErrorMarkers(NewCodex())) == 0 {
+		t.Fatalf("expected built-in agents to have activity markers")
+	}
+	aider := NewCommand("aider", config.AgentConfig{Command: "aider", PermissionMarkers: []string{"(Y)es/(N)o"}})
+	if got := PermissionMarkers(aider); len(got) != 1 || got[0] != "(Y)es/(N)o" {
+		t.Fatalf("unexpected configured permission markers: %v", got)
+	}
+	if ErrorMarkers(aider) != nil {
+		t.Fatalf("expected no error markers by default")
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/ccw/ccw/internal/claude"
+	"github.com/ccw/ccw/internal/deps"
+)
+
+// Claude launches the Claude Code CLI.
+type Claude struct {
+	caps     claude.Capabilities
+	detected bool
+	cacheDir string
+}
+
+func NewClaude() *Claude {
+	return &Claude{caps: claude.DefaultCapabilities()}
+}
+
+func (c *Claude) Name() string {
+	return "claude"
+}
+
+func (c *Claude) Dependency() deps.Dependency {
+	return deps.Dependency{
+		Name:        "claude",
+		DisplayName: "Claude Code CLI",
+		InstallHint: "Install Claude Code CLI: https://claude.com/claude-code",
+	}
+}
+
+// SetCacheDir keeps detected capabilities in dir across runs.
+func (c *Claude) SetCacheDir(dir string) {
+	c.cacheDir = dir
+}
+
+// Detect looks up the installed claude's capabilities once per process.
+func (c *Claude) Detect(ctx context.Context) {
+	if c.detected {
+		return
+	}
+	c.detected = true
+
+	d, err := claude.Detect(ctx, c.cacheDir)
+	if err != nil {
+		return
+	}
+	c.caps = d.Capabilities
+}
+
+func (c *Claude) LaunchCommand(opts LaunchOptions) string {
+	return withPrompt(claude.BuildLaunchCommand(c.launchOptions(opts, false), c.caps), opts.Prompt)
+}
+
+// ReadyMarkers are the hints claude prints under its input box: the
+// shortcuts hint, or the permission mode when one is active.
+func (c *Claude) ReadyMarkers() []string {
+	return []string{"? for shortcuts", "bypass permissions on", "accept edits on", "plan mode on"}
+}
+
+// BusyMarkers is the interrupt hint claude shows while it works.
+func (c *Claude) BusyMarkers() []string {
+	return []string{"esc to interrupt"}
+}
+
+// PermissionMarkers are the questions claude asks before running a tool
+// that needs approval.
+func (c *Claude) PermissionMarkers() []string {
+	return []string{"Do you want to proceed?", "Do you want to make this edit", "Do you want to create"}
+}
+
+// ErrorMarkers are the messages claude shows when the API fails.
+func (c *Claude) ErrorMarkers() []string {
+	return []string{"API Error", "Invalid API key"}
+}
+
+// AcceptsPrompt is true: `claude "prompt"` starts an interactive session
+// with that first message.
+func (c *Claude) AcceptsPrompt() bool {
+	return true
+}
+
+// ResumeCommand resumes opts.SessionID, or the most recent conversation in
+// the worktree when no ID is known.
+func (c *Claude) ResumeCommand(opts LaunchOptions) string {
+	return claude.BuildLaunchCommand(c.launchOptions(opts, true), c.caps)
+}
+
+func (c *Claude) launchOptions(opts LaunchOptions, resume bool) claude.LaunchOptions {
+	return claude.LaunchOptions{
+		Name:            opts.SessionName,
+		SessionID:       opts.SessionID,
+		Resume:          resume,
+		SkipPermissions: opts.SkipPermissions,
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/ccw/ccw/internal/deps"
+)
+
+const codexCommand = "codex --dangerously-bypass-approvals-and-sandbox"
+
+// Codex launches the OpenAI Codex CLI.
+type Codex struct{}
+
+func NewCodex() *Codex {
+	return &Codex{}
+}
+
+func (c *Codex) Name() string {
+	return "codex"
+}
+
+func (c *Codex) Dependency() deps.Dependency {
+	return deps.Dependency{
+		Name:        "codex",
+		DisplayName: "Codex CLI",
+		Optional:    true,
+		InstallHint: "Optional: install with `npm i -g @openai/codex` or `brew install codex`.",
+	}
+}
+
+func (c *Codex) Detect(ctx context.Context) {}
+
+// LaunchCommand always runs codex unsandboxed: the worktree is already an
+// isolated checkout and approval prompts would stall the side pane.
+func (c *Codex) LaunchCommand(opts LaunchOptions) string {
+	return withPrompt(codexCommand, opts.Prompt)
+}
+
+// ReadyMarkers are the key hints codex shows under its composer.
+func (c *Codex) ReadyMarkers() []string {
+	return []string{"⏎ send", "? for shortcuts"}
+}
+
+func (c *Codex) BusyMarkers() []string {
+	return []string{"Esc to interrupt", "esc to interrupt"}
+}
+
+func (c *Codex) PermissionMarkers() []string {
+	return []string{"Would you like to run the following command?", "Would you like to make the following edits?"}
+}
+
+func (c *Codex) ErrorMarkers() []string {
+	return []string{"stream error", "unexpected status"}
+}
+
+func (c *Codex) AcceptsPrompt() bool {
+	return true
+}
+
+// ResumeCommand starts a fresh codex session; codex has no way to target the
+// previous session of a particular worktree.
+func (c *Codex) ResumeCommand(opts LaunchOptions) string {
+	return codexCommand
+}
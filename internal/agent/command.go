@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/deps"
+)
+
+// Command is an agent declared in config by its shell commands, e.g. aider or
+// gemini-cli.
+type Command struct {
+	name string
+	cfg  config.AgentConfig
+}
+
+func NewCommand(name string, cfg config.AgentConfig) *Command {
+	return &Command{name: name, cfg: cfg}
+}
+
+func (c *Command) Name() string {
+	return c.name
+}
+
+// Dependency uses the configured binary, falling back to the first word of
+// the launch command.
+func (c *Command) Dependency() deps.Dependency {
+	binary := c.cfg.Binary
+	if binary == "" {
+		if fields := strings.Fields(c.cfg.Command); len(fields) > 0 {
+			binary = fields[0]
+		}
+	}
+	return deps.Dependency{
+		Name:        binary,
+		DisplayName: c.name,
+		Optional:    c.cfg.Optional,
+		InstallHint: c.cfg.InstallHint,
+	}
+}
+
+func (c *Command) Detect(ctx context.Context) {}
+
+func (c *Command) LaunchCommand(opts LaunchOptions) string {
+	if c.cfg.PromptArg {
+		return withPrompt(c.cfg.Command, opts.Prompt)
+	}
+	return c.cfg.Command
+}
+
+// ReadyMarkers returns the agent's configured ready_markers.
+func (c *Command) ReadyMarkers() []string {
+	return c.cfg.ReadyMarkers
+}
+
+// BusyMarkers returns the agent's configured busy_markers.
+func (c *Command) BusyMarkers() []string {
+	return c.cfg.BusyMarkers
+}
+
+// PermissionMarkers returns the agent's configured permission_markers.
+func (c *Command) PermissionMarkers() []string {
+	return c.cfg.PermissionMarkers
+}
+
+// ErrorMarkers returns the agent's configured error_markers.
+func (c *Command) ErrorMarkers() []string {
+	return c.cfg.ErrorMarkers
+}
+
+// AcceptsPrompt follows the agent's prompt_arg setting.
+func (c *Command) AcceptsPrompt() bool {
+	return c.cfg.PromptArg
+}
+
+func (c *Command) ResumeCommand(opts LaunchOptions) string {
+	if c.cfg.ResumeCommand != "" {
+		return c.cfg.ResumeCommand
+	}
+	return c.cfg.Command
+}
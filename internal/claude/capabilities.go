@@ -1,70 +1,94 @@
 package claude
 
 import (
-	"bytes"
 	"context"
-	"os/exec"
+	"regexp"
 	"strings"
 )
 
 type Capabilities struct {
-	SupportsResume  bool
-	SessionNameFlag string // e.g., "--session-name" or "--name"; empty if not supported
+	SupportsResume bool `json:"supports_resume"`
+	// SupportsSessionID means new sessions can be given their conversation
+	// UUID up front with --session-id.
+	SupportsSessionID bool   `json:"supports_session_id"`
+	SessionNameFlag   string `json:"session_name_flag,omitempty"` // e.g., "--session-name" or "--name"; empty if not supported
 }
 
 func DefaultCapabilities() Capabilities {
 	return Capabilities{
-		SupportsResume: true,
+		SupportsResume:    true,
+		SupportsSessionID: true,
 	}
 }
 
-// DetectCapabilities inspects `claude --help` to discover supported flags. It
-// returns a best-effort guess and does not fail if detection cannot run.
+// DetectCapabilities works out what the installed claude supports without
+// a cache. See Detect.
 func DetectCapabilities(ctx context.Context) (Capabilities, error) {
-	cmd := exec.CommandContext(ctx, "claude", "--help")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-
-	if err := cmd.Run(); err != nil {
-		return DefaultCapabilities(), err
-	}
-
-	return parseHelp(out.String()), nil
+	d, err := Detect(ctx, "")
+	return d.Capabilities, err
 }
 
-func parseHelp(helpText string) Capabilities {
-	text := strings.ToLower(helpText)
-	caps := DefaultCapabilities()
+// optionPattern matches the flags an option line of `claude --help`
+// declares, e.g. "  -r, --resume [sessionId]".
+var optionPattern = regexp.MustCompile(`^\s+(?:-[a-zA-Z],\s*)?(--[a-z][a-z0-9-]*)`)
 
-	if strings.Contains(text, "--resume") {
-		caps.SupportsResume = true
+// parseHelp reads the flags claude declares in its --help output. Only
+// option lines count, so a flag merely mentioned in a description doesn't.
+func parseHelp(helpText string) Capabilities {
+	flags := map[string]bool{}
+	for _, line := range strings.Split(helpText, "\n") {
+		if m := optionPattern.FindStringSubmatch(line); m != nil {
+			flags[strings.ToLower(m[1])] = true
+		}
 	}
 
-	if strings.Contains(text, "--session-name") {
+	caps := Capabilities{
+		SupportsResume:    flags["--resume"],
+		SupportsSessionID: flags["--session-id"],
+	}
+	switch {
+	case flags["--session-name"]:
 		caps.SessionNameFlag = "--session-name"
-	} else if strings.Contains(text, "--name") {
+	case flags["--name"]:
 		caps.SessionNameFlag = "--name"
 	}
-
 	return caps
 }
 
-func BuildLaunchCommand(name string, resume bool, caps Capabilities, skipPerms bool) string {
+// LaunchOptions describes the claude session to start.
+type LaunchOptions struct {
+	// Name is the workspace's tmux-safe name, passed with SessionNameFlag.
+	Name string
+	// SessionID is the conversation UUID to start or resume.
+	SessionID string
+	// Resume continues SessionID, or the most recent conversation in the
+	// working directory when SessionID is empty.
+	Resume          bool
+	SkipPermissions bool
+}
+
+func BuildLaunchCommand(opts LaunchOptions, caps Capabilities) string {
 	var parts []string
 	parts = append(parts, "claude")
 
-	if skipPerms {
+	if opts.SkipPermissions {
 		parts = append(parts, "--dangerously-skip-permissions")
 	}
 
-	if resume && caps.SupportsResume {
-		parts = append(parts, "--resume", name)
+	if opts.Resume && caps.SupportsResume {
+		if opts.SessionID != "" {
+			parts = append(parts, "--resume", opts.SessionID)
+		} else {
+			parts = append(parts, "--continue")
+		}
 		return strings.Join(parts, " ")
 	}
 
-	if caps.SessionNameFlag != "" {
-		parts = append(parts, caps.SessionNameFlag, name)
+	if opts.SessionID != "" && caps.SupportsSessionID {
+		parts = append(parts, "--session-id", opts.SessionID)
+	}
+	if caps.SessionNameFlag != "" && opts.Name != "" {
+		parts = append(parts, caps.SessionNameFlag, opts.Name)
 	}
 
 	return strings.Join(parts, " ")
@@ -20,31 +20,40 @@ Usage of claude:
 	}
 }
 
-func TestBuildLaunchCommand(t *testing.T) {
-	caps := Capabilities{SupportsResume: true, SessionNameFlag: "--session-name"}
-
-	// Test resume without skip perms
-	cmd := BuildLaunchCommand("demo", true, caps, false)
-	if cmd != "claude --resume demo" {
-		t.Fatalf("unexpected command: %s", cmd)
+func TestParseHelpIgnoresMentionsInDescriptions(t *testing.T) {
+	text := `
+Options:
+  -c, --continue             Continue the most recent conversation
+  --model <model>            Model for the session (see --name-suffix docs)
+  --output-format <format>   e.g. --session-id-like output`
+	caps := parseHelp(text)
+	if caps.SessionNameFlag != "" || caps.SupportsSessionID || caps.SupportsResume {
+		t.Fatalf("expected no flags from descriptions, got %+v", caps)
 	}
+}
 
-	// Test non-resume without skip perms
-	cmd = BuildLaunchCommand("demo", false, caps, false)
-	if cmd != "claude --session-name demo" {
-		t.Fatalf("unexpected command: %s", cmd)
-	}
+func TestBuildLaunchCommand(t *testing.T) {
+	caps := Capabilities{SupportsResume: true, SupportsSessionID: true, SessionNameFlag: "--session-name"}
+	const id = "0b6c3a1e-2f4d-4c5e-9a7b-1d2e3f4a5b6c"
 
-	// Test resume with skip perms
-	cmd = BuildLaunchCommand("demo", true, caps, true)
-	if cmd != "claude --dangerously-skip-permissions --resume demo" {
-		t.Fatalf("unexpected command with skip perms: %s", cmd)
+	tests := []struct {
+		name string
+		opts LaunchOptions
+		caps Capabilities
+		want string
+	}{
+		{"resume by id", LaunchOptions{Name: "demo", SessionID: id, Resume: true}, caps, "claude --resume " + id},
+		{"resume without id continues", LaunchOptions{Name: "demo", Resume: true}, caps, "claude --continue"},
+		{"new session", LaunchOptions{Name: "demo", SessionID: id}, caps, "claude --session-id " + id + " --session-name demo"},
+		{"resume with skip perms", LaunchOptions{SessionID: id, Resume: true, SkipPermissions: true}, caps, "claude --dangerously-skip-permissions --resume " + id},
+		{"new session with skip perms", LaunchOptions{Name: "demo", SkipPermissions: true}, caps, "claude --dangerously-skip-permissions --session-name demo"},
+		{"no --session-id support", LaunchOptions{SessionID: id}, Capabilities{SupportsResume: true}, "claude"},
+		{"no resume support", LaunchOptions{SessionID: id, Resume: true}, Capabilities{SupportsSessionID: true}, "claude --session-id " + id},
 	}
-
-	// Test non-resume with skip perms
-	cmd = BuildLaunchCommand("demo", false, caps, true)
-	if cmd != "claude --dangerously-skip-permissions --session-name demo" {
-		t.Fatalf("unexpected command with skip perms: %s", cmd)
+	for _, tt := range tests {
+		if got := BuildLaunchCommand(tt.opts, tt.caps); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
 	}
 }
 
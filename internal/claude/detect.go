@@ -0,0 +1,143 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/ccw/ccw/internal/storage"
+)
+
+const cacheFileName = "claude-capabilities.json"
+
+// Where a Detection's capabilities came from.
+const (
+	// SourceMatrix: the version is in the compatibility matrix.
+	SourceMatrix = "matrix"
+	// SourceHelp: the version is unknown, so `claude --help` was parsed.
+	SourceHelp = "help"
+	// SourceDefault: claude couldn't be run; conservative defaults apply.
+	SourceDefault = "default"
+)
+
+// Detection is what ccw learned about the installed claude binary.
+type Detection struct {
+	// Path is the binary with symlinks resolved, so upgrades that swap the
+	// symlink target invalidate the cache.
+	Path         string       `json:"path"`
+	ModTime      time.Time    `json:"mod_time"`
+	Version      string       `json:"version,omitempty"`
+	Source       string       `json:"source"`
+	Capabilities Capabilities `json:"capabilities"`
+	// Cached is set when the result came from the cache.
+	Cached bool `json:"cached"`
+}
+
+type cacheEntry struct {
+	Matrix    int       `json:"matrix"`
+	Detection Detection `json:"detection"`
+}
+
+// Detect reports the installed claude's version and capabilities. Known
+// versions are looked up in the compatibility matrix; others fall back to
+// parsing `claude --help`. With cacheDir set, the result is cached there
+// keyed by the binary's path and modification time, so repeat calls don't
+// spawn claude at all.
+//
+// On error the returned Detection still carries usable default capabilities.
+func Detect(ctx context.Context, cacheDir string) (Detection, error) {
+	fallback := Detection{Source: SourceDefault, Capabilities: DefaultCapabilities()}
+
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		return fallback, err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fallback, err
+	}
+	fallback.Path = path
+	fallback.ModTime = info.ModTime()
+
+	if cached, ok := readCache(cacheDir, path, info.ModTime()); ok {
+		return cached, nil
+	}
+
+	d := fallback
+	if out, err := runClaude(ctx, path, "--version"); err == nil {
+		if v, err := ParseVersion(out); err == nil {
+			d.Version = v.String()
+			if caps, ok := lookupCapabilities(v); ok {
+				d.Source = SourceMatrix
+				d.Capabilities = caps
+			}
+		}
+	}
+	if d.Source != SourceMatrix {
+		out, err := runClaude(ctx, path, "--help")
+		if err != nil {
+			return fallback, err
+		}
+		d.Source = SourceHelp
+		d.Capabilities = parseHelp(out)
+	}
+
+	writeCache(cacheDir, d)
+	return d, nil
+}
+
+func runClaude(ctx context.Context, path string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func readCache(dir, path string, modTime time.Time) (Detection, bool) {
+	if dir == "" {
+		return Detection{}, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, cacheFileName))
+	if err != nil {
+		return Detection{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Detection{}, false
+	}
+	d := entry.Detection
+	if entry.Matrix != matrixRevision || d.Path != path || !d.ModTime.Equal(modTime) {
+		return Detection{}, false
+	}
+	d.Cached = true
+	return d, true
+}
+
+// writeCache is best-effort: a failure only costs a re-detection next time.
+func writeCache(dir string, d Detection) {
+	if dir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cacheEntry{Matrix: matrixRevision, Detection: d}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	_ = storage.WriteFileAtomic(filepath.Join(dir, cacheFileName), data, 0o644)
+}
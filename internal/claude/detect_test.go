@@ -0,0 +1,100 @@
+package claude
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeClaude puts a claude script on PATH that logs each invocation.
+func fakeClaude(t *testing.T, version, help string) (logPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	logPath = filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$1" >> "` + logPath + `"
+case "$1" in
+--version) printf '%s\n' "` + version + `" ;;
+--help) cat <<'HELP'
+` + help + `
+HELP
+;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake claude: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func calls(t *testing.T, logPath string) []string {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	return strings.Fields(string(data))
+}
+
+func TestDetectKnownVersionUsesMatrixAndCache(t *testing.T) {
+	logPath := fakeClaude(t, "2.0.76 (Claude Code)", "")
+	cacheDir := t.TempDir()
+
+	d, err := Detect(context.Background(), cacheDir)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if d.Version != "2.0.76" || d.Source != SourceMatrix || !d.Capabilities.SupportsSessionID || d.Cached {
+		t.Fatalf("unexpected detection: %+v", d)
+	}
+	if got := calls(t, logPath); len(got) != 1 || got[0] != "--version" {
+		t.Fatalf("expected only --version to run, got %v", got)
+	}
+
+	d, err = Detect(context.Background(), cacheDir)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if !d.Cached || d.Version != "2.0.76" {
+		t.Fatalf("expected a cached detection, got %+v", d)
+	}
+	if got := calls(t, logPath); len(got) != 1 {
+		t.Fatalf("expected the cache to avoid running claude, got %v", got)
+	}
+}
+
+func TestDetectUnknownVersionParsesHelp(t *testing.T) {
+	logPath := fakeClaude(t, "9.1.0 (Claude Code)", `Options:
+  -r, --resume [sessionId]   Resume a conversation
+  --session-id <uuid>        Use a specific session ID
+  -n, --name <name>          Name the session`)
+
+	d, err := Detect(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	want := Capabilities{SupportsResume: true, SupportsSessionID: true, SessionNameFlag: "--name"}
+	if d.Source != SourceHelp || d.Capabilities != want || d.Version != "9.1.0" {
+		t.Fatalf("unexpected detection: %+v", d)
+	}
+	if got := calls(t, logPath); strings.Join(got, " ") != "--version --help" {
+		t.Fatalf("unexpected calls: %v", got)
+	}
+}
+
+func TestDetectWithoutClaude(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	d, err := Detect(context.Background(), "")
+	if err == nil {
+		t.Fatal("expected an error without claude on PATH")
+	}
+	if d.Source != SourceDefault || d.Capabilities != DefaultCapabilities() {
+		t.Fatalf("expected default capabilities, got %+v", d)
+	}
+}
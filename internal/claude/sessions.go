@@ -0,0 +1,138 @@
+package claude
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var sessionIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ErrNoSessionStore means claude has never stored a conversation for the
+// directory.
+var ErrNoSessionStore = errors.New("no claude conversations stored for this directory")
+
+// NewSessionID returns a random (version 4) UUID for --session-id.
+func NewSessionID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IsSessionID reports whether s looks like a claude conversation UUID.
+// Workspaces from before ccw tracked conversations hold the tmux name here.
+func IsSessionID(s string) bool {
+	return sessionIDPattern.MatchString(strings.ToLower(s))
+}
+
+// configDir is where claude keeps its state, honouring CLAUDE_CONFIG_DIR.
+func configDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude"), nil
+}
+
+// projectDirName mirrors how claude names a working directory's session
+// store: every character other than a letter or digit becomes a dash.
+func projectDirName(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// ProjectDir returns the directory holding claude's conversations for a
+// working directory. Both the path as given and with symlinks resolved are
+// tried, since claude records the real path. Returns ErrNoSessionStore when
+// neither exists.
+func ProjectDir(workdir string) (string, error) {
+	root, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{filepath.Clean(workdir)}
+	if resolved, err := filepath.EvalSymlinks(workdir); err == nil && resolved != candidates[0] {
+		candidates = append(candidates, resolved)
+	}
+	for _, c := range candidates {
+		dir := filepath.Join(root, "projects", projectDirName(c))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", ErrNoSessionStore
+}
+
+// SessionExists reports whether the conversation id is stored for workdir.
+func SessionExists(workdir, id string) (bool, error) {
+	if !IsSessionID(id) {
+		return false, nil
+	}
+	dir, err := ProjectDir(workdir)
+	if err != nil {
+		if errors.Is(err, ErrNoSessionStore) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, err = os.Stat(filepath.Join(dir, id+".jsonl"))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// LatestSession returns the most recently updated conversation stored for
+// workdir, or "" if there is none.
+func LatestSession(workdir string) (string, error) {
+	dir, err := ProjectDir(workdir)
+	if err != nil {
+		if errors.Is(err, ErrNoSessionStore) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestMod time.Time
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if e.IsDir() || !ok || !IsSessionID(id) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestMod) {
+			latest, latestMod = id, info.ModTime()
+		}
+	}
+	return latest, nil
+}
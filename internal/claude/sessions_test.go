@@ -0,0 +1,64 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewSessionID(t *testing.T) {
+	a, b := NewSessionID(), NewSessionID()
+	if !IsSessionID(a) || a == b {
+		t.Fatalf("unexpected ids %q, %q", a, b)
+	}
+	if IsSessionID("demo-feature-x") {
+		t.Fatal("tmux names are not session ids")
+	}
+}
+
+func TestProjectDirName(t *testing.T) {
+	got := projectDirName("/Users/me/.ccw/worktrees/demo-feature_x")
+	if got != "-Users-me--ccw-worktrees-demo-feature-x" {
+		t.Fatalf("unexpected project dir name %q", got)
+	}
+}
+
+func TestSessionLookup(t *testing.T) {
+	configRoot := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configRoot)
+	workdir := t.TempDir()
+
+	if latest, err := LatestSession(workdir); err != nil || latest != "" {
+		t.Fatalf("expected no sessions yet, got %q (%v)", latest, err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(workdir)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	store := filepath.Join(configRoot, "projects", projectDirName(resolved))
+	if err := os.MkdirAll(store, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	older, newer := NewSessionID(), NewSessionID()
+	for _, id := range []string{older, newer} {
+		if err := os.WriteFile(filepath.Join(store, id+".jsonl"), []byte("{}\n"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(store, older+".jsonl"), past, past); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	if ok, err := SessionExists(workdir, older); err != nil || !ok {
+		t.Fatalf("expected %s to exist (%v)", older, err)
+	}
+	if ok, _ := SessionExists(workdir, NewSessionID()); ok {
+		t.Fatal("expected unknown session to be missing")
+	}
+	if latest, err := LatestSession(workdir); err != nil || latest != newer {
+		t.Fatalf("expected latest %s, got %q (%v)", newer, latest, err)
+	}
+}
@@ -0,0 +1,76 @@
+package claude
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version is a claude CLI release, e.g. 2.0.76.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion reads the version from `claude --version` output, which looks
+// like "2.0.76 (Claude Code)".
+func ParseVersion(out string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(out)
+	if m == nil {
+		return Version{}, fmt.Errorf("no version in %q", out)
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is an older release than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// versionRange is a half-open range of releases [From, Until).
+type versionRange struct {
+	From, Until Version
+	Caps        Capabilities
+}
+
+// compatibility is what ccw knows about each range of claude releases. See
+// docs/claude-cli-behavior.md for where each row comes from. Releases
+// outside every range have their --help parsed instead.
+//
+// Bump matrixRevision whenever a row changes so cached detections are
+// redone.
+var compatibility = []versionRange{
+	// Verified on 2.0.76: --resume <uuid> and --session-id <uuid>, no name
+	// flag.
+	{From: Version{2, 0, 0}, Until: Version{2, 1, 0}, Caps: Capabilities{SupportsResume: true, SupportsSessionID: true}},
+	// 1.x resumes by UUID; --session-id arrived partway through, so don't
+	// rely on it.
+	{From: Version{1, 0, 0}, Until: Version{2, 0, 0}, Caps: Capabilities{SupportsResume: true}},
+}
+
+const matrixRevision = 1
+
+// lookupCapabilities returns the known capabilities of a release.
+func lookupCapabilities(v Version) (Capabilities, bool) {
+	for _, r := range compatibility {
+		if !v.Less(r.From) && v.Less(r.Until) {
+			return r.Caps, true
+		}
+	}
+	return Capabilities{}, false
+}
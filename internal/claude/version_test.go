@@ -0,0 +1,33 @@
+package claude
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("2.0.76 (Claude Code)\n")
+	if err != nil {
+		t.Fatalf("ParseVersion: %v", err)
+	}
+	if v != (Version{2, 0, 76}) || v.String() != "2.0.76" {
+		t.Fatalf("unexpected version %+v", v)
+	}
+	if _, err := ParseVersion("claude"); err == nil {
+		t.Fatal("expected an error without a version")
+	}
+}
+
+func TestLookupCapabilities(t *testing.T) {
+	caps, ok := lookupCapabilities(Version{2, 0, 76})
+	if !ok || !caps.SupportsResume || !caps.SupportsSessionID || caps.SessionNameFlag != "" {
+		t.Fatalf("unexpected capabilities for 2.0.76: %+v (%v)", caps, ok)
+	}
+	caps, ok = lookupCapabilities(Version{1, 0, 30})
+	if !ok || !caps.SupportsResume || caps.SupportsSessionID {
+		t.Fatalf("unexpected capabilities for 1.0.30: %+v (%v)", caps, ok)
+	}
+	if _, ok := lookupCapabilities(Version{2, 1, 0}); ok {
+		t.Fatal("expected releases past the matrix to be unknown")
+	}
+	if _, ok := lookupCapabilities(Version{0, 2, 9}); ok {
+		t.Fatal("expected releases before the matrix to be unknown")
+	}
+}
@@ -23,23 +23,103 @@ var ErrUnsupportedVersion = errors.New("unsupported config version")
 type Layout struct {
 	Left  string `json:"left"`
 	Right string `json:"right"`
+	// Panes, when set, replaces Left/Right with an arbitrary list of panes.
+	Panes []Pane `json:"panes,omitempty"`
 }
 
+// AgentConfig declares an agent CLI by its shell commands so it can be used
+// in layouts alongside the built-in claude and codex agents.
+type AgentConfig struct {
+	Command       string `json:"command"`
+	ResumeCommand string `json:"resume_command,omitempty"`
+	// Binary is looked up on PATH for dependency checks. Defaults to the
+	// first word of Command.
+	Binary      string `json:"binary,omitempty"`
+	InstallHint string `json:"install_hint,omitempty"`
+	// Optional agents are skipped when missing instead of failing workspace
+	// creation.
+	Optional bool `json:"optional,omitempty"`
+	// PromptArg means Command takes an initial prompt as a trailing
+	// argument. Without it, `ccw new -m` types the prompt into the pane.
+	PromptArg bool `json:"prompt_arg,omitempty"`
+	// ReadyMarkers is text the agent shows once it accepts input. ccw waits
+	// for one to appear before typing into the pane. Without any, `ccw new
+	// -m` can't tell the agent from the shell it starts in and doesn't type
+	// the prompt, and `ccw send` waits for the pane's output to settle.
+	ReadyMarkers []string `json:"ready_markers,omitempty"`
+	// BusyMarkers is text the agent shows while it works, so `ccw send
+	// --wait` can tell when it has finished replying.
+	BusyMarkers []string `json:"busy_markers,omitempty"`
+	// PermissionMarkers and ErrorMarkers are text the agent shows while it
+	// waits for approval and after a request fails, for the activity
+	// shown by `ccw ls`.
+	PermissionMarkers []string `json:"permission_markers,omitempty"`
+	ErrorMarkers      []string `json:"error_markers,omitempty"`
+}
+
+// Hook is a shell command run in a workspace at a lifecycle point.
+type Hook struct {
+	Run string `json:"run"`
+	// Timeout is in seconds. Zero uses the default of five minutes.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Hooks lists the commands to run at each workspace lifecycle point. Hooks
+// run in order and stop at the first failure.
+type Hooks struct {
+	// PostCreate runs in the new worktree before the session starts. A
+	// failure rolls the workspace back.
+	PostCreate []Hook `json:"post_create,omitempty"`
+	// PreOpen runs in the worktree before a workspace is opened. A failure
+	// aborts the open.
+	PreOpen []Hook `json:"pre_open,omitempty"`
+	// PreRemove runs in the worktree before anything is deleted. A failure
+	// aborts removal unless it is forced.
+	PreRemove []Hook `json:"pre_remove,omitempty"`
+	// PostRemove runs in the main repo after the workspace is gone.
+	PostRemove []Hook `json:"post_remove,omitempty"`
+}
+
+// RepoConfig holds per-repo settings. It can come from the user's config
+// (Config.Repos) or from a .ccw.json/.ccw.yaml committed in the repo; see
+// MergeRepoConfig for how the two combine.
 type RepoConfig struct {
-	CopyFiles []string `json:"copy_files"`
+	CopyFiles []CopyFile `json:"copy_files"`
+	Hooks     Hooks      `json:"hooks,omitzero"`
+	// Layout overrides the global pane layout for this repo.
+	Layout *Layout `json:"layout,omitempty"`
+	// BaseBranch is used when `ccw new` is not given --base.
+	BaseBranch string `json:"base_branch,omitempty"`
+	// Agents adds or overrides agent definitions for this repo.
+	Agents map[string]AgentConfig `json:"agents,omitempty"`
 }
 
 type Config struct {
-	Version                    int                   `json:"version"`
-	ReposDir                   string                `json:"repos_dir"`
-	ITermCCMode                bool                  `json:"iterm_cc_mode"`
-	ClaudeRenameDelay          int                   `json:"claude_rename_delay"`
-	Layout                     Layout                `json:"layout"`
-	Onboarded                  bool                  `json:"onboarded"`
-	ClaudeDangerouslySkipPerms bool                  `json:"claude_dangerously_skip_permissions"`
-	Repos                      map[string]RepoConfig `json:"repos,omitempty"`
+	Version                    int                    `json:"version"`
+	ReposDir                   string                 `json:"repos_dir"`
+	ITermCCMode                bool                   `json:"iterm_cc_mode"`
+	ClaudeRenameDelay          int                    `json:"claude_rename_delay"`
+	Layout                     Layout                 `json:"layout"`
+	Onboarded                  bool                   `json:"onboarded"`
+	ClaudeDangerouslySkipPerms bool                   `json:"claude_dangerously_skip_permissions"`
+	Repos                      map[string]RepoConfig  `json:"repos,omitempty"`
+	Agents                     map[string]AgentConfig `json:"agents,omitempty"`
+	// Forges maps remote hosts to a forge kind ("github", "gitlab", "gitea"
+	// or "git") for self-hosted instances ccw can't recognise by name.
+	Forges map[string]string `json:"forges,omitempty"`
+	// SyncStrategy is how `ccw sync` updates branches: "rebase" (the
+	// default when empty) or "merge".
+	SyncStrategy string `json:"sync_strategy,omitempty"`
+	// AgentReadyTimeout is how many seconds ccw waits for an agent to show
+	// it accepts input before typing into its pane anyway. Zero uses
+	// DefaultAgentReadyTimeout.
+	AgentReadyTimeout int `json:"agent_ready_timeout,omitempty"`
 }
 
+// DefaultAgentReadyTimeout is the agent_ready_timeout used when none is
+// set, in seconds. Agents can take this long to start on a cold cache.
+const DefaultAgentReadyTimeout = 30
+
 type Store struct {
 	root string
 }
@@ -70,6 +150,7 @@ func Default() Config {
 		Layout:                     Layout{Left: "claude", Right: "codex"},
 		Onboarded:                  false,
 		ClaudeDangerouslySkipPerms: false,
+		AgentReadyTimeout:          DefaultAgentReadyTimeout,
 	}
 }
 
@@ -95,6 +176,16 @@ func (s *Store) Load() (Config, error) {
 		return Config{}, fmt.Errorf("read config: %w", err)
 	}
 
+	// Loading takes no lock, so migrate in memory; Save writes the upgraded
+	// config, after backing up the old one.
+	data, _, err = storage.Migrate(data, CurrentVersion, configMigrations)
+	if err != nil {
+		if errors.Is(err, storage.ErrNewerVersion) {
+			return Config{}, fmt.Errorf("%w: %v", ErrUnsupportedVersion, err)
+		}
+		return Config{}, fmt.Errorf("migrate config: %w", err)
+	}
+
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("parse config: %w", err)
@@ -125,13 +216,8 @@ func (s *Store) Save(cfg Config) error {
 		return fmt.Errorf("encode config: %w", err)
 	}
 
-	tmpPath := s.Path() + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
-		return fmt.Errorf("write temp config: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, s.Path()); err != nil {
-		return fmt.Errorf("atomically write config: %w", err)
+	if err := storage.WriteFileAtomic(s.Path(), data, 0o644); err != nil {
+		return fmt.Errorf("write config: %w", err)
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -159,3 +160,37 @@ func TestConfigSaveCreatesBackup(t *testing.T) {
 		t.Fatalf("expected backup file to be created")
 	}
 }
+
+func TestConfigLoadWithoutVersion(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if err := os.WriteFile(store.Path(), []byte(`{"repos_dir": "~/src"}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.ReposDir != "~/src" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestConfigLoadNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if err := os.WriteFile(store.Path(), []byte(`{"version": 99}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
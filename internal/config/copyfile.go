@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// CopyModeCopy copies matched files into the worktree, keeping their
+	// permissions.
+	CopyModeCopy = "copy"
+	// CopyModeSymlink links matched paths back to the main checkout instead
+	// of copying them. Useful for large shared caches.
+	CopyModeSymlink = "symlink"
+)
+
+// CopyFile is an entry in copy_files. Path is relative to the repo root and
+// may be a file, a directory (copied recursively) or a doublestar glob such
+// as "config/**/*.local.json".
+//
+// In config files an entry is either a plain string, which copies, or an
+// object: {"path": "node_modules/.cache", "mode": "symlink"}.
+type CopyFile struct {
+	Path string `json:"path"`
+	Mode string `json:"mode,omitempty"`
+}
+
+// Symlink reports whether the entry should be linked rather than copied.
+func (c CopyFile) Symlink() bool {
+	return c.Mode == CopyModeSymlink
+}
+
+func (c *CopyFile) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*c = CopyFile{Path: path}
+		return nil
+	}
+
+	type plain CopyFile
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("copy_files entry must be a path or {\"path\", \"mode\"}: %w", err)
+	}
+	*c = CopyFile(p)
+	return nil
+}
+
+// MarshalJSON writes plain copies back as strings so existing configs keep
+// their shape.
+func (c CopyFile) MarshalJSON() ([]byte, error) {
+	if c.Mode == "" || c.Mode == CopyModeCopy {
+		return json.Marshal(c.Path)
+	}
+	type plain CopyFile
+	return json.Marshal(plain(c))
+}
+
+// Validate rejects entries that would reach outside the repo or use an
+// unknown mode.
+func (c CopyFile) Validate() error {
+	if strings.TrimSpace(c.Path) == "" {
+		return fmt.Errorf("copy_files: path is required")
+	}
+	switch c.Mode {
+	case "", CopyModeCopy, CopyModeSymlink:
+	default:
+		return fmt.Errorf("copy_files %q: invalid mode %q (want %q or %q)", c.Path, c.Mode, CopyModeCopy, CopyModeSymlink)
+	}
+	if filepath.IsAbs(c.Path) {
+		return fmt.Errorf("copy_files %q: path must be relative to the repo", c.Path)
+	}
+	clean := filepath.Clean(c.Path)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("copy_files %q: path cannot leave the repo", c.Path)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCopyFileUnmarshalStringAndObject(t *testing.T) {
+	var rc RepoConfig
+	data := `{"copy_files": [".env.local", {"path": "node_modules/.cache", "mode": "symlink"}]}`
+	if err := json.Unmarshal([]byte(data), &rc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(rc.CopyFiles) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", rc.CopyFiles)
+	}
+	if rc.CopyFiles[0].Path != ".env.local" || rc.CopyFiles[0].Symlink() {
+		t.Fatalf("unexpected string entry: %+v", rc.CopyFiles[0])
+	}
+	if rc.CopyFiles[1].Path != "node_modules/.cache" || !rc.CopyFiles[1].Symlink() {
+		t.Fatalf("unexpected object entry: %+v", rc.CopyFiles[1])
+	}
+}
+
+func TestCopyFileMarshalKeepsPlainCopiesAsStrings(t *testing.T) {
+	data, err := json.Marshal([]CopyFile{{Path: ".env.local"}, {Path: ".cache", Mode: CopyModeSymlink}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `[".env.local",{"path":".cache","mode":"symlink"}]`
+	if string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+}
+
+func TestCopyFileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   CopyFile
+		wantErr bool
+	}{
+		{"plain file", CopyFile{Path: ".env.local"}, false},
+		{"glob", CopyFile{Path: "config/**/*.json"}, false},
+		{"symlink", CopyFile{Path: "node_modules/.cache", Mode: CopyModeSymlink}, false},
+		{"empty path", CopyFile{}, true},
+		{"bad mode", CopyFile{Path: "a", Mode: "hardlink"}, true},
+		{"absolute", CopyFile{Path: "/etc/passwd"}, true},
+		{"escapes repo", CopyFile{Path: "../other/.env"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() err=%v, wantErr=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SplitHorizontal places a pane to the right of the pane it splits.
+	SplitHorizontal = "horizontal"
+	// SplitVertical places a pane below the pane it splits.
+	SplitVertical = "vertical"
+)
+
+// Pane describes a single tmux pane in a workspace session.
+//
+// Command is typed into the pane once it starts. Agent names (the built-in
+// "claude" and "codex", or any key of Config.Agents) are resolved to the
+// agent's launch command; any other value is sent verbatim, and an empty
+// command leaves a plain shell.
+type Pane struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"`
+	// Split controls how the pane is created from the pane it splits
+	// ("horizontal" or "vertical"). Defaults to horizontal. Ignored for the
+	// first pane.
+	Split string `json:"split,omitempty"`
+	// Size is the percentage of the split pane given to this pane. Zero lets
+	// tmux pick (an even split).
+	Size int `json:"size,omitempty"`
+	// Dir is a working directory relative to the worktree root.
+	Dir string `json:"dir,omitempty"`
+	// From names the pane to split. Defaults to the previous pane.
+	From string `json:"from,omitempty"`
+}
+
+// ResolvedPanes returns the panes to create for a session. An explicit Panes
+// list wins; otherwise the legacy Left/Right pair is turned into a two-pane
+// side-by-side layout.
+func (l Layout) ResolvedPanes() []Pane {
+	if len(l.Panes) > 0 {
+		return append([]Pane(nil), l.Panes...)
+	}
+
+	left, right := l.Left, l.Right
+	if left == "" && right == "" {
+		def := Default().Layout
+		left, right = def.Left, def.Right
+	}
+
+	panes := []Pane{{Name: "left", Command: left}}
+	if right != "" {
+		panes = append(panes, Pane{Name: "right", Command: right, Split: SplitHorizontal})
+	}
+	return panes
+}
+
+// Validate checks the resolved panes for problems that would otherwise only
+// surface as tmux errors halfway through building a session.
+func (l Layout) Validate() error {
+	panes := l.ResolvedPanes()
+	seen := make(map[string]bool, len(panes))
+	for i, p := range panes {
+		if p.Name == "" {
+			return fmt.Errorf("layout pane %d: name is required", i)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("layout pane %q: duplicate name", p.Name)
+		}
+		switch p.Split {
+		case "", SplitHorizontal, SplitVertical:
+		default:
+			return fmt.Errorf("layout pane %q: invalid split %q (want %q or %q)", p.Name, p.Split, SplitHorizontal, SplitVertical)
+		}
+		if p.Size < 0 || p.Size > 99 {
+			return fmt.Errorf("layout pane %q: size must be between 0 and 99", p.Name)
+		}
+		if p.Dir != "" {
+			if filepath.IsAbs(p.Dir) {
+				return fmt.Errorf("layout pane %q: dir must be relative to the worktree", p.Name)
+			}
+			clean := filepath.Clean(p.Dir)
+			if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("layout pane %q: dir cannot leave the worktree", p.Name)
+			}
+		}
+		if p.From != "" {
+			if i == 0 {
+				return fmt.Errorf("layout pane %q: first pane cannot split another pane", p.Name)
+			}
+			if !seen[p.From] {
+				return fmt.Errorf("layout pane %q: from %q must name an earlier pane", p.Name, p.From)
+			}
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import "testing"
+
+func TestResolvedPanesFromLeftRight(t *testing.T) {
+	panes := Layout{Left: "codex", Right: "claude"}.ResolvedPanes()
+	if len(panes) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(panes))
+	}
+	if panes[0].Command != "codex" || panes[1].Command != "claude" {
+		t.Fatalf("unexpected pane commands: %+v", panes)
+	}
+	if panes[1].Split != SplitHorizontal {
+		t.Fatalf("expected right pane to split horizontally, got %q", panes[1].Split)
+	}
+}
+
+func TestResolvedPanesEmptyLayoutUsesDefault(t *testing.T) {
+	panes := Layout{}.ResolvedPanes()
+	if len(panes) != 2 || panes[0].Command != "claude" || panes[1].Command != "codex" {
+		t.Fatalf("expected default claude|codex layout, got %+v", panes)
+	}
+}
+
+func TestResolvedPanesPrefersExplicitPanes(t *testing.T) {
+	layout := Layout{
+		Left:  "claude",
+		Right: "codex",
+		Panes: []Pane{{Name: "agent", Command: "claude"}, {Name: "dev", Command: "npm run dev"}, {Name: "tests", Command: "npm test -- --watch"}},
+	}
+	panes := layout.ResolvedPanes()
+	if len(panes) != 3 || panes[2].Name != "tests" {
+		t.Fatalf("expected explicit panes, got %+v", panes)
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		panes   []Pane
+		wantErr bool
+	}{
+		{"valid", []Pane{{Name: "a"}, {Name: "b", Split: SplitVertical, Size: 30, Dir: "web"}, {Name: "c", From: "a"}}, false},
+		{"missing name", []Pane{{Name: "a"}, {}}, true},
+		{"duplicate name", []Pane{{Name: "a"}, {Name: "a"}}, true},
+		{"bad split", []Pane{{Name: "a"}, {Name: "b", Split: "diagonal"}}, true},
+		{"bad size", []Pane{{Name: "a"}, {Name: "b", Size: 100}}, true},
+		{"absolute dir", []Pane{{Name: "a", Dir: "/tmp"}}, true},
+		{"escaping dir", []Pane{{Name: "a", Dir: "../other"}}, true},
+		{"forward from", []Pane{{Name: "a"}, {Name: "b", From: "c"}, {Name: "c"}}, true},
+		{"first pane from", []Pane{{Name: "a", From: "a"}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Layout{Panes: tc.panes}.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+import "github.com/ccw/ccw/internal/storage"
+
+// configMigrations upgrade config.json one version at a time. Append a
+// migration here whenever CurrentVersion is bumped.
+var configMigrations = []storage.Migration{
+	{From: 0, Apply: migrateConfigV0},
+}
+
+// migrateConfigV0 accepts hand-written configs that predate the version
+// field. Their fields already match v1, so only the version changes.
+func migrateConfigV0(storage.Document) error {
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Repo-local config files, committed at the root of a repository so every
+// teammate gets the same workspace setup without editing ~/.ccw/config.json.
+const (
+	RepoFileName     = ".ccw.json"
+	RepoYAMLFileName = ".ccw.yaml"
+)
+
+// LoadRepoFile reads the repo-local config at the root of repoPath. found is
+// false when the repo has no such file. Having both a JSON and a YAML file is
+// an error, since it's unclear which one the team maintains.
+func LoadRepoFile(repoPath string) (rc RepoConfig, found bool, err error) {
+	jsonPath := filepath.Join(repoPath, RepoFileName)
+	yamlPath := filepath.Join(repoPath, RepoYAMLFileName)
+
+	jsonData, jsonErr := os.ReadFile(jsonPath)
+	yamlData, yamlErr := os.ReadFile(yamlPath)
+	if jsonErr != nil && !os.IsNotExist(jsonErr) {
+		return RepoConfig{}, false, fmt.Errorf("read %s: %w", RepoFileName, jsonErr)
+	}
+	if yamlErr != nil && !os.IsNotExist(yamlErr) {
+		return RepoConfig{}, false, fmt.Errorf("read %s: %w", RepoYAMLFileName, yamlErr)
+	}
+
+	switch {
+	case jsonErr == nil && yamlErr == nil:
+		return RepoConfig{}, false, fmt.Errorf("both %s and %s exist in %s; keep only one", RepoFileName, RepoYAMLFileName, repoPath)
+	case jsonErr == nil:
+		if err := json.Unmarshal(jsonData, &rc); err != nil {
+			return RepoConfig{}, false, fmt.Errorf("parse %s: %w", RepoFileName, err)
+		}
+	case yamlErr == nil:
+		// Round-trip through JSON so the YAML keys match the JSON field tags.
+		var doc any
+		if err := yaml.Unmarshal(yamlData, &doc); err != nil {
+			return RepoConfig{}, false, fmt.Errorf("parse %s: %w", RepoYAMLFileName, err)
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return RepoConfig{}, false, fmt.Errorf("parse %s: %w", RepoYAMLFileName, err)
+		}
+		if err := json.Unmarshal(data, &rc); err != nil {
+			return RepoConfig{}, false, fmt.Errorf("parse %s: %w", RepoYAMLFileName, err)
+		}
+	default:
+		return RepoConfig{}, false, nil
+	}
+
+	for _, cf := range rc.CopyFiles {
+		if err := cf.Validate(); err != nil {
+			return RepoConfig{}, false, fmt.Errorf("%s: %w", filepath.Base(repoPath), err)
+		}
+	}
+	if rc.Layout != nil {
+		if err := rc.Layout.Validate(); err != nil {
+			return RepoConfig{}, false, fmt.Errorf("%s: %w", filepath.Base(repoPath), err)
+		}
+	}
+	return rc, true, nil
+}
+
+// MergeRepoConfig layers the user's settings for a repo over the repo-local
+// file. The user always gets the last word:
+//
+//   - copy_files: union of both lists, repo file entries first; when both
+//     list the same path, the user's mode wins.
+//   - hooks: for each lifecycle point the repo file's hooks run first, then
+//     the user's.
+//   - layout, base_branch: the user's value wins when set.
+//   - agents: merged by name; the user's definition wins.
+func MergeRepoConfig(shared, user RepoConfig) RepoConfig {
+	merged := RepoConfig{
+		CopyFiles:  mergeCopyFiles(shared.CopyFiles, user.CopyFiles),
+		Layout:     shared.Layout,
+		BaseBranch: shared.BaseBranch,
+		Hooks: Hooks{
+			PostCreate: concatHooks(shared.Hooks.PostCreate, user.Hooks.PostCreate),
+			PreOpen:    concatHooks(shared.Hooks.PreOpen, user.Hooks.PreOpen),
+			PreRemove:  concatHooks(shared.Hooks.PreRemove, user.Hooks.PreRemove),
+			PostRemove: concatHooks(shared.Hooks.PostRemove, user.Hooks.PostRemove),
+		},
+	}
+
+	if user.Layout != nil {
+		merged.Layout = user.Layout
+	}
+	if user.BaseBranch != "" {
+		merged.BaseBranch = user.BaseBranch
+	}
+
+	if len(shared.Agents) > 0 || len(user.Agents) > 0 {
+		merged.Agents = make(map[string]AgentConfig, len(shared.Agents)+len(user.Agents))
+		for name, a := range shared.Agents {
+			merged.Agents[name] = a
+		}
+		for name, a := range user.Agents {
+			merged.Agents[name] = a
+		}
+	}
+
+	return merged
+}
+
+func mergeCopyFiles(shared, user []CopyFile) []CopyFile {
+	var out []CopyFile
+	index := make(map[string]int, len(shared)+len(user))
+	for _, list := range [][]CopyFile{shared, user} {
+		for _, cf := range list {
+			if i, ok := index[cf.Path]; ok {
+				out[i] = cf
+				continue
+			}
+			index[cf.Path] = len(out)
+			out = append(out, cf)
+		}
+	}
+	return out
+}
+
+func concatHooks(a, b []Hook) []Hook {
+	if len(a) == 0 {
+		return b
+	}
+	return append(append([]Hook(nil), a...), b...)
+}
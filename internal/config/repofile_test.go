@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRepoFileMissing(t *testing.T) {
+	_, found, err := LoadRepoFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadRepoFile: %v", err)
+	}
+	if found {
+		t.Fatalf("expected no repo file")
+	}
+}
+
+func TestLoadRepoFileJSON(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"copy_files": [".env.local"], "base_branch": "develop", "hooks": {"post_create": [{"run": "npm install", "timeout": 600}]}}`
+	if err := os.WriteFile(filepath.Join(dir, RepoFileName), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	rc, found, err := LoadRepoFile(dir)
+	if err != nil || !found {
+		t.Fatalf("LoadRepoFile: found=%v err=%v", found, err)
+	}
+	if rc.BaseBranch != "develop" || len(rc.CopyFiles) != 1 {
+		t.Fatalf("unexpected repo config: %+v", rc)
+	}
+	if len(rc.Hooks.PostCreate) != 1 || rc.Hooks.PostCreate[0].Timeout != 600 {
+		t.Fatalf("unexpected hooks: %+v", rc.Hooks)
+	}
+}
+
+func TestLoadRepoFileYAML(t *testing.T) {
+	dir := t.TempDir()
+	data := `copy_files:
+  - .env.local
+layout:
+  panes:
+    - name: agent
+      command: claude
+    - name: dev
+      command: npm run dev
+      split: vertical
+      size: 30
+agents:
+  aider:
+    command: aider
+    optional: true
+`
+	if err := os.WriteFile(filepath.Join(dir, RepoYAMLFileName), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	rc, found, err := LoadRepoFile(dir)
+	if err != nil || !found {
+		t.Fatalf("LoadRepoFile: found=%v err=%v", found, err)
+	}
+	if rc.Layout == nil || len(rc.Layout.Panes) != 2 || rc.Layout.Panes[1].Size != 30 {
+		t.Fatalf("unexpected layout: %+v", rc.Layout)
+	}
+	if a, ok := rc.Agents["aider"]; !ok || !a.Optional {
+		t.Fatalf("unexpected agents: %+v", rc.Agents)
+	}
+}
+
+func TestLoadRepoFileRejectsBothFormats(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{RepoFileName, RepoYAMLFileName} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	if _, _, err := LoadRepoFile(dir); err == nil {
+		t.Fatalf("expected error when both repo files exist")
+	}
+}
+
+func TestLoadRepoFileRejectsInvalidLayout(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"layout": {"panes": [{"name": "a"}, {"name": "a"}]}}`
+	if err := os.WriteFile(filepath.Join(dir, RepoFileName), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, _, err := LoadRepoFile(dir); err == nil {
+		t.Fatalf("expected invalid layout to be rejected")
+	}
+}
+
+func TestMergeRepoConfigUserWins(t *testing.T) {
+	sharedLayout := &Layout{Left: "claude"}
+	userLayout := &Layout{Left: "codex"}
+	shared := RepoConfig{
+		CopyFiles:  []CopyFile{{Path: ".env.local"}, {Path: "config/dev.json"}},
+		BaseBranch: "develop",
+		Layout:     sharedLayout,
+		Hooks:      Hooks{PostCreate: []Hook{{Run: "npm install"}}},
+		Agents:     map[string]AgentConfig{"aider": {Command: "aider"}, "gemini": {Command: "gemini"}},
+	}
+	user := RepoConfig{
+		CopyFiles:  []CopyFile{{Path: "config/dev.json", Mode: CopyModeSymlink}, {Path: ".secrets"}},
+		BaseBranch: "main",
+		Layout:     userLayout,
+		Hooks:      Hooks{PostCreate: []Hook{{Run: "direnv allow"}}},
+		Agents:     map[string]AgentConfig{"aider": {Command: "aider --model gpt-4o"}},
+	}
+
+	merged := MergeRepoConfig(shared, user)
+
+	if got := merged.CopyFiles; len(got) != 3 || got[0].Path != ".env.local" || got[2].Path != ".secrets" {
+		t.Fatalf("unexpected copy files: %v", got)
+	}
+	if !merged.CopyFiles[1].Symlink() {
+		t.Fatalf("expected user mode to win for config/dev.json, got %+v", merged.CopyFiles[1])
+	}
+	if merged.BaseBranch != "main" {
+		t.Fatalf("expected user base branch to win, got %q", merged.BaseBranch)
+	}
+	if merged.Layout != userLayout {
+		t.Fatalf("expected user layout to win")
+	}
+	if hooks := merged.Hooks.PostCreate; len(hooks) != 2 || hooks[0].Run != "npm install" || hooks[1].Run != "direnv allow" {
+		t.Fatalf("expected repo hooks before user hooks, got %+v", hooks)
+	}
+	if merged.Agents["aider"].Command != "aider --model gpt-4o" || merged.Agents["gemini"].Command != "gemini" {
+		t.Fatalf("unexpected merged agents: %+v", merged.Agents)
+	}
+}
+
+func TestMergeRepoConfigKeepsSharedDefaults(t *testing.T) {
+	layout := &Layout{Left: "claude"}
+	merged := MergeRepoConfig(RepoConfig{BaseBranch: "develop", Layout: layout}, RepoConfig{})
+	if merged.BaseBranch != "develop" || merged.Layout != layout {
+		t.Fatalf("expected repo file values when user sets nothing: %+v", merged)
+	}
+}
@@ -20,6 +20,8 @@ type Result struct {
 	Path       string
 }
 
+// DefaultDependencies returns the tools ccw itself needs. Agent CLIs are
+// declared by the agent package and forge CLIs by the forge package.
 func DefaultDependencies() []Dependency {
 	return []Dependency{
 		{
@@ -37,22 +39,6 @@ func DefaultDependencies() []Dependency {
 			DisplayName: "iTerm2",
 			InstallHint: "Install iTerm2: https://iterm2.com",
 		},
-		{
-			Name:        "claude",
-			DisplayName: "Claude Code CLI",
-			InstallHint: "Install Claude Code CLI: https://claude.com/claude-code",
-		},
-		{
-			Name:        "codex",
-			DisplayName: "Codex CLI",
-			Optional:    true,
-			InstallHint: "Optional: install with `npm i -g @openai/codex` or `brew install codex`.",
-		},
-		{
-			Name:        "gh",
-			DisplayName: "GitHub CLI",
-			InstallHint: "Install with `brew install gh` then run `gh auth login`.",
-		},
 	}
 }
 
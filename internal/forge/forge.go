@@ -0,0 +1,151 @@
+// Package forge abstracts the code hosting service behind a repo's origin
+// remote: GitHub, GitLab, Gitea or a plain git server with no review tooling.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/ccw/ccw/internal/deps"
+)
+
+// Forge kinds, as used in the "forges" config map.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindGitea  = "gitea"
+	KindPlain  = "git"
+)
+
+// Provider is the forge integration for one repository.
+type Provider interface {
+	// Kind is one of the Kind constants.
+	Kind() string
+	// Host is the origin remote's host name, or "" when there is none.
+	Host() string
+	// Dependency returns the CLI the provider shells out to. ok is false when
+	// no CLI is needed.
+	Dependency() (dep deps.Dependency, ok bool)
+	// CheckAuth verifies the CLI is logged in to Host.
+	CheckAuth(ctx context.Context) error
+	// MergeStatus reports whether branch was merged through a PR/MR. found is
+	// false when the forge knows of no PR/MR for the branch; callers then fall
+	// back to git heuristics. It has the shape of git.MergeChecker.
+	MergeStatus(ctx context.Context, branch string) (merged bool, found bool, err error)
+
+	// CreatePR opens a pull/merge request.
+	CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error)
+	// ViewPR looks up a pull/merge request by number or source branch.
+	ViewPR(ctx context.Context, ref string) (PullRequest, error)
+	// OpenPR shows a pull/merge request in the browser.
+	OpenPR(ctx context.Context, ref string) error
+}
+
+// MergeStatusLister is implemented by providers that can report the merge
+// status of every branch with a PR/MR in one call, which is much cheaper
+// than calling MergeStatus per branch.
+type MergeStatusLister interface {
+	// MergeStatuses maps source branches to whether any of their PRs/MRs
+	// was merged. Branches with no PR/MR are absent.
+	MergeStatuses(ctx context.Context) (map[string]bool, error)
+}
+
+// PRRetargeter is implemented by providers that can change the branch an
+// open PR/MR merges into. Forges close PRs whose base branch is deleted, so
+// stacked PRs must be moved off a branch before it goes.
+type PRRetargeter interface {
+	// RetargetPRs points head's open PRs/MRs into from at to instead.
+	// Having none is not an error.
+	RetargetPRs(ctx context.Context, head, from, to string) error
+}
+
+// Dependencies returns the CLIs of every forge. All are optional: which one
+// is needed depends on where a repo is hosted.
+func Dependencies() []deps.Dependency {
+	return []deps.Dependency{ghDependency, glabDependency, teaDependency}
+}
+
+// Kinds returns the valid forge kinds.
+func Kinds() []string {
+	return []string{KindGitHub, KindGitLab, KindGitea, KindPlain}
+}
+
+// Detect picks the provider for the repo at repoPath from its origin URL.
+// overrides maps host names to kinds and wins over the built-in guesses,
+// which is how self-hosted instances are recognised.
+func Detect(repoPath string, overrides map[string]string) (Provider, error) {
+	out, err := exec.Command("git", "-C", repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		// No origin: nothing to talk to but git itself.
+		return New(KindPlain, "", repoPath)
+	}
+
+	host := RemoteHost(strings.TrimSpace(string(out)))
+	kind := KindFor(host, overrides)
+	return New(kind, host, repoPath)
+}
+
+// New returns the provider of the given kind for repoPath.
+func New(kind, host, repoPath string) (Provider, error) {
+	switch kind {
+	case KindGitHub:
+		return newGitHub(host, repoPath), nil
+	case KindGitLab:
+		return &gitLab{host: host, repoPath: repoPath}, nil
+	case KindGitea:
+		return &gitea{host: host, repoPath: repoPath}, nil
+	case KindPlain, "":
+		return &plain{host: host}, nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q (want one of %s)", kind, strings.Join(Kinds(), ", "))
+	}
+}
+
+// KindFor maps a remote host to a forge kind. Hosts without an override are
+// guessed from well-known names; anything unrecognised is plain git.
+func KindFor(host string, overrides map[string]string) string {
+	host = strings.ToLower(host)
+	if kind, ok := overrides[host]; ok {
+		return kind
+	}
+
+	switch {
+	case host == "":
+		return KindPlain
+	case host == "github.com":
+		return KindGitHub
+	case host == "gitlab.com", strings.HasPrefix(host, "gitlab."):
+		return KindGitLab
+	case host == "gitea.com", host == "codeberg.org", strings.HasPrefix(host, "gitea."), strings.HasPrefix(host, "forgejo."):
+		return KindGitea
+	default:
+		return KindPlain
+	}
+}
+
+// RemoteHost extracts the host from a git remote URL. It understands URLs
+// (https://, ssh://, git://) and scp-like "user@host:path" remotes, and
+// returns "" for local paths.
+func RemoteHost(remote string) string {
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" {
+			return ""
+		}
+		return strings.ToLower(u.Hostname())
+	}
+
+	// scp-like syntax: [user@]host:path. A colon after a slash is a path.
+	colon := strings.Index(remote, ":")
+	if colon <= 0 || strings.Contains(remote[:colon], "/") {
+		return ""
+	}
+	host := remote[:colon]
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,193 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ccw/ccw/internal/deps"
+)
+
+var teaDependency = deps.Dependency{
+	Name:        "tea",
+	DisplayName: "Gitea CLI",
+	Optional:    true,
+	InstallHint: "Install with `brew install tea` then run `tea login add`.",
+}
+
+type gitea struct {
+	host     string
+	repoPath string
+}
+
+func (g *gitea) Kind() string { return KindGitea }
+func (g *gitea) Host() string { return g.host }
+
+func (g *gitea) Dependency() (deps.Dependency, bool) { return teaDependency, true }
+
+// CheckAuth looks for a tea login whose URL points at the host; tea has no
+// per-host status command.
+func (g *gitea) CheckAuth(ctx context.Context) error {
+	out, err := exec.CommandContext(ctx, "tea", "login", "list", "--output", "simple").Output()
+	if err != nil || !strings.Contains(strings.ToLower(string(out)), g.host) {
+		return fmt.Errorf("tea has no login for %s; run `tea login add --url https://%s`", g.host, g.host)
+	}
+	return nil
+}
+
+func (g *gitea) MergeStatus(ctx context.Context, branch string) (bool, bool, error) {
+	pulls, err := g.listPulls(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	merged, found := parseTeaPulls(pulls, branch)
+	return merged, found, nil
+}
+
+func (g *gitea) CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		// Gitea marks work-in-progress pulls by title prefix.
+		title = "WIP: " + title
+	}
+	args := []string{"pulls", "create", "--head", opts.Head, "--title", title, "--description", opts.Body}
+	if opts.Base != "" {
+		args = append(args, "--base", opts.Base)
+	}
+	if _, err := g.run(ctx, args...); err != nil {
+		return PullRequest{}, err
+	}
+	return g.ViewPR(ctx, opts.Head)
+}
+
+// ViewPR finds a pull by index or head branch. tea reports neither checks
+// nor reviews in its listing.
+func (g *gitea) ViewPR(ctx context.Context, ref string) (PullRequest, error) {
+	pulls, err := g.listPulls(ctx)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return findTeaPull(pulls, ref)
+}
+
+func (g *gitea) OpenPR(ctx context.Context, ref string) error {
+	pr, err := g.ViewPR(ctx, ref)
+	if err != nil {
+		return err
+	}
+	_, err = g.run(ctx, "open", strconv.Itoa(pr.Number))
+	return err
+}
+
+// teaPageSize and teaMaxPages bound listPulls. tea returns one page of
+// about 30 pulls unless asked for more, so pulls are read page by page, up
+// to the newest teaPageSize*teaMaxPages.
+const (
+	teaPageSize = 50
+	teaMaxPages = 20
+)
+
+// listPulls returns the repo's pulls, newest first, as `tea pulls list
+// --output json` prints them: every field a string.
+func (g *gitea) listPulls(ctx context.Context) ([]map[string]string, error) {
+	return collectTeaPages(func(page int) ([]byte, error) {
+		return g.run(ctx, "pulls", "list", "--state", "all", "--output", "json",
+			"--fields", "index,state,head,base,title,url",
+			"--page", strconv.Itoa(page), "--limit", strconv.Itoa(teaPageSize))
+	})
+}
+
+// collectTeaPages reads pages from fetch, starting at 1, until one comes
+// back short or teaMaxPages have been read.
+func collectTeaPages(fetch func(page int) ([]byte, error)) ([]map[string]string, error) {
+	var all []map[string]string
+	for page := 1; page <= teaMaxPages; page++ {
+		out, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		var pulls []map[string]string
+		if err := json.Unmarshal(out, &pulls); err != nil {
+			return nil, err
+		}
+		all = append(all, pulls...)
+		if len(pulls) < teaPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+func (g *gitea) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "tea", args...)
+	cmd.Dir = g.repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tea %s %s: %w: %s", args[0], args[1], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// MergeStatuses reads the same listing MergeStatus does, once for all
+// branches.
+func (g *gitea) MergeStatuses(ctx context.Context) (map[string]bool, error) {
+	pulls, err := g.listPulls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return teaMergeStatuses(pulls), nil
+}
+
+// parseTeaPulls looks for pulls whose head is branch in a listPulls
+// listing.
+func parseTeaPulls(pulls []map[string]string, branch string) (merged bool, found bool) {
+	merged, found = teaMergeStatuses(pulls)[branch]
+	return merged, found
+}
+
+// teaMergeStatuses maps every pull's head branch to whether its newest
+// pull, which tea lists first, was merged. An older merged pull from a
+// reused branch name doesn't count. tea doesn't say which repo a pull's
+// head is in, so unlike GitHub, pulls from forks can't be left out.
+func teaMergeStatuses(pulls []map[string]string) map[string]bool {
+	statuses := make(map[string]bool, len(pulls))
+	for _, pr := range pulls {
+		if _, seen := statuses[pr["head"]]; !seen {
+			statuses[pr["head"]] = pr["state"] == "merged"
+		}
+	}
+	return statuses
+}
+
+// findTeaPull picks the pull matching ref (an index or head branch) from a
+// listPulls listing. When a branch has several pulls the newest, which tea
+// lists first, wins.
+func findTeaPull(pulls []map[string]string, ref string) (PullRequest, error) {
+	for _, p := range pulls {
+		if p["index"] != ref && p["head"] != ref {
+			continue
+		}
+		n, _ := strconv.Atoi(p["index"])
+		state := p["state"]
+		if state != PRStateMerged && state != PRStateClosed {
+			state = PRStateOpen
+		}
+		return PullRequest{
+			Number:     n,
+			URL:        p["url"],
+			Title:      p["title"],
+			State:      state,
+			HeadBranch: p["head"],
+			BaseBranch: p["base"],
+			FetchRef:   fmt.Sprintf("refs/pull/%d/head", n),
+		}, nil
+	}
+	return PullRequest{}, fmt.Errorf("no pull request found for %s", ref)
+}
@@ -0,0 +1,109 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccw/ccw/internal/deps"
+	"github.com/ccw/ccw/internal/github"
+)
+
+var ghDependency = deps.Dependency{
+	Name:        "gh",
+	DisplayName: "GitHub CLI",
+	Optional:    true,
+	InstallHint: "Install with `brew install gh` then run `gh auth login`.",
+}
+
+type gitHub struct {
+	host   string
+	client *github.Client
+}
+
+func newGitHub(host, repoPath string) *gitHub {
+	if host == "" {
+		host = "github.com"
+	}
+	return &gitHub{host: host, client: github.NewClient(repoPath)}
+}
+
+func (g *gitHub) Kind() string { return KindGitHub }
+func (g *gitHub) Host() string { return g.host }
+
+func (g *gitHub) Dependency() (deps.Dependency, bool) { return ghDependency, true }
+
+func (g *gitHub) CheckAuth(ctx context.Context) error {
+	return github.CheckAuthenticatedHost(g.host)
+}
+
+func (g *gitHub) MergeStatus(ctx context.Context, branch string) (bool, bool, error) {
+	return g.client.IsPRMerged(ctx, branch)
+}
+
+// MergeStatuses lists the repo's recent PRs with a single `gh pr list`.
+func (g *gitHub) MergeStatuses(ctx context.Context) (map[string]bool, error) {
+	return g.client.PRMergeStatuses(ctx)
+}
+
+func (g *gitHub) CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error) {
+	pr, err := g.client.CreatePR(ctx, github.CreatePROptions{
+		Head:  opts.Head,
+		Base:  opts.Base,
+		Title: opts.Title,
+		Body:  opts.Body,
+		Draft: opts.Draft,
+	})
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return fromGitHub(pr), nil
+}
+
+func (g *gitHub) ViewPR(ctx context.Context, ref string) (PullRequest, error) {
+	pr, err := g.client.ViewPR(ctx, ref)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return fromGitHub(pr), nil
+}
+
+func (g *gitHub) OpenPR(ctx context.Context, ref string) error {
+	return g.client.OpenPR(ctx, ref)
+}
+
+func (g *gitHub) RetargetPRs(ctx context.Context, head, from, to string) error {
+	return g.client.RetargetPRs(ctx, head, from, to)
+}
+
+func fromGitHub(pr github.PullRequest) PullRequest {
+	state := strings.ToLower(pr.State)
+	if state == PRStateOpen && pr.IsDraft {
+		state = PRStateDraft
+	}
+
+	var checks []string
+	for _, c := range pr.Checks {
+		switch {
+		case c.State != "":
+			checks = append(checks, c.State)
+		case !strings.EqualFold(c.Status, "completed"):
+			checks = append(checks, c.Status)
+		default:
+			checks = append(checks, c.Conclusion)
+		}
+	}
+
+	return PullRequest{
+		Number:          pr.Number,
+		URL:             pr.URL,
+		Title:           pr.Title,
+		State:           state,
+		Checks:          summarizeChecks(checks),
+		ReviewDecision:  strings.ToLower(pr.ReviewDecision),
+		HeadBranch:      pr.HeadRefName,
+		BaseBranch:      pr.BaseRefName,
+		CrossRepository: pr.IsCrossRepo,
+		FetchRef:        fmt.Sprintf("refs/pull/%d/head", pr.Number),
+	}
+}
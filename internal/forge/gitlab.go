@@ -0,0 +1,173 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ccw/ccw/internal/deps"
+)
+
+var glabDependency = deps.Dependency{
+	Name:        "glab",
+	DisplayName: "GitLab CLI",
+	Optional:    true,
+	InstallHint: "Install with `brew install glab` then run `glab auth login`.",
+}
+
+type gitLab struct {
+	host     string
+	repoPath string
+}
+
+func (g *gitLab) Kind() string { return KindGitLab }
+func (g *gitLab) Host() string { return g.host }
+
+func (g *gitLab) Dependency() (deps.Dependency, bool) { return glabDependency, true }
+
+func (g *gitLab) CheckAuth(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "glab", "auth", "status", "--hostname", g.host)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("glab is not authenticated for %s; run `glab auth login --hostname %s`", g.host, g.host)
+	}
+	return nil
+}
+
+func (g *gitLab) MergeStatus(ctx context.Context, branch string) (bool, bool, error) {
+	out, err := g.run(ctx, "mr", "list", "--source-branch", branch, "--all", "--output", "json")
+	if err != nil {
+		return false, false, err
+	}
+	return parseGitLabMRs(out)
+}
+
+func (g *gitLab) CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error) {
+	args := []string{"mr", "create", "--source-branch", opts.Head,
+		"--title", opts.Title, "--description", opts.Body, "--yes"}
+	if opts.Base != "" {
+		args = append(args, "--target-branch", opts.Base)
+	}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+	if _, err := g.run(ctx, args...); err != nil {
+		return PullRequest{}, err
+	}
+	return g.ViewPR(ctx, opts.Head)
+}
+
+func (g *gitLab) ViewPR(ctx context.Context, ref string) (PullRequest, error) {
+	out, err := g.run(ctx, "mr", "view", ref, "--output", "json")
+	if err != nil {
+		return PullRequest{}, err
+	}
+	return parseGitLabMR(out)
+}
+
+func (g *gitLab) OpenPR(ctx context.Context, ref string) error {
+	_, err := g.run(ctx, "mr", "view", ref, "--web")
+	return err
+}
+
+func (g *gitLab) RetargetPRs(ctx context.Context, head, from, to string) error {
+	out, err := g.run(ctx, "mr", "list", "--source-branch", head, "--target-branch", from, "--output", "json")
+	if err != nil {
+		return err
+	}
+	var mrs []gitLabMR
+	if err := json.Unmarshal(out, &mrs); err != nil {
+		return err
+	}
+	for _, mr := range mrs {
+		if _, err := g.run(ctx, "mr", "update", strconv.Itoa(mr.IID), "--target-branch", to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gitLab) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "glab", args...)
+	cmd.Dir = g.repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("glab %s %s: %w: %s", args[0], args[1], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+type gitLabMR struct {
+	IID                 int    `json:"iid"`
+	WebURL              string `json:"web_url"`
+	Title               string `json:"title"`
+	State               string `json:"state"`
+	Draft               bool   `json:"draft"`
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	SourceBranch        string `json:"source_branch"`
+	TargetBranch        string `json:"target_branch"`
+	SourceProjectID     int    `json:"source_project_id"`
+	TargetProjectID     int    `json:"target_project_id"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// parseGitLabMR reads `glab mr view --output json`.
+func parseGitLabMR(data []byte) (PullRequest, error) {
+	var mr gitLabMR
+	if err := json.Unmarshal(data, &mr); err != nil {
+		return PullRequest{}, err
+	}
+
+	pr := PullRequest{
+		Number:          mr.IID,
+		URL:             mr.WebURL,
+		Title:           mr.Title,
+		HeadBranch:      mr.SourceBranch,
+		BaseBranch:      mr.TargetBranch,
+		CrossRepository: mr.SourceProjectID != mr.TargetProjectID,
+		FetchRef:        fmt.Sprintf("refs/merge-requests/%d/head", mr.IID),
+	}
+	switch {
+	case mr.State == "opened" && mr.Draft:
+		pr.State = PRStateDraft
+	case mr.State == "opened":
+		pr.State = PRStateOpen
+	case mr.State == "merged":
+		pr.State = PRStateMerged
+	default:
+		pr.State = PRStateClosed
+	}
+	if mr.HeadPipeline != nil {
+		pr.Checks = summarizeChecks([]string{mr.HeadPipeline.Status})
+	}
+	switch mr.DetailedMergeStatus {
+	case "not_approved":
+		pr.ReviewDecision = "review_required"
+	case "requested_changes":
+		pr.ReviewDecision = "changes_requested"
+	}
+	return pr, nil
+}
+
+// parseGitLabMRs reads `glab mr list --output json`. The branch counts as
+// merged if any of its merge requests was.
+func parseGitLabMRs(data []byte) (merged bool, found bool, err error) {
+	var mrs []gitLabMR
+	if err := json.Unmarshal(data, &mrs); err != nil {
+		return false, false, err
+	}
+	for _, mr := range mrs {
+		if mr.State == "merged" {
+			return true, true, nil
+		}
+	}
+	return false, len(mrs) > 0, nil
+}
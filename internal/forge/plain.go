@@ -0,0 +1,41 @@
+package forge
+
+import (
+	"context"
+
+	"github.com/ccw/ccw/internal/deps"
+)
+
+// plain is a git server with no review tooling we can query (Bitbucket,
+// bare SSH hosts, local remotes). Merge detection is left entirely to git.
+type plain struct {
+	host string
+}
+
+func (p *plain) Kind() string { return KindPlain }
+func (p *plain) Host() string { return p.host }
+
+func (p *plain) Dependency() (deps.Dependency, bool) { return deps.Dependency{}, false }
+
+func (p *plain) CheckAuth(ctx context.Context) error { return nil }
+
+func (p *plain) MergeStatus(ctx context.Context, branch string) (bool, bool, error) {
+	return false, false, nil
+}
+
+func (p *plain) CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error) {
+	return PullRequest{}, ErrPullRequestsUnsupported
+}
+
+func (p *plain) ViewPR(ctx context.Context, ref string) (PullRequest, error) {
+	return PullRequest{}, ErrPullRequestsUnsupported
+}
+
+func (p *plain) OpenPR(ctx context.Context, ref string) error {
+	return ErrPullRequestsUnsupported
+}
+
+// RetargetPRs has nothing to do: there are no PRs to close.
+func (p *plain) RetargetPRs(ctx context.Context, head, from, to string) error {
+	return nil
+}
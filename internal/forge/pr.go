@@ -0,0 +1,72 @@
+package forge
+
+import (
+	"errors"
+	"strings"
+)
+
+// Normalised PR/MR states.
+const (
+	PRStateOpen   = "open"
+	PRStateDraft  = "draft"
+	PRStateMerged = "merged"
+	PRStateClosed = "closed"
+)
+
+// Normalised check summaries.
+const (
+	ChecksPassing = "passing"
+	ChecksFailing = "failing"
+	ChecksPending = "pending"
+)
+
+// ErrPullRequestsUnsupported is returned by forges ccw can't open or query
+// pull requests on.
+var ErrPullRequestsUnsupported = errors.New("pull requests are not supported for this remote")
+
+// PullRequest is a pull/merge request in forge-neutral terms. Checks and
+// ReviewDecision are empty when the forge doesn't report them.
+type PullRequest struct {
+	Number         int    `json:"number"`
+	URL            string `json:"url"`
+	Title          string `json:"title,omitempty"`
+	State          string `json:"state"`
+	Checks         string `json:"checks,omitempty"`
+	ReviewDecision string `json:"review_decision,omitempty"`
+	HeadBranch     string `json:"head_branch,omitempty"`
+	BaseBranch     string `json:"base_branch,omitempty"`
+	// CrossRepository is true when the head branch lives in a fork rather
+	// than on origin.
+	CrossRepository bool `json:"cross_repository,omitempty"`
+	// FetchRef is the ref on origin that holds the PR head, which works for
+	// fork PRs too (e.g. refs/pull/12/head).
+	FetchRef string `json:"-"`
+}
+
+// CreatePROptions describes a pull request to open from Head into Base.
+type CreatePROptions struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Draft bool
+}
+
+// summarizeChecks folds individual check states into one summary: any
+// failure wins, then anything still running.
+func summarizeChecks(states []string) string {
+	if len(states) == 0 {
+		return ""
+	}
+	summary := ChecksPassing
+	for _, s := range states {
+		switch strings.ToLower(s) {
+		case "failure", "failed", "error", "cancelled", "canceled", "timed_out", "action_required":
+			return ChecksFailing
+		case "success", "passed", "neutral", "skipped", "manual":
+		default:
+			summary = ChecksPending
+		}
+	}
+	return summary
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,7 +17,11 @@ var (
 )
 
 func BranchExists(repoPath, branch string) (bool, error) {
-	_, err := runGit(context.Background(), repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return branchExists(context.Background(), repoPath, branch)
+}
+
+func branchExists(ctx context.Context, repoPath, branch string) (bool, error) {
+	_, err := runGit(ctx, repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
 	if err == nil {
 		return true, nil
 	}
@@ -40,13 +45,21 @@ func RemoteBranchExists(repoPath, remote, branch string) (bool, error) {
 	return strings.TrimSpace(out) != "", nil
 }
 
+// RemoteTrackingBranchExists reports whether origin/<branch> is known
+// locally. Unlike RemoteBranchExists it doesn't touch the network, so it
+// reflects the last fetch.
+func RemoteTrackingBranchExists(ctx context.Context, repoPath, branch string) bool {
+	_, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch)
+	return err == nil
+}
+
 // branchOrRemoteExists checks if a branch exists locally or on origin.
-func branchOrRemoteExists(repoPath, branch string) bool {
-	if exists, _ := BranchExists(repoPath, branch); exists {
+func branchOrRemoteExists(ctx context.Context, repoPath, branch string) bool {
+	if exists, _ := branchExists(ctx, repoPath, branch); exists {
 		return true
 	}
 	// Check remote
-	if _, err := runGit(context.Background(), repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch); err == nil {
+	if _, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch); err == nil {
 		return true
 	}
 	return false
@@ -55,17 +68,17 @@ func branchOrRemoteExists(repoPath, branch string) bool {
 // DetectDefaultBranch auto-detects the default branch for a repo.
 // It first checks origin/HEAD (set by git clone), then falls back to
 // heuristic main/master detection.
-func DetectDefaultBranch(repoPath string) (string, error) {
+func DetectDefaultBranch(ctx context.Context, repoPath string) (string, error) {
 	// Primary: ask the remote what its default branch is via origin/HEAD.
-	if ref, err := runGit(context.Background(), repoPath, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); err == nil {
+	if ref, err := runGit(ctx, repoPath, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); err == nil {
 		if name := strings.TrimPrefix(ref, "refs/remotes/origin/"); name != ref {
 			return name, nil
 		}
 	}
 
 	// Fallback: heuristic based on branch existence.
-	mainExists := branchOrRemoteExists(repoPath, "main")
-	masterExists := branchOrRemoteExists(repoPath, "master")
+	mainExists := branchOrRemoteExists(ctx, repoPath, "main")
+	masterExists := branchOrRemoteExists(ctx, repoPath, "master")
 
 	if mainExists && masterExists {
 		return "", errors.New("both 'main' and 'master' branches exist; specify --base explicitly")
@@ -132,16 +145,16 @@ func resolveBaseName(baseBranch, repoPath string) string {
 	if baseBranch != "" {
 		return baseBranch
 	}
-	detected, err := DetectDefaultBranch(repoPath)
+	detected, err := DetectDefaultBranch(context.Background(), repoPath)
 	if err != nil {
 		return ""
 	}
 	return detected
 }
 
-func resolveBaseRef(repoPath, baseBranch string) (string, error) {
+func resolveBaseRef(ctx context.Context, repoPath, baseBranch string) (string, error) {
 	if baseBranch == "" {
-		detected, err := DetectDefaultBranch(repoPath)
+		detected, err := DetectDefaultBranch(ctx, repoPath)
 		if err != nil {
 			return "", err
 		}
@@ -149,11 +162,11 @@ func resolveBaseRef(repoPath, baseBranch string) (string, error) {
 	}
 
 	// Prefer remote base branch.
-	if _, err := runGit(context.Background(), repoPath, "rev-parse", "--verify", "--quiet", "origin/"+baseBranch); err == nil {
+	if _, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", "origin/"+baseBranch); err == nil {
 		return "origin/" + baseBranch, nil
 	}
 
-	if _, err := runGit(context.Background(), repoPath, "rev-parse", "--verify", "--quiet", baseBranch); err == nil {
+	if _, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", baseBranch); err == nil {
 		return baseBranch, nil
 	}
 
@@ -167,6 +180,39 @@ func CreateBranch(repoPath, branch, baseBranch string, fetch bool) error {
 		}
 	}
 
+	if err := checkNewBranch(repoPath, branch); err != nil {
+		return err
+	}
+
+	baseRef, err := resolveBaseRef(context.Background(), repoPath, baseBranch)
+	if err != nil {
+		return err
+	}
+
+	// Best-effort: fast-forward local base branch to match remote.
+	if name := resolveBaseName(baseBranch, repoPath); name != "" {
+		_ = SyncLocalBranch(repoPath, name)
+	}
+
+	if _, err := runGit(context.Background(), repoPath, "branch", branch, baseRef); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateBranchAt creates branch pointing at ref exactly, without the base
+// branch resolution CreateBranch does. Used to stack a branch on a local
+// branch that may be ahead of origin.
+func CreateBranchAt(repoPath, branch, ref string) error {
+	if err := checkNewBranch(repoPath, branch); err != nil {
+		return err
+	}
+	_, err := runGit(context.Background(), repoPath, "branch", branch, ref)
+	return err
+}
+
+func checkNewBranch(repoPath, branch string) error {
 	if exists, err := BranchExists(repoPath, branch); err != nil {
 		return err
 	} else if exists {
@@ -178,22 +224,62 @@ func CreateBranch(repoPath, branch, baseBranch string, fetch bool) error {
 	} else if exists {
 		return ErrRemoteBranchFound
 	}
+	return nil
+}
 
-	baseRef, err := resolveBaseRef(repoPath, baseBranch)
+// CheckoutExistingBranch makes a branch that already exists elsewhere
+// available locally so a worktree can be created on it.
+//
+// With ref empty the branch comes from origin: an existing local branch is
+// reused, otherwise a local branch tracking origin/<branch> is created. With
+// ref set (e.g. "refs/pull/12/head" for a fork PR), that ref is fetched from
+// origin into the local branch, replacing what it held before unless a
+// worktree has it checked out. created reports whether a local branch was
+// created, so callers know whether to delete it on rollback.
+func CheckoutExistingBranch(repoPath, branch, ref string, fetch bool) (created bool, err error) {
+	ctx := context.Background()
+	localExists, err := BranchExists(repoPath, branch)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Best-effort: fast-forward local base branch to match remote.
-	if name := resolveBaseName(baseBranch, repoPath); name != "" {
-		_ = SyncLocalBranch(repoPath, name)
+	if ref != "" {
+		if localExists {
+			worktrees, err := ListWorktrees(repoPath)
+			if err != nil {
+				return false, err
+			}
+			for _, wt := range worktrees {
+				if wt.Branch == branch {
+					return false, fmt.Errorf("branch %s is checked out in %s", branch, wt.Path)
+				}
+			}
+		}
+		// The PR's author may have force-pushed since the last fetch.
+		if _, err := runGit(ctx, repoPath, "fetch", "origin", "+"+ref+":refs/heads/"+branch); err != nil {
+			return false, fmt.Errorf("fetch %s: %w", ref, err)
+		}
+		return !localExists, nil
 	}
 
-	if _, err := runGit(context.Background(), repoPath, "branch", branch, baseRef); err != nil {
-		return err
+	if fetch {
+		refspec := "+refs/heads/" + branch + ":refs/remotes/origin/" + branch
+		if _, err := runGit(ctx, repoPath, "fetch", "origin", refspec); err != nil && !localExists {
+			return false, fmt.Errorf("%w: %s not found on origin", ErrBranchNotFound, branch)
+		}
 	}
 
-	return nil
+	if localExists {
+		return false, nil
+	}
+
+	if _, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch); err != nil {
+		return false, fmt.Errorf("%w: %s not found locally or on origin", ErrBranchNotFound, branch)
+	}
+	if _, err := runGit(ctx, repoPath, "branch", "--track", branch, "origin/"+branch); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func PushBranch(repoPath, branch string) error {
@@ -256,7 +342,7 @@ func IsMergedWithPR(ctx context.Context, repoPath, branch, baseBranch string, fe
 		// Fall through to git-based detection on error or not found
 	}
 
-	baseRef, err := resolveBaseRef(repoPath, baseBranch)
+	baseRef, err := resolveBaseRef(ctx, repoPath, baseBranch)
 	if err != nil {
 		return false, err
 	}
@@ -302,6 +388,39 @@ func IsMerged(repoPath, branch, baseBranch string, fetch bool) (bool, error) {
 	return IsMergedWithPR(context.Background(), repoPath, branch, baseBranch, fetch, nil)
 }
 
+// CommitSubjects returns the subjects of the commits on branch that are not
+// in baseBranch, oldest first.
+func CommitSubjects(repoPath, branch, baseBranch string) ([]string, error) {
+	baseRef, err := resolveBaseRef(context.Background(), repoPath, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(context.Background(), repoPath, "log", "--reverse", "--format=%s", baseRef+".."+branch)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// CommitsNotInRemoteRef fetches ref from origin and counts the commits on
+// branch that it doesn't have.
+func CommitsNotInRemoteRef(ctx context.Context, repoPath, branch, ref string) (int, error) {
+	if _, err := runGit(ctx, repoPath, "fetch", "origin", ref); err != nil {
+		return 0, fmt.Errorf("fetch %s: %w", ref, err)
+	}
+	out, err := runGit(ctx, repoPath, "rev-list", "--count", branch, "--not", "FETCH_HEAD")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
+}
+
 func HasUnpushedCommits(repoPath, branch string) (bool, error) {
 	if _, err := runGit(context.Background(), repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch); err != nil {
 		// If remote branch is missing, treat all commits as unpushed.
@@ -337,7 +456,7 @@ func RemoteBranchHasUnmergedCommitsWithPR(ctx context.Context, repoPath, branch,
 		// Fall through to git-based detection on error or not found
 	}
 
-	baseRef, err := resolveBaseRef(repoPath, baseBranch)
+	baseRef, err := resolveBaseRef(ctx, repoPath, baseBranch)
 	if err != nil {
 		return false, err
 	}
@@ -390,7 +509,7 @@ func RemoteBranchHasUnmergedCommits(repoPath, branch, baseBranch string) (bool,
 // GetDiffFiles returns the list of files that differ between a branch and base.
 // Returns nil if there are no differences.
 func GetDiffFiles(repoPath, branch, baseBranch string) ([]string, error) {
-	baseRef, err := resolveBaseRef(repoPath, baseBranch)
+	baseRef, err := resolveBaseRef(context.Background(), repoPath, baseBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,13 @@ import (
 )
 
 func runGit(ctx context.Context, repoPath string, args ...string) (string, error) {
+	out, err := runGitRaw(ctx, repoPath, args...)
+	return strings.TrimSpace(out), err
+}
+
+// runGitRaw is runGit without trimming, for output where leading spaces
+// matter, such as `git status --porcelain`.
+func runGitRaw(ctx context.Context, repoPath string, args ...string) (string, error) {
 	fullArgs := append([]string{"-C", repoPath}, args...)
 	cmd := exec.CommandContext(ctx, "git", fullArgs...)
 
@@ -22,7 +29,7 @@ func runGit(ctx context.Context, repoPath string, args ...string) (string, error
 		return "", fmt.Errorf("git %s: %w (stderr: %s)", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	return stdout.String(), nil
 }
 
 func exitCode(err error) (int, bool) {
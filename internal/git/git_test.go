@@ -2,8 +2,10 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -267,7 +269,7 @@ func TestRemoveWorktreeMissingIsOk(t *testing.T) {
 
 func TestDetectDefaultBranchMain(t *testing.T) {
 	repo := initRepo(t) // initRepo creates a repo with main branch
-	branch, err := DetectDefaultBranch(repo)
+	branch, err := DetectDefaultBranch(context.Background(), repo)
 	if err != nil {
 		t.Fatalf("DetectDefaultBranch: %v", err)
 	}
@@ -295,7 +297,7 @@ func TestDetectDefaultBranchMaster(t *testing.T) {
 		t.Fatalf("git commit: %v", err)
 	}
 
-	branch, err := DetectDefaultBranch(dir)
+	branch, err := DetectDefaultBranch(context.Background(), dir)
 	if err != nil {
 		t.Fatalf("DetectDefaultBranch: %v", err)
 	}
@@ -323,7 +325,7 @@ func TestDetectDefaultBranchNeitherError(t *testing.T) {
 		t.Fatalf("git commit: %v", err)
 	}
 
-	_, err := DetectDefaultBranch(dir)
+	_, err := DetectDefaultBranch(context.Background(), dir)
 	if err == nil {
 		t.Fatal("expected error when neither main nor master exists")
 	}
@@ -646,7 +648,7 @@ func TestDetectDefaultBranch_OriginHEAD(t *testing.T) {
 		t.Fatalf("set-head: %v", err)
 	}
 
-	branch, err := DetectDefaultBranch(localRepo)
+	branch, err := DetectDefaultBranch(context.Background(), localRepo)
 	if err != nil {
 		t.Fatalf("DetectDefaultBranch: %v", err)
 	}
@@ -658,7 +660,7 @@ func TestDetectDefaultBranch_OriginHEAD(t *testing.T) {
 func TestDetectDefaultBranch_FallbackMain(t *testing.T) {
 	// initRepo creates a local-only repo with main — no origin/HEAD.
 	repo := initRepo(t)
-	branch, err := DetectDefaultBranch(repo)
+	branch, err := DetectDefaultBranch(context.Background(), repo)
 	if err != nil {
 		t.Fatalf("DetectDefaultBranch: %v", err)
 	}
@@ -685,7 +687,7 @@ func TestDetectDefaultBranch_FallbackMaster(t *testing.T) {
 		t.Fatalf("git commit: %v", err)
 	}
 
-	branch, err := DetectDefaultBranch(dir)
+	branch, err := DetectDefaultBranch(context.Background(), dir)
 	if err != nil {
 		t.Fatalf("DetectDefaultBranch: %v", err)
 	}
@@ -812,3 +814,309 @@ func TestSyncLocalBranch_NoLocalBranch(t *testing.T) {
 		t.Fatalf("SyncLocalBranch: %v", err)
 	}
 }
+
+func TestCommitSubjects(t *testing.T) {
+	repo := initRepo(t)
+	if err := CreateBranch(repo, "feature/test", "main", false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if _, err := runGit(context.Background(), repo, "checkout", "feature/test"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	for _, msg := range []string{"first change", "second change"} {
+		if _, err := runGit(context.Background(), repo, "commit", "--allow-empty", "-m", msg); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+
+	subjects, err := CommitSubjects(repo, "feature/test", "main")
+	if err != nil {
+		t.Fatalf("CommitSubjects: %v", err)
+	}
+	if len(subjects) != 2 || subjects[0] != "first change" || subjects[1] != "second change" {
+		t.Fatalf("unexpected subjects: %v", subjects)
+	}
+}
+
+func TestCheckoutExistingBranchFromOrigin(t *testing.T) {
+	repo, _ := initRepoWithRemote(t)
+	ctx := context.Background()
+
+	// Simulate a colleague's branch: it exists on origin but not locally.
+	for _, args := range [][]string{
+		{"checkout", "-b", "colleague/fix"},
+		{"commit", "--allow-empty", "-m", "their fix"},
+		{"push", "origin", "colleague/fix"},
+		{"checkout", "main"},
+		{"branch", "-D", "colleague/fix"},
+	} {
+		if _, err := runGit(ctx, repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	created, err := CheckoutExistingBranch(repo, "colleague/fix", "", true)
+	if err != nil {
+		t.Fatalf("CheckoutExistingBranch: %v", err)
+	}
+	if !created {
+		t.Fatal("expected a local branch to be created")
+	}
+	upstream, err := runGit(ctx, repo, "rev-parse", "--abbrev-ref", "colleague/fix@{upstream}")
+	if err != nil || strings.TrimSpace(upstream) != "origin/colleague/fix" {
+		t.Fatalf("expected branch to track origin, got %q (%v)", upstream, err)
+	}
+
+	created, err = CheckoutExistingBranch(repo, "colleague/fix", "", true)
+	if err != nil || created {
+		t.Fatalf("expected existing local branch to be reused, created=%v err=%v", created, err)
+	}
+}
+
+func TestCheckoutExistingBranchFromRef(t *testing.T) {
+	repo, _ := initRepoWithRemote(t)
+	ctx := context.Background()
+
+	if _, err := runGit(ctx, repo, "push", "origin", "HEAD:refs/pull/7/head"); err != nil {
+		t.Fatalf("push pull ref: %v", err)
+	}
+
+	created, err := CheckoutExistingBranch(repo, "pr/7", "refs/pull/7/head", true)
+	if err != nil || !created {
+		t.Fatalf("CheckoutExistingBranch: created=%v err=%v", created, err)
+	}
+	if exists, _ := BranchExists(repo, "pr/7"); !exists {
+		t.Fatal("expected pr/7 to exist locally")
+	}
+}
+
+func TestCheckoutExistingBranchMissing(t *testing.T) {
+	repo, _ := initRepoWithRemote(t)
+
+	_, err := CheckoutExistingBranch(repo, "does-not-exist", "", true)
+	if !errors.Is(err, ErrBranchNotFound) {
+		t.Fatalf("expected ErrBranchNotFound, got %v", err)
+	}
+}
+
+func TestListWorktrees(t *testing.T) {
+	repo := initRepo(t)
+	if err := CreateBranch(repo, "feature/test", "main", false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	worktreePath := filepath.Join(t.TempDir(), "wt")
+	if err := CreateWorktree(repo, worktreePath, "feature/test"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+
+	worktrees, err := ListWorktrees(repo)
+	if err != nil {
+		t.Fatalf("ListWorktrees: %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("expected 2 worktrees, got %+v", worktrees)
+	}
+	if worktrees[0].Branch != "main" {
+		t.Fatalf("expected main checkout first, got %+v", worktrees[0])
+	}
+	if worktrees[1].Branch != "feature/test" || resolvePath(worktrees[1].Path) != resolvePath(worktreePath) {
+		t.Fatalf("unexpected worktree: %+v", worktrees[1])
+	}
+}
+
+func TestParseWorktreesDetached(t *testing.T) {
+	out := "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\nworktree /wt\nHEAD def\ndetached\n"
+	worktrees := parseWorktrees(out)
+	if len(worktrees) != 2 || !worktrees[1].Detached || worktrees[1].Branch != "" {
+		t.Fatalf("unexpected worktrees: %+v", worktrees)
+	}
+}
+
+func TestWorktreeOwnerAndPrune(t *testing.T) {
+	repo := initRepo(t)
+	if err := CreateBranch(repo, "feature/test", "main", false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	worktreePath := filepath.Join(t.TempDir(), "wt")
+	if err := CreateWorktree(repo, worktreePath, "feature/test"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+
+	owner, branch, err := WorktreeOwner(worktreePath)
+	if err != nil {
+		t.Fatalf("WorktreeOwner: %v", err)
+	}
+	if resolvePath(owner) != resolvePath(repo) || branch != "feature/test" {
+		t.Fatalf("unexpected owner %q, branch %q", owner, branch)
+	}
+
+	if err := os.RemoveAll(worktreePath); err != nil {
+		t.Fatalf("remove worktree: %v", err)
+	}
+	worktrees, err := ListWorktrees(repo)
+	if err != nil {
+		t.Fatalf("ListWorktrees: %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[1].Prunable {
+		t.Fatalf("expected deleted worktree to be prunable, got %+v", worktrees)
+	}
+
+	if err := PruneWorktrees(repo); err != nil {
+		t.Fatalf("PruneWorktrees: %v", err)
+	}
+	worktrees, err = ListWorktrees(repo)
+	if err != nil {
+		t.Fatalf("ListWorktrees: %v", err)
+	}
+	if len(worktrees) != 1 {
+		t.Fatalf("expected only the main checkout after pruning, got %+v", worktrees)
+	}
+}
+
+func TestStatusKeepsLeadingStatusColumn(t *testing.T) {
+	repo := initRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := runGit(context.Background(), repo, "add", "tracked.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := runGit(context.Background(), repo, "commit", "-m", "add"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("b"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "new", "dir"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new", "dir", "file.txt"), []byte("c"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	entries, err := Status(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Code != " M" || entries[0].Path != "tracked.txt" {
+		t.Fatalf("unexpected modified entry: %+v", entries[0])
+	}
+	if entries[1].Path != "new/dir/file.txt" || !entries[1].Untracked() {
+		t.Fatalf("unexpected untracked entry: %+v", entries[1])
+	}
+}
+
+func TestAheadBehindAndLastCommit(t *testing.T) {
+	repo := initRepo(t)
+	ctx := context.Background()
+	if err := CreateBranch(repo, "feature", "main", false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	for _, args := range [][]string{
+		{"commit", "--allow-empty", "-m", "on main"},
+		{"checkout", "feature"},
+		{"commit", "--allow-empty", "-m", "first"},
+		{"commit", "--allow-empty", "-m", "second feature commit"},
+	} {
+		if _, err := runGit(ctx, repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	ahead, behind, err := AheadBehind(ctx, repo, "feature", "main")
+	if err != nil {
+		t.Fatalf("AheadBehind: %v", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Fatalf("expected 2 ahead / 1 behind, got %d / %d", ahead, behind)
+	}
+
+	commit, err := LastCommit(ctx, repo, "feature")
+	if err != nil {
+		t.Fatalf("LastCommit: %v", err)
+	}
+	if commit.Subject != "second feature commit" || commit.Hash == "" || commit.Time.IsZero() {
+		t.Fatalf("unexpected commit: %+v", commit)
+	}
+
+	if _, _, err := AheadBehind(ctx, repo, "feature", "origin/feature"); err == nil {
+		t.Fatalf("expected error for missing ref")
+	}
+}
+
+func TestSyncWorktree(t *testing.T) {
+	ctx := context.Background()
+	commitFile := func(repo, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if _, err := runGit(ctx, repo, "add", name); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+		if _, err := runGit(ctx, repo, "commit", "-m", "edit "+name); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+
+	for _, strategy := range []string{SyncRebase, SyncMerge} {
+		t.Run(strategy, func(t *testing.T) {
+			repo := initRepo(t)
+			commitFile(repo, "shared.txt", "base\n")
+			if _, err := runGit(ctx, repo, "checkout", "-b", "feature"); err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+			commitFile(repo, "feature.txt", "feature\n")
+
+			updated, err := SyncWorktree(ctx, repo, "main", strategy)
+			if err != nil || updated {
+				t.Fatalf("expected nothing to do, got updated=%v err=%v", updated, err)
+			}
+
+			if _, err := runGit(ctx, repo, "checkout", "main"); err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+			commitFile(repo, "main.txt", "main\n")
+			if _, err := runGit(ctx, repo, "checkout", "feature"); err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+
+			updated, err = SyncWorktree(ctx, repo, "main", strategy)
+			if err != nil || !updated {
+				t.Fatalf("expected update, got updated=%v err=%v", updated, err)
+			}
+			if _, behind, _ := AheadBehind(ctx, repo, "feature", "main"); behind != 0 {
+				t.Fatalf("expected feature to contain main, still %d behind", behind)
+			}
+
+			if _, err := runGit(ctx, repo, "checkout", "main"); err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+			commitFile(repo, "shared.txt", "main side\n")
+			if _, err := runGit(ctx, repo, "checkout", "feature"); err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+			commitFile(repo, "shared.txt", "feature side\n")
+			before, _ := runGit(ctx, repo, "rev-parse", "HEAD")
+
+			_, err = SyncWorktree(ctx, repo, "main", strategy)
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("expected a conflict, got %v", err)
+			}
+			if len(conflict.Files) != 1 || conflict.Files[0] != "shared.txt" {
+				t.Fatalf("unexpected conflict files: %v", conflict.Files)
+			}
+			after, _ := runGit(ctx, repo, "rev-parse", "HEAD")
+			if before != after {
+				t.Fatalf("expected the %s to be aborted, HEAD moved from %s to %s", strategy, before, after)
+			}
+			if entries, _ := Status(ctx, repo); len(entries) != 0 {
+				t.Fatalf("expected a clean worktree after abort, got %+v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a commit's identity as shown in status summaries.
+type Commit struct {
+	Hash    string
+	Subject string
+	Time    time.Time
+}
+
+// BaseRef returns the ref a branch's base is compared against: origin/<base>
+// when it exists, otherwise the local base branch. An empty baseBranch is
+// auto-detected.
+func BaseRef(ctx context.Context, repoPath, baseBranch string) (string, error) {
+	return resolveBaseRef(ctx, repoPath, baseBranch)
+}
+
+// ResolveRef returns the commit hash ref points at.
+func ResolveRef(ctx context.Context, repoPath, ref string) (string, error) {
+	return runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
+
+// AheadBehind counts the commits reachable from ref but not other (ahead)
+// and from other but not ref (behind).
+func AheadBehind(ctx context.Context, repoPath, ref, other string) (ahead, behind int, err error) {
+	out, err := runGit(ctx, repoPath, "rev-list", "--left-right", "--count", ref+"..."+other)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseAheadBehind(out)
+}
+
+func parseAheadBehind(out string) (int, int, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	return ahead, behind, nil
+}
+
+// LastCommit returns the commit ref points at.
+func LastCommit(ctx context.Context, repoPath, ref string) (Commit, error) {
+	out, err := runGit(ctx, repoPath, "log", "-1", "--format=%H%x00%ct%x00%s", ref)
+	if err != nil {
+		return Commit{}, err
+	}
+	parts := strings.SplitN(out, "\x00", 3)
+	if len(parts) != 3 {
+		return Commit{}, fmt.Errorf("unexpected git log output %q", out)
+	}
+	secs, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Commit{}, fmt.Errorf("parse commit time %q: %w", parts[1], err)
+	}
+	return Commit{Hash: parts[0], Subject: parts[2], Time: time.Unix(secs, 0).UTC()}, nil
+}
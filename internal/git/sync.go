@@ -0,0 +1,90 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Strategies for SyncWorktree.
+const (
+	SyncRebase = "rebase"
+	SyncMerge  = "merge"
+)
+
+// ConflictError is returned by SyncWorktree when the rebase or merge hit
+// conflicts. The operation has been aborted, so the worktree is as it was.
+type ConflictError struct {
+	Strategy string
+	Files    []string
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Files) == 0 {
+		return e.Strategy + " stopped with conflicts"
+	}
+	return fmt.Sprintf("%s conflicts in %s", e.Strategy, strings.Join(e.Files, ", "))
+}
+
+// SyncWorktree brings the branch checked out at worktreePath up to date
+// with baseRef by rebasing onto it or merging it in. It reports whether
+// anything changed; a branch that already contains baseRef is left alone.
+// The worktree should be clean.
+func SyncWorktree(ctx context.Context, worktreePath, baseRef, strategy string) (bool, error) {
+	return SyncWorktreeOnto(ctx, worktreePath, baseRef, "", strategy)
+}
+
+// SyncWorktreeOnto is SyncWorktree for a branch whose base was rewritten or
+// replaced, as happens in a stack when the parent branch is rebased or
+// squash-merged. oldBase is the commit the base used to be at; a rebase
+// then replays only the branch's own commits (those after its fork point
+// from oldBase) instead of the base's old commits too. An empty oldBase
+// behaves like SyncWorktree.
+func SyncWorktreeOnto(ctx context.Context, worktreePath, baseRef, oldBase, strategy string) (bool, error) {
+	if _, err := runGit(ctx, worktreePath, "merge-base", "--is-ancestor", baseRef, "HEAD"); err == nil {
+		return false, nil
+	} else if code, ok := exitCode(err); !ok || code != 1 {
+		return false, err
+	}
+
+	var args, abort []string
+	switch strategy {
+	case SyncRebase:
+		args = []string{"rebase", baseRef}
+		if oldBase != "" {
+			forkPoint, err := runGit(ctx, worktreePath, "merge-base", "HEAD", oldBase)
+			if err != nil {
+				return false, fmt.Errorf("find fork point from %s: %w", oldBase, err)
+			}
+			args = []string{"rebase", "--onto", baseRef, forkPoint}
+		}
+		abort = []string{"rebase", "--abort"}
+	case SyncMerge:
+		args = []string{"merge", "--no-edit", baseRef}
+		abort = []string{"merge", "--abort"}
+	default:
+		return false, fmt.Errorf("unknown sync strategy %q (want %s or %s)", strategy, SyncRebase, SyncMerge)
+	}
+
+	if _, err := runGit(ctx, worktreePath, args...); err != nil {
+		conflicts, _ := runGit(ctx, worktreePath, "diff", "--name-only", "--diff-filter=U")
+		if conflicts == "" {
+			return false, err
+		}
+		if _, abortErr := runGit(ctx, worktreePath, abort...); abortErr != nil {
+			return false, fmt.Errorf("%s conflicted and could not be aborted: %w", strategy, abortErr)
+		}
+		return false, &ConflictError{Strategy: strategy, Files: strings.Split(conflicts, "\n")}
+	}
+	return true, nil
+}
+
+// PushForceWithLease pushes branch to origin, overwriting it only if it
+// still points at expect, or doesn't exist when expect is empty. Needed
+// after a rebase rewrote the branch. The caller passes the commit it checked
+// the branch against, since origin/<branch> moves with every fetch.
+func PushForceWithLease(repoPath, branch, expect string) error {
+	lease := "--force-with-lease=" + branch + ":" + expect
+	_, err := runGit(context.Background(), repoPath, "push", lease, "-u", "origin", branch)
+	return err
+}
@@ -8,8 +8,63 @@ import (
 	"strings"
 )
 
-func WorktreeExists(repoPath, path string) (bool, error) {
+// Worktree is one entry of `git worktree list --porcelain`.
+type Worktree struct {
+	Path string
+	Head string
+	// Branch is the short branch name, empty when detached or bare.
+	Branch   string
+	Bare     bool
+	Detached bool
+	// Prunable is set when git knows the worktree's directory is gone.
+	Prunable bool
+}
+
+// ListWorktrees returns every worktree of the repo, the main checkout first.
+func ListWorktrees(repoPath string) ([]Worktree, error) {
 	out, err := runGit(context.Background(), repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(out), nil
+}
+
+func parseWorktrees(out string) []Worktree {
+	var worktrees []Worktree
+	var cur *Worktree
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			worktrees = append(worktrees, Worktree{Path: filepath.Clean(value)})
+			cur = &worktrees[len(worktrees)-1]
+		case "HEAD":
+			if cur != nil {
+				cur.Head = value
+			}
+		case "branch":
+			if cur != nil {
+				cur.Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		case "bare":
+			if cur != nil {
+				cur.Bare = true
+			}
+		case "detached":
+			if cur != nil {
+				cur.Detached = true
+			}
+		case "prunable":
+			if cur != nil {
+				cur.Prunable = true
+			}
+		}
+	}
+	return worktrees
+}
+
+func WorktreeExists(repoPath, path string) (bool, error) {
+	worktrees, err := ListWorktrees(repoPath)
 	if err != nil {
 		return false, err
 	}
@@ -18,26 +73,94 @@ func WorktreeExists(repoPath, path string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if resolved, err := filepath.EvalSymlinks(path); err == nil {
-		path = resolved
-	}
-	path = filepath.Clean(path)
-
-	entries := strings.Split(out, "\n")
-	for _, line := range entries {
-		if strings.HasPrefix(line, "worktree ") {
-			wtPath := filepath.Clean(strings.TrimPrefix(line, "worktree "))
-			if resolved, err := filepath.EvalSymlinks(wtPath); err == nil {
-				wtPath = resolved
-			}
-			if wtPath == path {
-				return true, nil
-			}
+	path = resolvePath(path)
+
+	for _, wt := range worktrees {
+		if resolvePath(wt.Path) == path {
+			return true, nil
 		}
 	}
 	return false, nil
 }
 
+// PruneWorktrees drops git's records of worktrees whose directories are gone.
+func PruneWorktrees(repoPath string) error {
+	_, err := runGit(context.Background(), repoPath, "worktree", "prune")
+	return err
+}
+
+// RepairWorktree reconnects a worktree directory and its repo after either
+// was moved.
+func RepairWorktree(repoPath, path string) error {
+	_, err := runGit(context.Background(), repoPath, "worktree", "repair", path)
+	return err
+}
+
+// WorktreeOwner reports the main repo a worktree directory belongs to and
+// the branch checked out in it (empty when detached).
+func WorktreeOwner(path string) (repoPath, branch string, err error) {
+	common, err := runGit(context.Background(), path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", "", err
+	}
+	repoPath = filepath.Dir(strings.TrimSpace(common))
+
+	head, err := runGit(context.Background(), path, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		if code, ok := exitCode(err); ok && code == 1 {
+			return repoPath, "", nil
+		}
+		return "", "", err
+	}
+	return repoPath, strings.TrimSpace(head), nil
+}
+
+// StatusEntry is one line of `git status --porcelain`.
+type StatusEntry struct {
+	// Code is the two-letter XY status, "??" for untracked files.
+	Code string
+	Path string
+}
+
+func (e StatusEntry) Untracked() bool {
+	return e.Code == "??"
+}
+
+// Status lists the uncommitted changes in the worktree at path, with
+// untracked directories expanded to their files.
+func Status(ctx context.Context, path string) ([]StatusEntry, error) {
+	out, err := runGitRaw(ctx, path, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(out), nil
+}
+
+func parseStatus(out string) []StatusEntry {
+	var entries []StatusEntry
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		p := line[3:]
+		// Renames are reported as "old -> new".
+		if _, dst, ok := strings.Cut(p, " -> "); ok {
+			p = dst
+		}
+		entries = append(entries, StatusEntry{Code: line[:2], Path: strings.Trim(p, `"`)})
+	}
+	return entries
+}
+
+// resolvePath cleans path and resolves symlinks where possible, so paths
+// reported by git compare equal to ones built by hand (e.g. /tmp on macOS).
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
 func CreateWorktree(repoPath, path, branch string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("create worktree parent dir: %w", err)
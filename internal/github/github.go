@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -62,8 +64,17 @@ var ErrNotAuthenticated = errors.New("gh CLI is not authenticated; run `gh auth
 
 // CheckAuthenticated verifies that gh CLI is authenticated for github.com.
 func CheckAuthenticated() error {
-	cmd := exec.Command("gh", "auth", "status", "--hostname", "github.com")
+	return CheckAuthenticatedHost("github.com")
+}
+
+// CheckAuthenticatedHost verifies that gh CLI is authenticated for host,
+// which may be a GitHub Enterprise server.
+func CheckAuthenticatedHost(host string) error {
+	cmd := exec.Command("gh", "auth", "status", "--hostname", host)
 	if err := cmd.Run(); err != nil {
+		if host != "github.com" {
+			return fmt.Errorf("gh CLI is not authenticated for %s; run `gh auth login --hostname %s`", host, host)
+		}
 		return ErrNotAuthenticated
 	}
 	return nil
@@ -106,3 +117,151 @@ func (c *Client) IsPRMerged(ctx context.Context, branch string) (merged bool, fo
 
 	return result.State == "MERGED", true, nil
 }
+
+// prListLimit bounds `gh pr list` in PRMergeStatuses. Branches whose PRs
+// are older than the newest prListLimit are missing from the result.
+const prListLimit = 1000
+
+// PRMergeStatuses lists the repo's most recent PRs in one call and maps
+// each head branch to whether its newest PR was merged, as `gh pr view
+// <branch>` would report. PRs from forks are ignored, since their branch
+// names say nothing about this repo's branches. Branches with no PR, or only
+// PRs older than the listing covers, are absent.
+func (c *Client) PRMergeStatuses(ctx context.Context) (map[string]bool, error) {
+	out, err := c.run(ctx, "pr", "list", "--state", "all", "--limit", strconv.Itoa(prListLimit), "--json", "headRefName,state,isCrossRepository")
+	if err != nil {
+		return nil, err
+	}
+	return parsePRMergeStatuses([]byte(out))
+}
+
+func parsePRMergeStatuses(data []byte) (map[string]bool, error) {
+	var prs []struct {
+		HeadRefName       string `json:"headRefName"`
+		State             string `json:"state"`
+		IsCrossRepository bool   `json:"isCrossRepository"`
+	}
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil, err
+	}
+	// gh lists the newest PRs first; a branch's newest PR decides.
+	statuses := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		if pr.IsCrossRepository {
+			continue
+		}
+		if _, seen := statuses[pr.HeadRefName]; !seen {
+			statuses[pr.HeadRefName] = pr.State == "MERGED"
+		}
+	}
+	return statuses, nil
+}
+
+// PullRequest is the subset of `gh pr view --json` output ccw uses.
+type PullRequest struct {
+	Number         int           `json:"number"`
+	URL            string        `json:"url"`
+	Title          string        `json:"title"`
+	State          string        `json:"state"`
+	IsDraft        bool          `json:"isDraft"`
+	ReviewDecision string        `json:"reviewDecision"`
+	Checks         []CheckResult `json:"statusCheckRollup"`
+	HeadRefName    string        `json:"headRefName"`
+	BaseRefName    string        `json:"baseRefName"`
+	IsCrossRepo    bool          `json:"isCrossRepository"`
+}
+
+// CheckResult is one entry of a PR's status check rollup. Check runs set
+// Status and Conclusion; commit statuses set State.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+const prViewFields = "number,url,title,state,isDraft,reviewDecision,statusCheckRollup,headRefName,baseRefName,isCrossRepository"
+
+// CreatePROptions configures CreatePR.
+type CreatePROptions struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Draft bool
+}
+
+// CreatePR opens a pull request and returns it as `gh pr view` reports it.
+func (c *Client) CreatePR(ctx context.Context, opts CreatePROptions) (PullRequest, error) {
+	args := []string{"pr", "create", "--head", opts.Head, "--title", opts.Title, "--body", opts.Body}
+	if opts.Base != "" {
+		args = append(args, "--base", opts.Base)
+	}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return PullRequest{}, err
+	}
+
+	// gh prints the new PR's URL last.
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return c.ViewPR(ctx, strings.TrimSpace(lines[len(lines)-1]))
+}
+
+// ViewPR looks up a pull request by number, URL or branch.
+func (c *Client) ViewPR(ctx context.Context, ref string) (PullRequest, error) {
+	out, err := c.run(ctx, "pr", "view", ref, "--json", prViewFields)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	var pr PullRequest
+	if err := json.Unmarshal([]byte(out), &pr); err != nil {
+		return PullRequest{}, err
+	}
+	return pr, nil
+}
+
+// RetargetPRs changes the base of head's open pull requests into from to
+// to. Having none is not an error.
+func (c *Client) RetargetPRs(ctx context.Context, head, from, to string) error {
+	out, err := c.run(ctx, "pr", "list", "--head", head, "--base", from, "--state", "open", "--json", "number")
+	if err != nil {
+		return err
+	}
+	var prs []struct {
+		Number int `json:"number"`
+	}
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if _, err := c.run(ctx, "pr", "edit", strconv.Itoa(pr.Number), "--base", to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenPR opens a pull request in the browser.
+func (c *Client) OpenPR(ctx context.Context, ref string) error {
+	_, err := c.run(ctx, "pr", "view", ref, "--web")
+	return err
+}
+
+func (c *Client) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Dir = c.repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("gh %s: %s", args[0]+" "+args[1], msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
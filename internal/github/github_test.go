@@ -153,3 +153,29 @@ func findGitRoot(dir string) string {
 		dir = parent
 	}
 }
+
+func TestParsePRMergeStatuses(t *testing.T) {
+	data := []byte(`[
+  {"headRefName": "feature/a", "state": "MERGED"},
+  {"headRefName": "feature/a", "state": "CLOSED"},
+  {"headRefName": "feature/b", "state": "OPEN"},
+  {"headRefName": "feature/b", "state": "MERGED"},
+  {"headRefName": "patch-1", "state": "MERGED", "isCrossRepository": true}
+]`)
+	statuses, err := parsePRMergeStatuses(data)
+	if err != nil {
+		t.Fatalf("parsePRMergeStatuses: %v", err)
+	}
+	if merged, ok := statuses["feature/a"]; !ok || !merged {
+		t.Fatalf("expected feature/a merged, got %v", statuses)
+	}
+	if merged, ok := statuses["feature/b"]; !ok || merged {
+		t.Fatalf("expected feature/b's open PR to win over an older merged one, got %v", statuses)
+	}
+	if _, ok := statuses["patch-1"]; ok {
+		t.Fatalf("expected fork PRs to be ignored, got %v", statuses)
+	}
+	if _, ok := statuses["feature/c"]; ok {
+		t.Fatalf("expected feature/c absent, got %v", statuses)
+	}
+}
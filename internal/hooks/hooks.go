@@ -0,0 +1,102 @@
+// Package hooks runs the per-repo lifecycle scripts configured in
+// RepoConfig.Hooks.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/ccw/ccw/internal/config"
+)
+
+// DefaultTimeout applies to hooks that do not set their own timeout.
+const DefaultTimeout = 5 * time.Minute
+
+// Lifecycle points, exported to hooks as CCW_HOOK.
+const (
+	PostCreate = "post-create"
+	PreOpen    = "pre-open"
+	PreRemove  = "pre-remove"
+	PostRemove = "post-remove"
+)
+
+// Env describes the workspace a hook runs for.
+type Env struct {
+	Hook         string
+	WorkspaceID  string
+	Repo         string
+	RepoPath     string
+	Branch       string
+	BaseBranch   string
+	WorktreePath string
+	TmuxSession  string
+}
+
+// Vars returns the environment variables exported to hook commands.
+func (e Env) Vars() []string {
+	return []string{
+		"CCW_HOOK=" + e.Hook,
+		"CCW_WORKSPACE_ID=" + e.WorkspaceID,
+		"CCW_REPO=" + e.Repo,
+		"CCW_REPO_PATH=" + e.RepoPath,
+		"CCW_BRANCH=" + e.Branch,
+		"CCW_BASE_BRANCH=" + e.BaseBranch,
+		"CCW_WORKTREE_PATH=" + e.WorktreePath,
+		"CCW_TMUX_SESSION=" + e.TmuxSession,
+	}
+}
+
+// Run executes hooks in order with sh -c in dir, streaming their output to
+// out. It stops at the first hook that fails or times out.
+func Run(ctx context.Context, hooks []config.Hook, dir string, env Env, out io.Writer) error {
+	if out == nil {
+		out = io.Discard
+	}
+
+	for _, hook := range hooks {
+		if hook.Run == "" {
+			continue
+		}
+		if err := runOne(ctx, hook, dir, env, out); err != nil {
+			return fmt.Errorf("%s hook %q: %w", env.Hook, hook.Run, err)
+		}
+	}
+	return nil
+}
+
+func runOne(ctx context.Context, hook config.Hook, dir string, env Env, out io.Writer) error {
+	timeout := DefaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env.Vars()...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Run the hook in its own process group so a timeout kills everything it
+	// started, not just sh.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Background children (e.g. a dev server forked by the hook) can hold the
+	// output pipe open after sh exits; don't wait on them forever.
+	cmd.WaitDelay = 5 * time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
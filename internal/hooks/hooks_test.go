@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/config"
+)
+
+func TestRunExportsWorkspaceEnv(t *testing.T) {
+	dir := t.TempDir()
+	env := Env{Hook: PostCreate, WorkspaceID: "demo/feature", Branch: "feature", WorktreePath: dir}
+
+	var out bytes.Buffer
+	hooks := []config.Hook{
+		{Run: `echo "$CCW_HOOK $CCW_WORKSPACE_ID" > env.txt`},
+		{Run: `echo hello from hook`},
+	}
+	if err := Run(context.Background(), hooks, dir, env, &out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if err != nil {
+		t.Fatalf("read env file: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "post-create demo/feature" {
+		t.Fatalf("unexpected hook env: %q", got)
+	}
+	if !strings.Contains(out.String(), "hello from hook") {
+		t.Fatalf("expected hook output to be streamed, got %q", out.String())
+	}
+}
+
+func TestRunStopsAtFirstFailure(t *testing.T) {
+	dir := t.TempDir()
+	hooks := []config.Hook{
+		{Run: "exit 3"},
+		{Run: "touch ran.txt"},
+	}
+
+	err := Run(context.Background(), hooks, dir, Env{Hook: PreRemove}, nil)
+	if err == nil || !strings.Contains(err.Error(), "pre-remove hook") {
+		t.Fatalf("expected pre-remove hook error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran.txt")); err == nil {
+		t.Fatalf("expected later hooks to be skipped")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	hooks := []config.Hook{{Run: "sleep 5", Timeout: 1}}
+
+	err := Run(context.Background(), hooks, t.TempDir(), Env{Hook: PreOpen}, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
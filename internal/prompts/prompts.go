@@ -0,0 +1,87 @@
+// Package prompts loads and renders the initial prompts given to agents by
+// `ccw new`: inline text, a prompt file, or a named template from
+// ~/.ccw/prompts.
+package prompts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// DirName is the templates directory inside the ccw root.
+const DirName = "prompts"
+
+// extensions are tried, in order, when a template is named without one.
+var extensions = []string{"", ".md", ".txt"}
+
+// Dir returns the templates directory for the ccw root.
+func Dir(root string) string {
+	return filepath.Join(root, DirName)
+}
+
+// Load reads the template name from dir. The .md or .txt extension may be
+// left off.
+func Load(dir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid prompt template name %q", name)
+	}
+	for _, ext := range extensions {
+		data, err := os.ReadFile(filepath.Join(dir, name+ext))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read prompt template %s: %w", name, err)
+		}
+	}
+	return "", fmt.Errorf("prompt template %q not found in %s", name, dir)
+}
+
+// List returns the names of the templates in dir, without extensions. A
+// missing dir has none.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		name := e.Name()
+		if ext := filepath.Ext(name); ext == ".md" || ext == ".txt" {
+			name = strings.TrimSuffix(name, ext)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Render expands text as a Go template. vars holds the values it can refer
+// to, e.g. {{.Branch}}; referring to a missing one is an error.
+func Render(text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,70 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadAndList(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"fix-issue.md": "Fix issue #{{.Issue}} on {{.Branch}}\n",
+		"review.txt":   "Review the diff",
+		"plain":        "Plain",
+		".hidden":      "skipped",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := []string{"fix-issue", "plain", "review"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("List = %v, want %v", names, want)
+	}
+
+	text, err := Load(dir, "fix-issue")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !strings.HasPrefix(text, "Fix issue") {
+		t.Fatalf("Load = %q", text)
+	}
+	if _, err := Load(dir, "review.txt"); err != nil {
+		t.Fatalf("Load with extension: %v", err)
+	}
+	if _, err := Load(dir, "missing"); err == nil {
+		t.Fatalf("expected missing template error")
+	}
+	if _, err := Load(dir, "../plain"); err == nil {
+		t.Fatalf("expected path in template name to be rejected")
+	}
+
+	if names, err := List(filepath.Join(dir, "nope")); err != nil || names != nil {
+		t.Fatalf("List of missing dir = %v, %v", names, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	got, err := Render("Fix issue #{{.Issue}} on {{.Branch}}\n", map[string]string{"Issue": "42", "Branch": "fix/42"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got != "Fix issue #42 on fix/42" {
+		t.Fatalf("Render = %q", got)
+	}
+
+	if _, err := Render("{{.Issue}}", map[string]string{"Branch": "x"}); err == nil {
+		t.Fatalf("expected error for missing variable")
+	}
+	if _, err := Render("{{.Issue", nil); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNewerVersion is returned when a file's version is ahead of what this
+// build understands, usually because a newer ccw wrote it.
+var ErrNewerVersion = errors.New("file was written by a newer version")
+
+// Document is a decoded JSON object as seen by migrations. Numbers decode as
+// json.Number so they round-trip unchanged.
+type Document map[string]any
+
+// Migration upgrades a document from version From to From+1. Apply edits the
+// document in place; the version field is bumped for it.
+type Migration struct {
+	From  int
+	Apply func(doc Document) error
+}
+
+// Version reads the top-level "version" field of a JSON document. A missing
+// field is version 0.
+func Version(data []byte) (int, error) {
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return 0, err
+	}
+	return head.Version, nil
+}
+
+// Migrate upgrades data to version target by running the migrations for
+// each version in turn. It reports whether anything changed; data already at
+// target is returned as-is.
+func Migrate(data []byte, target int, migrations []Migration) ([]byte, bool, error) {
+	version, err := Version(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if version == target {
+		return data, false, nil
+	}
+	if version > target {
+		return nil, false, fmt.Errorf("%w: version %d, this build supports up to %d", ErrNewerVersion, version, target)
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.From] = m
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false, err
+	}
+
+	for ; version < target; version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, false, fmt.Errorf("no migration from version %d", version)
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, false, fmt.Errorf("migrate version %d to %d: %w", version, version+1, err)
+		}
+		doc["version"] = version + 1
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partial file. Each call uses its
+// own temporary file, so concurrent writers don't collide; the last rename
+// wins.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("atomically write file: %w", err)
+	}
+	return nil
+}
+
+// Objects returns the values of a JSON object field that are themselves
+// objects, keyed like the field. Handy for migrations that touch every entry
+// of a map such as the registry's workspaces.
+func (d Document) Objects(field string) map[string]Document {
+	raw, ok := d[field].(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := make(map[string]Document, len(raw))
+	for k, v := range raw {
+		if obj, ok := v.(map[string]any); ok {
+			out[k] = Document(obj)
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMigrateRunsChainInOrder(t *testing.T) {
+	var order []int
+	migrations := []Migration{
+		{From: 2, Apply: func(doc Document) error {
+			order = append(order, 2)
+			doc["c"] = doc["b"]
+			return nil
+		}},
+		{From: 1, Apply: func(doc Document) error {
+			order = append(order, 1)
+			doc["b"] = doc["a"]
+			delete(doc, "a")
+			return nil
+		}},
+	}
+
+	out, changed, err := Migrate([]byte(`{"version": 1, "a": 12345678901234567}`), 3, migrations)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if !changed || len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("unexpected run order %v (changed=%v)", order, changed)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if string(doc["version"]) != "3" || string(doc["c"]) != "12345678901234567" || doc["a"] != nil {
+		t.Fatalf("unexpected result: %s", out)
+	}
+}
+
+func TestMigrateCurrentIsUnchanged(t *testing.T) {
+	in := []byte(`{"version": 2}`)
+	out, changed, err := Migrate(in, 2, nil)
+	if err != nil || changed || string(out) != string(in) {
+		t.Fatalf("expected no change, got %s (changed=%v, err=%v)", out, changed, err)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	if _, _, err := Migrate([]byte(`{"version": 3}`), 2, nil); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("expected ErrNewerVersion, got %v", err)
+	}
+	if _, _, err := Migrate([]byte(`{"version": 1}`), 3, []Migration{{From: 1, Apply: func(Document) error { return nil }}}); err == nil || !strings.Contains(err.Error(), "no migration from version 2") {
+		t.Fatalf("expected a gap in the chain to fail, got %v", err)
+	}
+	failing := []Migration{{From: 1, Apply: func(Document) error { return errors.New("boom") }}}
+	if _, _, err := Migrate([]byte(`{"version": 1}`), 2, failing); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected migration error, got %v", err)
+	}
+}
+
+func TestWriteFileAtomicConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- WriteFileAtomic(path, []byte(fmt.Sprintf(`{"writer": %d}`, i)), 0o644)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("WriteFileAtomic: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), `{"writer": `) {
+		t.Fatalf("expected one writer's content, got %q (%v)", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o644 {
+		t.Fatalf("unexpected mode: %v (%v)", info.Mode(), err)
+	}
+	if leftovers, _ := filepath.Glob(path + ".tmp*"); len(leftovers) != 0 {
+		t.Fatalf("expected temp files to be gone, got %v", leftovers)
+	}
+}
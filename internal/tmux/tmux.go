@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"golang.org/x/term"
@@ -262,34 +263,193 @@ func removeControlWindowID(session string) error {
 	return os.Remove(controlWindowIDPath(session))
 }
 
-func (r Runner) SplitPane(session string, horizontal bool, path string) error {
-	target := normalizeTarget(session)
-	args := []string{"split-window", "-t", target}
+// SplitPane splits the pane at target and returns the new pane's ID (e.g.
+// "%3"), which stays valid as further panes are added. size is a percentage
+// of the split pane; zero lets tmux choose.
+func (r Runner) SplitPane(target string, horizontal bool, path string, size int) (string, error) {
+	target = normalizeTarget(target)
+	args := []string{"split-window", "-t", target, "-P", "-F", "#{pane_id}"}
 	if horizontal {
 		args = append(args, "-h")
 	} else {
 		args = append(args, "-v")
 	}
+	if size > 0 {
+		args = append(args, "-l", fmt.Sprintf("%d%%", size))
+	}
 	if path != "" {
 		args = append(args, "-c", path)
 	}
 
-	_, err := r.run(context.Background(), args...)
+	out, err := r.run(context.Background(), args...)
+	if err != nil {
+		return "", err
+	}
+	return parsePaneID(out), nil
+}
+
+// parsePaneID extracts a pane ID from split-window -P output. In control
+// mode the ID is wrapped in %begin/%end notifications, so scan every line.
+func parsePaneID(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 1 && line[0] == '%' && strings.Trim(line[1:], "0123456789") == "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// SendText types text into the pane at target as is, then presses Enter if
+// enter is set. Words in text such as "Escape" or "Tab" are typed, not read
+// as key names.
+func (r Runner) SendText(target, text string, enter bool) error {
+	target = normalizeTarget(target)
+	if _, err := r.run(context.Background(), "send-keys", "-l", "-t", target, "--", text); err != nil {
+		return err
+	}
+	if !enter {
+		return nil
+	}
+	_, err := r.run(context.Background(), "send-keys", "-t", target, "Enter")
 	return err
 }
 
-func (r Runner) SendKeys(target string, keys []string, enter bool) error {
+// CapturePane returns the text shown in a pane, with wrapped lines joined.
+// lines > 0 also includes up to that many lines of scrollback.
+func (r Runner) CapturePane(target string, lines int) (string, error) {
+	args := []string{"capture-pane", "-p", "-J", "-t", normalizeTarget(target)}
+	if lines > 0 {
+		args = append(args, "-S", "-"+strconv.Itoa(lines))
+	}
+	return r.run(context.Background(), args...)
+}
+
+// PipePane feeds everything the pane at target prints to the stdin of
+// command, a shell command, from now until the pane closes. It does nothing
+// if the pane is already piped.
+func (r Runner) PipePane(target, command string) error {
 	target = normalizeTarget(target)
-	args := []string{"send-keys", "-t", target}
-	args = append(args, keys...)
-	if enter {
-		args = append(args, "Enter")
+	// pipe-pane -o closes an existing pipe rather than leaving it alone, so
+	// check first.
+	piped, err := r.run(context.Background(), "display-message", "-p", "-t", target, "#{pane_pipe}")
+	if err != nil {
+		return err
 	}
+	for _, line := range strings.Split(piped, "\n") {
+		if strings.TrimSpace(line) == "1" {
+			return nil
+		}
+	}
+	_, err = r.run(context.Background(), "pipe-pane", "-t", target, command)
+	return err
+}
 
-	_, err := r.run(context.Background(), args...)
+// Session is a running tmux session and the directory it was started in.
+type Session struct {
+	Name string
+	Path string
+	// Attached is the number of clients attached to the session.
+	Attached int
+}
+
+// ListSessions returns every session on the tmux server with its attached
+// client count, in a single tmux call. No server running means no sessions.
+func (r Runner) ListSessions() ([]Session, error) {
+	out, err := r.run(context.Background(), "list-sessions", "-F", "#{session_name}\t#{session_path}\t#{session_attached}")
+	if err != nil {
+		if code, ok := exitCode(err); ok && code == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseSessions(out), nil
+}
+
+// parseSessions reads list-sessions output, skipping control-mode
+// notifications, which have no tab.
+func parseSessions(out string) []Session {
+	var sessions []Session
+	for _, line := range strings.Split(out, "\n") {
+		name, rest, ok := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+		if !ok || name == "" {
+			continue
+		}
+		session := Session{Name: name, Path: rest}
+		if i := strings.LastIndex(rest, "\t"); i >= 0 {
+			if n, err := strconv.Atoi(rest[i+1:]); err == nil {
+				session.Path, session.Attached = rest[:i], n
+			}
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Pane describes a pane of a session's first window.
+type Pane struct {
+	Index int
+	// ID is tmux's pane ID, such as "%3", which stays the same when panes
+	// are moved or renumbered.
+	ID string
+	// PID is the pane's shell; whatever ccw launched in it runs below it.
+	PID int
+	// Command is the name of the pane's foreground process.
+	Command string
+	// Name and Launch are what TagPane recorded on the pane. Both are empty
+	// for panes ccw didn't tag.
+	Name   string
+	Launch string
+	// Title is the pane title, which programs set with escape sequences.
+	Title string
+}
+
+// TagPane records on the pane at target the layout pane name it was created
+// for and what was launched in it, for Panes to report.
+func (r Runner) TagPane(target, name, launch string) error {
+	target = normalizeTarget(target)
+	if _, err := r.run(context.Background(), "set-option", "-p", "-t", target, "@ccw_pane", name); err != nil {
+		return err
+	}
+	_, err := r.run(context.Background(), "set-option", "-p", "-t", target, "@ccw_launch", launch)
 	return err
 }
 
+// Panes lists the panes of a session's first window.
+func (r Runner) Panes(session string) ([]Pane, error) {
+	out, err := r.run(context.Background(), "list-panes", "-t", session+":0", "-F",
+		"#{pane_index}\t#{pane_id}\t#{pane_pid}\t#{pane_current_command}\t#{@ccw_pane}\t#{@ccw_launch}\t#{pane_title}")
+	if err != nil {
+		return nil, err
+	}
+	return parsePanes(out), nil
+}
+
+func parsePanes(out string) []Pane {
+	var panes []Pane
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), "\t", 7)
+		if len(fields) < 7 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[2])
+		panes = append(panes, Pane{
+			Index:   index,
+			ID:      fields[1],
+			PID:     pid,
+			Command: fields[3],
+			Name:    fields[4],
+			Launch:  fields[5],
+			Title:   fields[6],
+		})
+	}
+	return panes
+}
+
 func (r Runner) ListPanes(session string) (int, error) {
 	target := normalizeTarget(session)
 	out, err := r.run(context.Background(), "list-panes", "-t", target)
@@ -315,7 +475,8 @@ func normalizeTarget(target string) string {
 	// Session names can legitimately contain "." (e.g., "elicited.blog--branch").
 	// Tmux uses "." as window.pane separator, but we want to append ":" to
 	// session-only targets to avoid tmux misinterpreting dots in session names.
-	if strings.Contains(target, ":") {
+	// Pane IDs ("%3") are already unambiguous.
+	if strings.Contains(target, ":") || strings.HasPrefix(target, "%") {
 		return target
 	}
 	return target + ":"
@@ -406,10 +567,10 @@ func pickMacTerminalApp() string {
 }
 
 func tmuxAttachCommand(tmuxBin, session string, ccMode bool) string {
-	quotedSession := shellQuote(session)
-	base := fmt.Sprintf("%s attach -t %s", shellQuote(tmuxBin), quotedSession)
+	quotedSession := ShellQuote(session)
+	base := fmt.Sprintf("%s attach -t %s", ShellQuote(tmuxBin), quotedSession)
 	if ccMode {
-		base = fmt.Sprintf("%s -CC attach -t %s || %s attach -t %s", shellQuote(tmuxBin), quotedSession, shellQuote(tmuxBin), quotedSession)
+		base = fmt.Sprintf("%s -CC attach -t %s || %s attach -t %s", ShellQuote(tmuxBin), quotedSession, ShellQuote(tmuxBin), quotedSession)
 	}
 	return base
 }
@@ -425,7 +586,8 @@ func tmuxBinary() string {
 	return "tmux"
 }
 
-func shellQuote(s string) string {
+// ShellQuote quotes s as a single word for sh.
+func ShellQuote(s string) string {
 	if s == "" {
 		return "''"
 	}
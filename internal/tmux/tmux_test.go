@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -71,9 +72,13 @@ func TestSplitPane(t *testing.T) {
 	}
 	defer runner.KillSession(name)
 
-	if err := runner.SplitPane(name, true, dir); err != nil {
+	paneID, err := runner.SplitPane(name, true, dir, 0)
+	if err != nil {
 		t.Fatalf("SplitPane: %v", err)
 	}
+	if paneID == "" {
+		t.Fatalf("expected SplitPane to return the new pane id")
+	}
 
 	panes, err := runner.ListPanes(name)
 	if err != nil {
@@ -84,36 +89,6 @@ func TestSplitPane(t *testing.T) {
 	}
 }
 
-func TestSendKeys(t *testing.T) {
-	requireTmux(t)
-	runner := NewRunner(false)
-	name := newSessionName()
-	dir := t.TempDir()
-	targetFile := filepath.Join(dir, "out.txt")
-
-	if err := runner.CreateSession(name, dir, true); err != nil {
-		t.Fatalf("CreateSession: %v", err)
-	}
-	defer runner.KillSession(name)
-
-	time.Sleep(100 * time.Millisecond)
-
-	target := name + ":0.0"
-	if err := runner.SendKeys(target, []string{"echo -n hello > " + targetFile}, true); err != nil {
-		t.Fatalf("SendKeys: %v", err)
-	}
-
-	time.Sleep(500 * time.Millisecond)
-
-	data, err := os.ReadFile(targetFile)
-	if err != nil {
-		t.Fatalf("read output: %v", err)
-	}
-	if string(data) != "hello" {
-		t.Fatalf("expected output to be written, got %q", string(data))
-	}
-}
-
 func TestCCModeHasSession(t *testing.T) {
 	requireTmux(t)
 	runner := NewRunner(true)
@@ -135,6 +110,7 @@ func TestNormalizeTarget(t *testing.T) {
 		{"already has colon", "session:window", "session:window"},
 		{"full target", "session:window.pane", "session:window.pane"},
 		{"colon only", "session:", "session:"},
+		{"pane id", "%12", "%12"},
 	}
 
 	for _, tt := range tests {
@@ -180,7 +156,7 @@ func TestSplitPaneWithUnderscoreInName(t *testing.T) {
 	}
 	defer runner.KillSession(name)
 
-	if err := runner.SplitPane(name, true, dir); err != nil {
+	if _, err := runner.SplitPane(name, true, dir, 0); err != nil {
 		t.Fatalf("SplitPane with underscored session name: %v", err)
 	}
 
@@ -192,3 +168,235 @@ func TestSplitPaneWithUnderscoreInName(t *testing.T) {
 		t.Fatalf("expected 2 panes, got %d", panes)
 	}
 }
+
+func TestSplitPaneWithSizeAndTarget(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+	dir := t.TempDir()
+
+	if err := runner.CreateSession(name, dir, true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+
+	right, err := runner.SplitPane(name+":0.0", true, dir, 40)
+	if err != nil {
+		t.Fatalf("SplitPane: %v", err)
+	}
+	if _, err := runner.SplitPane(right, false, dir, 30); err != nil {
+		t.Fatalf("SplitPane from pane id: %v", err)
+	}
+
+	panes, err := runner.ListPanes(name)
+	if err != nil {
+		t.Fatalf("ListPanes: %v", err)
+	}
+	if panes != 3 {
+		t.Fatalf("expected 3 panes, got %d", panes)
+	}
+}
+
+func TestParsePaneID(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"plain", "%12", "%12"},
+		{"control mode", "%begin 1 2 0\n%5\n%end 1 2 0", "%5"},
+		{"empty", "", ""},
+		{"not an id", "%begin", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePaneID(tt.input); got != tt.expect {
+				t.Fatalf("parsePaneID(%q) = %q, want %q", tt.input, got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+	dir := t.TempDir()
+
+	if err := runner.CreateSession(name, dir, true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+
+	sessions, err := runner.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	for _, s := range sessions {
+		if s.Name == name {
+			if s.Path != dir {
+				t.Fatalf("expected session path %s, got %s", dir, s.Path)
+			}
+			return
+		}
+	}
+	t.Fatalf("session %s not listed in %+v", name, sessions)
+}
+
+func TestParseSessions(t *testing.T) {
+	out := "%begin 1 2 0\nwork\t/home/me/wt/a\t2\nscratch\t/tmp\t0\nold\t/srv\n%end 1 2 0"
+	sessions := parseSessions(out)
+	if len(sessions) != 3 || sessions[0].Name != "work" || sessions[1].Path != "/tmp" {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+	if sessions[0].Path != "/home/me/wt/a" || sessions[0].Attached != 2 || sessions[1].Attached != 0 {
+		t.Fatalf("unexpected attached counts: %+v", sessions)
+	}
+	if sessions[2].Path != "/srv" {
+		t.Fatalf("expected a line without a count to keep its path, got %+v", sessions[2])
+	}
+}
+
+func TestCapturePane(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+
+	if err := runner.CreateSession(name, t.TempDir(), true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+
+	target := name + ":0.0"
+	if err := runner.SendText(target, "echo ccw-capture-$((40+2))", true); err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := runner.CapturePane(target, 100)
+		if err != nil {
+			t.Fatalf("CapturePane: %v", err)
+		}
+		if strings.Contains(out, "ccw-capture-42") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected echoed output in pane, got %q", out)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestSendTextIsLiteral(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+
+	if err := runner.CreateSession(name, t.TempDir(), true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+
+	target := name + ":0.0"
+	// Without -l, Escape and Tab would be pressed rather than typed.
+	if err := runner.SendText(target, "echo ccw-text Escape Tab", true); err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := runner.CapturePane(target, 100)
+		if err != nil {
+			t.Fatalf("CapturePane: %v", err)
+		}
+		if strings.Contains(out, "\nccw-text Escape Tab") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the text echoed as typed, got %q", out)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestPipePane(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+
+	if err := runner.CreateSession(name, t.TempDir(), true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+
+	target := name + ":0.0"
+	logFile := filepath.Join(t.TempDir(), "pane log.txt")
+	pipe := "cat >> " + ShellQuote(logFile)
+	if err := runner.PipePane(target, pipe); err != nil {
+		t.Fatalf("PipePane: %v", err)
+	}
+	// A second call must not toggle the pipe off.
+	if err := runner.PipePane(target, pipe); err != nil {
+		t.Fatalf("PipePane again: %v", err)
+	}
+	if err := runner.SendText(target, "echo ccw-pipe-$((40+2))", true); err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logFile)
+		if strings.Contains(string(data), "ccw-pipe-42") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected echoed output in log, got %q", data)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestParsePanes(t *testing.T) {
+	out := "%begin 1 2 0\n0\t%3\t4242\tnode\tleft\tclaude\t⠂ Fix tests\n1\t%4\t4250\tzsh\t\t\tmy\ttitle\n%end 1 2 0"
+	panes := parsePanes(out)
+	if len(panes) != 2 {
+		t.Fatalf("unexpected panes: %+v", panes)
+	}
+	if panes[0] != (Pane{Index: 0, ID: "%3", PID: 4242, Command: "node", Name: "left", Launch: "claude", Title: "⠂ Fix tests"}) {
+		t.Fatalf("unexpected first pane: %+v", panes[0])
+	}
+	if panes[1].Index != 1 || panes[1].Title != "my\ttitle" {
+		t.Fatalf("expected tabs to stay in the title, got %+v", panes[1])
+	}
+}
+
+func TestPanes(t *testing.T) {
+	requireTmux(t)
+	runner := NewRunner(false)
+	name := newSessionName()
+
+	if err := runner.CreateSession(name, t.TempDir(), true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer runner.KillSession(name)
+	id, err := runner.SplitPane(name, true, t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("SplitPane: %v", err)
+	}
+	if err := runner.TagPane(id, "right", "codex"); err != nil {
+		t.Fatalf("TagPane: %v", err)
+	}
+
+	panes, err := runner.Panes(name)
+	if err != nil {
+		t.Fatalf("Panes: %v", err)
+	}
+	if len(panes) != 2 || panes[0].Index != 0 || panes[1].Index != 1 || panes[0].PID == 0 {
+		t.Fatalf("unexpected panes: %+v", panes)
+	}
+	if panes[1].ID != id || panes[1].Name != "right" || panes[1].Launch != "codex" || panes[0].Name != "" {
+		t.Fatalf("expected only the split pane tagged, got %+v", panes)
+	}
+}
@@ -0,0 +1,261 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/storage"
+)
+
+// Activity is what an agent is doing, as far as ccw can tell.
+type Activity string
+
+const (
+	ActivityWorking Activity = "working"
+	ActivityIdle    Activity = "idle"
+	// ActivityWaiting means the agent is asking permission to go on.
+	ActivityWaiting Activity = "waiting"
+	ActivityErrored Activity = "errored"
+	// ActivityStopped means the agent's process has exited.
+	ActivityStopped Activity = "stopped"
+	ActivityUnknown Activity = "unknown"
+)
+
+// activityRank orders activities by how much they need the user, for the
+// activity of a workspace with several agents.
+var activityRank = map[Activity]int{
+	ActivityWaiting: 5,
+	ActivityErrored: 4,
+	ActivityWorking: 3,
+	ActivityIdle:    2,
+	ActivityStopped: 1,
+}
+
+// AgentActivity is what the agent in one layout pane is doing.
+type AgentActivity struct {
+	Pane  string   `json:"pane"`
+	Agent string   `json:"agent"`
+	State Activity `json:"state"`
+	// Reason names the signal State was read from.
+	Reason string `json:"reason,omitempty"`
+}
+
+// agentStatus is the last Claude Code hook event recorded for a workspace.
+type agentStatus struct {
+	Event   string    `json:"event"`
+	State   Activity  `json:"state"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// claudeHookInput is the part of a Claude Code hook's JSON input ccw uses.
+type claudeHookInput struct {
+	Event            string `json:"hook_event_name"`
+	Message          string `json:"message"`
+	NotificationType string `json:"notification_type"`
+}
+
+// statusFile is where hook events for a workspace's session are recorded.
+func (m *Manager) statusFile(session string) string {
+	return filepath.Join(m.root, "status", session+".json")
+}
+
+// hookActivity maps a Claude Code hook event to the activity it starts.
+// Events that say nothing about activity return false.
+func hookActivity(in claudeHookInput) (Activity, bool) {
+	switch in.Event {
+	case "UserPromptSubmit", "PreToolUse", "PostToolUse", "SubagentStop", "PreCompact":
+		return ActivityWorking, true
+	case "Stop", "SessionStart":
+		return ActivityIdle, true
+	case "StopFailure":
+		return ActivityErrored, true
+	case "SessionEnd":
+		return ActivityStopped, true
+	case "Notification":
+		if in.NotificationType == "permission_prompt" || strings.Contains(strings.ToLower(in.Message), "permission") {
+			return ActivityWaiting, true
+		}
+		return ActivityIdle, true
+	}
+	return "", false
+}
+
+// RecordClaudeHook records a Claude Code hook event, read as JSON from r,
+// for the workspace the hook runs in. Outside a workspace, or for events
+// that don't change the activity, it does nothing.
+func (m *Manager) RecordClaudeHook(ctx context.Context, r io.Reader) error {
+	var in claudeHookInput
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return fmt.Errorf("read hook input: %w", err)
+	}
+	state, ok := hookActivity(in)
+	if !ok {
+		return nil
+	}
+	_, ws, err := m.FindCurrent(ctx)
+	if errors.Is(err, ErrNoCurrentWorkspace) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(agentStatus{Event: in.Event, State: state, Message: in.Message, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	path := m.statusFile(ws.TmuxSession)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Hooks for parallel tool calls run at once. Each write goes through its
+	// own temporary file, and the last one to land wins.
+	return storage.WriteFileAtomic(path, data, 0o644)
+}
+
+func (m *Manager) readAgentStatus(session string) *agentStatus {
+	data, err := os.ReadFile(m.statusFile(session))
+	if err != nil {
+		return nil
+	}
+	var st agentStatus
+	if json.Unmarshal(data, &st) != nil || st.State == "" {
+		return nil
+	}
+	return &st
+}
+
+// listProcesses maps each running process to its children.
+func listProcesses() (map[int][]int, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	children := make(map[int][]int)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		children[ppid] = append(children[ppid], pid)
+	}
+	return children, nil
+}
+
+// collectActivity fills in the activity of each workspace with a running
+// session.
+func (m *Manager) collectActivity(statuses []WorkspaceStatus) {
+	// Without a process list, agents are assumed to be running.
+	children, _ := m.processes()
+	forEachLimit(len(statuses), statusConcurrency, func(i int) {
+		st := &statuses[i]
+		if !st.SessionAlive || st.Workspace.Archived {
+			return
+		}
+		st.Agents = m.agentActivity(st.Workspace, children)
+		st.Activity = overallActivity(st.Agents)
+	})
+}
+
+// agentActivity classifies the agent in each of ws's agent panes.
+func (m *Manager) agentActivity(ws Workspace, children map[int][]int) []AgentActivity {
+	registry := m.agentsFor(m.existingRepoConfig(ws))
+	panes, err := m.sessionPanes(ws)
+	if err != nil {
+		return nil
+	}
+	hook := m.readAgentStatus(ws.TmuxSession)
+
+	var agents []AgentActivity
+	for _, p := range panes {
+		a, ok := registry.Get(p.Command)
+		if !ok || m.agentMissing(a) {
+			continue
+		}
+		tp := p.Live
+		act := AgentActivity{Pane: p.Name, Agent: a.Name(), State: ActivityUnknown}
+		content, err := m.tmux.CapturePane(p.Target, 0)
+		if err != nil {
+			act.Reason = "pane could not be captured"
+			agents = append(agents, act)
+			continue
+		}
+		running := children == nil || len(children[tp.PID]) > 0
+		var paneHook *agentStatus
+		if a.Name() == "claude" {
+			paneHook = hook
+		}
+		act.State, act.Reason = classifyActivity(a, content, tp.Title, running, paneHook)
+		agents = append(agents, act)
+	}
+	return agents
+}
+
+// classifyActivity reads an agent's activity from its pane. The signals, in
+// order of trust: whether the agent process is still running under the
+// pane's shell, a permission prompt on screen, the last hook event the
+// agent reported, a busy hint or spinner title, an error on screen, and a
+// ready hint. A hook event the screen contradicts is stale and skipped:
+// claude fires no hook when a turn is interrupted with Esc, so "working"
+// can outlive the turn, and a busy screen means a turn started since "idle".
+func classifyActivity(a agent.Agent, content, title string, running bool, hook *agentStatus) (Activity, string) {
+	errored := paneShows(content, agent.ErrorMarkers(a))
+	busy := paneShows(content, agent.BusyMarkers(a)) || titleSpinning(title)
+	ready := paneShows(content, agent.ReadyMarkers(a))
+	if hook != nil && (hook.State == ActivityWorking && !busy && ready || hook.State == ActivityIdle && busy) {
+		hook = nil
+	}
+	switch {
+	case !running && errored:
+		return ActivityErrored, "agent exited after an error"
+	case !running:
+		return ActivityStopped, "agent is not running"
+	case paneShows(content, agent.PermissionMarkers(a)):
+		return ActivityWaiting, "permission prompt"
+	case hook != nil:
+		return hook.State, "hook " + hook.Event
+	case paneShows(content, agent.BusyMarkers(a)):
+		return ActivityWorking, "busy hint"
+	case titleSpinning(title):
+		return ActivityWorking, "pane title"
+	case errored:
+		return ActivityErrored, "error on screen"
+	case ready:
+		return ActivityIdle, "ready hint"
+	}
+	return ActivityUnknown, ""
+}
+
+// titleSpinning reports whether a pane title starts with a braille spinner
+// frame, which agents put in the terminal title while they work.
+func titleSpinning(title string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(title))
+	return r >= 0x2800 && r <= 0x28FF && r != 0x2800
+}
+
+// overallActivity is the activity of the agent that most needs the user.
+func overallActivity(agents []AgentActivity) Activity {
+	var overall Activity
+	for _, a := range agents {
+		if overall == "" || activityRank[a.State] > activityRank[overall] {
+			overall = a.State
+		}
+	}
+	return overall
+}
@@ -0,0 +1,155 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/tmux"
+)
+
+func TestHookActivity(t *testing.T) {
+	tests := []struct {
+		in   claudeHookInput
+		want Activity
+		ok   bool
+	}{
+		{claudeHookInput{Event: "UserPromptSubmit"}, ActivityWorking, true},
+		{claudeHookInput{Event: "PreToolUse"}, ActivityWorking, true},
+		{claudeHookInput{Event: "Stop"}, ActivityIdle, true},
+		{claudeHookInput{Event: "Notification", Message: "Claude needs your permission to use Bash"}, ActivityWaiting, true},
+		{claudeHookInput{Event: "Notification", NotificationType: "permission_prompt"}, ActivityWaiting, true},
+		{claudeHookInput{Event: "Notification", Message: "Claude is waiting for your input"}, ActivityIdle, true},
+		{claudeHookInput{Event: "StopFailure"}, ActivityErrored, true},
+		{claudeHookInput{Event: "SessionEnd"}, ActivityStopped, true},
+		{claudeHookInput{Event: "InstructionsLoaded"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := hookActivity(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("hookActivity(%+v) = %q, %t; want %q, %t", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyActivity(t *testing.T) {
+	claude := agent.NewClaude()
+	const ready = "╭─╮\n│ > │\n╰─╯\n  ? for shortcuts\n"
+	tests := []struct {
+		name    string
+		content string
+		title   string
+		running bool
+		hook    *agentStatus
+		want    Activity
+	}{
+		{"exited", "$ ", "", false, nil, ActivityStopped},
+		{"crashed", "API Error: 500\n$ ", "", false, nil, ActivityErrored},
+		{"permission prompt", "Bash(rm -rf build)\nDo you want to proceed?\n❯ 1. Yes", "", true, &agentStatus{State: ActivityWorking}, ActivityWaiting},
+		{"hook", "⏺ Bash(go test ./...)\n", "", true, &agentStatus{Event: "PreToolUse", State: ActivityWorking}, ActivityWorking},
+		{"hook while busy", "✻ Running… (esc to interrupt)\n" + ready, "", true, &agentStatus{Event: "PreToolUse", State: ActivityWorking}, ActivityWorking},
+		{"stale working hook", "⎿ Interrupted by user\n" + ready, "✳ Claude Code", true, &agentStatus{Event: "PreToolUse", State: ActivityWorking}, ActivityIdle},
+		{"stale idle hook", "✻ Thinking… (esc to interrupt)\n" + ready, "", true, &agentStatus{Event: "Stop", State: ActivityIdle}, ActivityWorking},
+		{"waiting hook", ready, "", true, &agentStatus{Event: "Notification", State: ActivityWaiting}, ActivityWaiting},
+		{"busy hint", "✻ Thinking… (esc to interrupt)\n" + ready, "", true, nil, ActivityWorking},
+		{"spinner title", "", "⠐ Fix the tests", true, nil, ActivityWorking},
+		{"error on screen", "⎿ API Error: 529 Overloaded\n" + ready, "✳ Fix the tests", true, nil, ActivityErrored},
+		{"ready", ready, "✳ Claude Code", true, nil, ActivityIdle},
+		{"blank", "", "", true, nil, ActivityUnknown},
+	}
+	for _, tt := range tests {
+		if got, _ := classifyActivity(claude, tt.content, tt.title, tt.running, tt.hook); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOverallActivity(t *testing.T) {
+	agents := []AgentActivity{{State: ActivityIdle}, {State: ActivityWaiting}, {State: ActivityWorking}}
+	if got := overallActivity(agents); got != ActivityWaiting {
+		t.Fatalf("overallActivity = %q, want waiting", got)
+	}
+	if got := overallActivity([]AgentActivity{{State: ActivityUnknown}}); got != ActivityUnknown {
+		t.Fatalf("overallActivity = %q, want unknown", got)
+	}
+	if got := overallActivity(nil); got != "" {
+		t.Fatalf("overallActivity(nil) = %q", got)
+	}
+}
+
+func TestListWorkspacesActivityFromHook(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, stub)
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/activity", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	// Outside a workspace the hook is a no-op.
+	t.Setenv("TMUX", "")
+	t.Chdir(t.TempDir())
+	if err := mgr.RecordClaudeHook(ctx, strings.NewReader(`{"hook_event_name":"Stop"}`)); err != nil {
+		t.Fatalf("RecordClaudeHook outside a workspace: %v", err)
+	}
+	if _, err := os.Stat(mgr.statusFile(ws.TmuxSession)); !os.IsNotExist(err) {
+		t.Fatalf("expected no status file, got %v", err)
+	}
+
+	t.Chdir(ws.WorktreePath)
+	input := `{"hook_event_name":"Notification","message":"Claude needs your permission to use Bash","cwd":"` + ws.WorktreePath + `"}`
+	if err := mgr.RecordClaudeHook(ctx, strings.NewReader(input)); err != nil {
+		t.Fatalf("RecordClaudeHook: %v", err)
+	}
+
+	// Claude fires hooks for parallel tool calls at once; none may fail.
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- mgr.RecordClaudeHook(ctx, strings.NewReader(`{"hook_event_name":"PreToolUse"}`))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent RecordClaudeHook: %v", err)
+		}
+	}
+	if err := mgr.RecordClaudeHook(ctx, strings.NewReader(input)); err != nil {
+		t.Fatalf("RecordClaudeHook: %v", err)
+	}
+
+	stub.livePanes = map[string][]tmux.Pane{ws.TmuxSession: {{Index: 0, PID: 100, Command: "claude"}, {Index: 1, PID: 200, Command: "zsh"}}}
+	mgr.processes = func() (map[int][]int, error) { return map[int][]int{100: {101}}, nil }
+
+	statuses, err := mgr.ListWorkspaces(ctx, ListOptions{Activity: true})
+	if err != nil {
+		t.Fatalf("ListWorkspaces: %v", err)
+	}
+	st := statuses[0]
+	if st.Activity != ActivityWaiting || len(st.Agents) != 1 {
+		t.Fatalf("unexpected activity: %q %+v", st.Activity, st.Agents)
+	}
+	if a := st.Agents[0]; a.Pane != "left" || a.Agent != "claude" || a.Reason != "hook Notification" {
+		t.Fatalf("unexpected agent activity: %+v", a)
+	}
+
+	// Once claude exits, its last hook event no longer counts.
+	mgr.processes = func() (map[int][]int, error) { return map[int][]int{}, nil }
+	st, err = mgr.WorkspaceInfo(ctx, ws.Repo+"/"+ws.Branch)
+	if err != nil {
+		t.Fatalf("WorkspaceInfo: %v", err)
+	}
+	if st.Activity != ActivityStopped {
+		t.Fatalf("expected stopped after the agent exits, got %q %+v", st.Activity, st.Agents)
+	}
+}
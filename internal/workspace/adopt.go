@@ -0,0 +1,148 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+// AdoptCandidate is a worktree made outside ccw that could be registered.
+type AdoptCandidate struct {
+	ID        string
+	Workspace Workspace
+	// Session is the tmux session found running in the worktree, if any.
+	Session string
+}
+
+// AdoptCandidates scans every repo under ReposDir for worktrees that aren't
+// registered yet and pairs each with a running tmux session where one
+// exists. The main checkout, bare repos and detached worktrees are skipped.
+func (m *Manager) AdoptCandidates(ctx context.Context, repoFilter string) ([]AdoptCandidate, error) {
+	if err := m.checkDepsByName("git"); err != nil {
+		return nil, err
+	}
+
+	reposDir, err := m.cfg.ExpandedReposDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := m.regStore.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool, len(reg.Workspaces))
+	for _, ws := range reg.Workspaces {
+		registered[canonicalPath(ws.WorktreePath)] = true
+	}
+
+	// Session lookup is best-effort: without a tmux server there is simply
+	// nothing to match.
+	sessionsByPath := map[string]string{}
+	sessionNames := map[string]bool{}
+	if sessions, err := m.tmux.ListSessions(); err == nil {
+		for _, s := range sessions {
+			sessionNames[s.Name] = true
+			if s.Path != "" {
+				sessionsByPath[canonicalPath(s.Path)] = s.Name
+			}
+		}
+	}
+
+	var candidates []AdoptCandidate
+	for _, e := range entries {
+		repo := e.Name()
+		if !e.IsDir() || strings.HasPrefix(repo, ".") {
+			continue
+		}
+		if repoFilter != "" && repo != repoFilter {
+			continue
+		}
+		repoPath := filepath.Join(reposDir, repo)
+		if _, err := git.ValidateRepo(repoPath); err != nil {
+			continue
+		}
+
+		worktrees, err := git.ListWorktrees(repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("list worktrees of %s: %w", repo, err)
+		}
+		for i, wt := range worktrees {
+			// The first entry is the main checkout.
+			if i == 0 || wt.Bare || wt.Branch == "" {
+				continue
+			}
+			path := canonicalPath(wt.Path)
+			id := WorkspaceID(repo, wt.Branch)
+			if registered[path] {
+				continue
+			}
+			if _, taken := reg.Workspaces[id]; taken {
+				continue
+			}
+
+			safeName := SafeName(repo, wt.Branch)
+			session := sessionsByPath[path]
+			if session == "" && sessionNames[safeName] {
+				session = safeName
+			}
+
+			tmuxSession := safeName
+			if session != "" {
+				tmuxSession = session
+			}
+			candidates = append(candidates, AdoptCandidate{
+				ID: id,
+				Workspace: Workspace{
+					Repo:           repo,
+					RepoPath:       repoPath,
+					Branch:         wt.Branch,
+					WorktreePath:   wt.Path,
+					ClaudeSession:  discoverClaudeSession(wt.Path),
+					TmuxSession:    tmuxSession,
+					ExternalBranch: true,
+				},
+				Session: session,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	return candidates, nil
+}
+
+// Adopt registers the given candidates. Adopted branches are marked
+// external, since ccw didn't create them, so `ccw rm` keeps them.
+func (m *Manager) Adopt(ctx context.Context, candidates []AdoptCandidate) error {
+	now := time.Now().UTC()
+	return m.regStore.Update(ctx, func(reg *Registry) error {
+		for _, c := range candidates {
+			ws := c.Workspace
+			ws.CreatedAt = now
+			ws.LastAccessedAt = now
+			if err := reg.Add(c.ID, ws); err != nil {
+				return fmt.Errorf("adopt %s: %w", c.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// canonicalPath makes worktree paths from git, tmux and the registry
+// comparable.
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
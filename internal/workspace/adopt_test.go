@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdoptHandMadeWorktrees(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+
+	// One workspace made by ccw, which must not be offered again.
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/ccw", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	// Two made by hand, one with a tmux session running in it.
+	withSession := filepath.Join(t.TempDir(), "hand-a")
+	runGitCmd(t, repoPath, "worktree", "add", "-b", "hand/a", withSession)
+	noSession := filepath.Join(t.TempDir(), "hand-b")
+	runGitCmd(t, repoPath, "worktree", "add", "-b", "hand/b", noSession)
+	if err := tmuxStub.CreateSession("my-old-session", withSession, true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	candidates, err := mgr.AdoptCandidates(context.Background(), "")
+	if err != nil {
+		t.Fatalf("AdoptCandidates: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+	if candidates[0].ID != WorkspaceID(repoName, "hand/a") || candidates[0].Session != "my-old-session" {
+		t.Fatalf("unexpected first candidate: %+v", candidates[0])
+	}
+	if candidates[0].Workspace.TmuxSession != "my-old-session" {
+		t.Fatalf("expected adopted workspace to reuse the session, got %q", candidates[0].Workspace.TmuxSession)
+	}
+	if candidates[1].Session != "" || candidates[1].Workspace.TmuxSession != SafeName(repoName, "hand/b") {
+		t.Fatalf("unexpected second candidate: %+v", candidates[1])
+	}
+
+	if err := mgr.Adopt(context.Background(), candidates[:1]); err != nil {
+		t.Fatalf("Adopt: %v", err)
+	}
+
+	reg, err := mgr.regStore.Read(context.Background())
+	if err != nil {
+		t.Fatalf("read registry: %v", err)
+	}
+	ws, ok := reg.Workspaces[WorkspaceID(repoName, "hand/a")]
+	if !ok {
+		t.Fatal("expected adopted workspace to be registered")
+	}
+	if !ws.ExternalBranch || ws.CreatedAt.IsZero() {
+		t.Fatalf("unexpected adopted workspace: %+v", ws)
+	}
+
+	candidates, err = mgr.AdoptCandidates(context.Background(), "")
+	if err != nil {
+		t.Fatalf("AdoptCandidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Workspace.Branch != "hand/b" {
+		t.Fatalf("expected only the remaining worktree, got %+v", candidates)
+	}
+
+	candidates, err = mgr.AdoptCandidates(context.Background(), "other-repo")
+	if err != nil || len(candidates) != 0 {
+		t.Fatalf("expected repo filter to exclude everything, got %+v (%v)", candidates, err)
+	}
+}
@@ -0,0 +1,199 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ccw/ccw/internal/git"
+	"github.com/ccw/ccw/internal/hooks"
+	"github.com/ccw/ccw/internal/tmux"
+	"golang.org/x/term"
+)
+
+type ArchiveOptions struct {
+	// Force archives a worktree with uncommitted changes, losing them.
+	Force bool
+}
+
+type RestoreOptions struct {
+	NoAttach bool
+	// NoResume starts a fresh agent conversation instead of resuming the
+	// archived one.
+	NoResume bool
+}
+
+// ErrWorkspaceArchived is returned when opening an archived workspace.
+var ErrWorkspaceArchived = errors.New("workspace is archived")
+
+// ArchiveWorkspace frees a workspace's disk and tmux session while keeping
+// its branch and registry entry, so RestoreWorkspace can bring it back with
+// the same agent conversation. Hooks don't run: nothing is being deleted for
+// good.
+func (m *Manager) ArchiveWorkspace(ctx context.Context, id string, opts ArchiveOptions) error {
+	if err := m.checkDepsByName("git", "tmux"); err != nil {
+		return err
+	}
+
+	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ws.Archived {
+		return fmt.Errorf("workspace %s is already archived", resolvedID)
+	}
+
+	if !opts.Force {
+		if _, err := os.Stat(ws.WorktreePath); err == nil {
+			changes, err := m.uncommittedWork(ctx, ws)
+			if err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				return fmt.Errorf("worktree %s has uncommitted changes (%s).\nCommit or stash them, or use --force to discard them.", ws.WorktreePath, summarizePaths(changes, 3))
+			}
+		}
+	}
+
+	if err := git.RemoveWorktree(ws.RepoPath, ws.WorktreePath, true); err != nil {
+		return fmt.Errorf("remove worktree: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if err := m.regStore.Update(ctx, func(reg *Registry) error {
+		w, ok := reg.Workspaces[resolvedID]
+		if !ok {
+			return fmt.Errorf("workspace %s not found", resolvedID)
+		}
+		w.Archived = true
+		w.ArchivedAt = now
+		reg.Workspaces[resolvedID] = w
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	clientTTYs, _ := m.tmux.ClientTTYs(ws.TmuxSession)
+
+	// Kill the session last since ccw archive may run inside the workspace.
+	if err := m.tmux.KillSession(ws.TmuxSession); err != nil && !errors.Is(err, tmux.ErrSessionMissing) {
+		return fmt.Errorf("kill session: %w", err)
+	}
+	m.tmux.CloseClientTTYs(clientTTYs)
+	tmux.CloseITermControlWindow(ws.TmuxSession)
+	return nil
+}
+
+// uncommittedWork lists the changes archiving would lose. Untracked files
+// that copy_files put there don't count; restore copies them again.
+func (m *Manager) uncommittedWork(ctx context.Context, ws Workspace) ([]string, error) {
+	entries, err := git.Status(ctx, ws.WorktreePath)
+	if err != nil {
+		return nil, err
+	}
+	carried := copyEntries(m.existingRepoConfig(ws))
+	var changes []string
+	for _, e := range entries {
+		if e.Untracked() && carriedOver(e.Path, carried) {
+			continue
+		}
+		changes = append(changes, e.Path)
+	}
+	return changes, nil
+}
+
+func summarizePaths(paths []string, max int) string {
+	if len(paths) <= max {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:max], ", "), len(paths)-max)
+}
+
+// RestoreWorkspace recreates an archived workspace's worktree from its
+// branch, re-copies .env and copy_files, runs post_create hooks and starts
+// the session, resuming the recorded agent conversation.
+func (m *Manager) RestoreWorkspace(ctx context.Context, id string, opts RestoreOptions) (Workspace, error) {
+	if err := m.checkDepsByName("git", "tmux"); err != nil {
+		return Workspace{}, err
+	}
+
+	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
+	if err != nil {
+		return Workspace{}, err
+	}
+	if !ws.Archived {
+		return Workspace{}, fmt.Errorf("workspace %s is not archived", resolvedID)
+	}
+	if _, err := git.ValidateRepo(ws.RepoPath); err != nil {
+		return Workspace{}, err
+	}
+
+	rc := m.existingRepoConfig(ws)
+	if err := m.checkAgentDeps(rc); err != nil {
+		return Workspace{}, err
+	}
+
+	rb := rollback{}
+
+	// The local branch may have been cleaned up while archived; origin
+	// still has it if it was pushed.
+	if exists, _ := git.BranchExists(ws.RepoPath, ws.Branch); !exists {
+		if _, err := git.CheckoutExistingBranch(ws.RepoPath, ws.Branch, "", true); err != nil {
+			return Workspace{}, fmt.Errorf("branch %s is gone: %w", ws.Branch, err)
+		}
+	}
+
+	// Forget any stale record of the old worktree before re-adding it.
+	if err := git.PruneWorktrees(ws.RepoPath); err != nil {
+		return Workspace{}, err
+	}
+	if err := git.CreateWorktree(ws.RepoPath, ws.WorktreePath, ws.Branch); err != nil {
+		return Workspace{}, err
+	}
+	rb.Add(func() { _ = git.RemoveWorktree(ws.RepoPath, ws.WorktreePath, true) })
+
+	if _, err := copyRepoFiles(ws.RepoPath, ws.WorktreePath, copyEntries(rc)); err != nil {
+		rb.Run()
+		return Workspace{}, err
+	}
+
+	if err := m.runHooks(ctx, hooks.PostCreate, rc.Hooks.PostCreate, ws.WorktreePath, resolvedID, ws); err != nil {
+		rb.Run()
+		return Workspace{}, err
+	}
+
+	launch := m.claudeLaunch(ws, rc, !opts.NoResume)
+	if err := m.bootstrapSession(ctx, ws.TmuxSession, ws.WorktreePath, rc, launch); err != nil {
+		rb.Run()
+		return Workspace{}, err
+	}
+	rb.Add(func() { _ = m.tmux.KillSession(ws.TmuxSession) })
+
+	now := time.Now().UTC()
+	ws.Archived = false
+	ws.ArchivedAt = time.Time{}
+	ws.LastAccessedAt = now
+	if launch.claudeSession != "" {
+		ws.ClaudeSession = launch.claudeSession
+	}
+	if err := m.regStore.Update(ctx, func(reg *Registry) error {
+		if _, ok := reg.Workspaces[resolvedID]; !ok {
+			return fmt.Errorf("workspace %s not found", resolvedID)
+		}
+		reg.Workspaces[resolvedID] = ws
+		return nil
+	}); err != nil {
+		rb.Run()
+		return Workspace{}, err
+	}
+
+	if !opts.NoAttach && term.IsTerminal(int(os.Stdout.Fd())) {
+		if err := m.tmux.AttachSession(ws.TmuxSession); err != nil {
+			return ws, err
+		}
+	}
+	return ws, nil
+}
@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveAndRestoreWorkspace(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	if err := os.WriteFile(filepath.Join(repoPath, ".env"), []byte("SECRET=1\n"), 0o644); err != nil {
+		t.Fatalf("write .env: %v", err)
+	}
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	ctx := context.Background()
+	id := WorkspaceID(repoName, "feature/pause")
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/pause", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	// Uncommitted work blocks archiving unless forced.
+	scratch := filepath.Join(ws.WorktreePath, "scratch.txt")
+	if err := os.WriteFile(scratch, []byte("wip"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := mgr.ArchiveWorkspace(ctx, id, ArchiveOptions{}); err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Fatalf("expected uncommitted changes to block archiving, got %v", err)
+	}
+	runGitCmd(t, ws.WorktreePath, "add", "scratch.txt")
+	runGitCmd(t, ws.WorktreePath, "commit", "-m", "wip")
+
+	saveConversation(t, ws.WorktreePath, ws.ClaudeSession, time.Now())
+
+	if err := mgr.ArchiveWorkspace(ctx, id, ArchiveOptions{}); err != nil {
+		t.Fatalf("ArchiveWorkspace: %v", err)
+	}
+	if _, err := os.Stat(ws.WorktreePath); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree to be removed, got %v", err)
+	}
+	if tmuxStub.sessions[ws.TmuxSession] {
+		t.Fatal("expected session to be killed")
+	}
+	reg, err := mgr.regStore.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	archived := reg.Workspaces[id]
+	if !archived.Archived || archived.ArchivedAt.IsZero() || archived.ClaudeSession != ws.ClaudeSession {
+		t.Fatalf("unexpected archived record: %+v", archived)
+	}
+	runGitCmd(t, repoPath, "rev-parse", "--verify", "feature/pause")
+
+	if err := mgr.OpenWorkspace(ctx, id, OpenOptions{}); !errors.Is(err, ErrWorkspaceArchived) {
+		t.Fatalf("expected opening an archived workspace to fail, got %v", err)
+	}
+	if err := mgr.ArchiveWorkspace(ctx, id, ArchiveOptions{}); err == nil {
+		t.Fatal("expected archiving twice to fail")
+	}
+	if issues, err := mgr.Doctor(ctx, DoctorOptions{}); err != nil || len(issues) != 0 {
+		t.Fatalf("expected doctor to accept an archived workspace, got %+v (%v)", issues, err)
+	}
+
+	tmuxStub.sent = nil
+	restored, err := mgr.RestoreWorkspace(ctx, id, RestoreOptions{NoAttach: true})
+	if err != nil {
+		t.Fatalf("RestoreWorkspace: %v", err)
+	}
+	if restored.Archived || !restored.ArchivedAt.IsZero() {
+		t.Fatalf("expected archived state to be cleared: %+v", restored)
+	}
+	if data, err := os.ReadFile(scratch); err != nil || string(data) != "wip" {
+		t.Fatalf("expected committed work in the restored worktree, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.WorktreePath, ".env")); err != nil {
+		t.Fatalf("expected .env to be copied again: %v", err)
+	}
+	if !tmuxStub.sessions[ws.TmuxSession] {
+		t.Fatal("expected session to be recreated")
+	}
+	if keys := tmuxStub.sent[ws.TmuxSession+":0.0"]; len(keys) != 1 || !strings.HasSuffix(keys[0], "--resume "+ws.ClaudeSession) {
+		t.Fatalf("expected the archived conversation to be resumed, got %v", keys)
+	}
+
+	if _, err := mgr.RestoreWorkspace(ctx, id, RestoreOptions{NoAttach: true}); err == nil {
+		t.Fatal("expected restoring a live workspace to fail")
+	}
+}
@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/ccw/ccw/internal/claude"
+	"github.com/ccw/ccw/internal/config"
+)
+
+// agentLaunch says how the agents in a new tmux session start.
+type agentLaunch struct {
+	resume bool
+	// claudeSession is the conversation claude starts (via --session-id) or
+	// resumes. Resuming with no ID continues the most recent conversation in
+	// the worktree.
+	claudeSession string
+	// prompt is the first message for a new workspace's agent.
+	prompt string
+}
+
+// usesClaude reports whether the repo's layout has a claude pane.
+func (m *Manager) usesClaude(rc config.RepoConfig) bool {
+	for _, a := range m.layoutAgents(rc) {
+		if a.Name() == "claude" {
+			return true
+		}
+	}
+	return false
+}
+
+// claudeLaunch decides which conversation a reopened workspace gets. In
+// order of preference: the recorded conversation, the most recent one
+// stored for the worktree, `--continue` when claude's storage can't be
+// read, or a fresh conversation. Anything short of the recorded
+// conversation is reported on m.out.
+func (m *Manager) claudeLaunch(ws Workspace, rc config.RepoConfig, resume bool) agentLaunch {
+	if !m.usesClaude(rc) {
+		return agentLaunch{resume: resume, claudeSession: ws.ClaudeSession}
+	}
+	if !resume {
+		return agentLaunch{claudeSession: claude.NewSessionID()}
+	}
+
+	exists, err := claude.SessionExists(ws.WorktreePath, ws.ClaudeSession)
+	if err == nil && exists {
+		return agentLaunch{resume: true, claudeSession: ws.ClaudeSession}
+	}
+	var latest string
+	if err == nil {
+		latest, err = claude.LatestSession(ws.WorktreePath)
+	}
+	if err != nil {
+		fmt.Fprintf(m.out, "warning: cannot read Claude's saved conversations (%v); continuing the most recent one\n", err)
+		return agentLaunch{resume: true}
+	}
+
+	if latest != "" {
+		if claude.IsSessionID(ws.ClaudeSession) {
+			fmt.Fprintf(m.out, "warning: Claude conversation %s not found; resuming the most recent one in the worktree\n", ws.ClaudeSession)
+		}
+		return agentLaunch{resume: true, claudeSession: latest}
+	}
+
+	fmt.Fprintln(m.out, "warning: no saved Claude conversation for this workspace; starting a new one")
+	id := ws.ClaudeSession
+	if !claude.IsSessionID(id) {
+		id = claude.NewSessionID()
+	}
+	return agentLaunch{claudeSession: id}
+}
+
+// discoverClaudeSession finds the conversation to record for a worktree ccw
+// didn't start claude in, such as an adopted one.
+func discoverClaudeSession(worktreePath string) string {
+	id, _ := claude.LatestSession(worktreePath)
+	return id
+}
@@ -0,0 +1,109 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccw/ccw/internal/claude"
+)
+
+// saveConversation fakes claude having stored a conversation for a worktree.
+func saveConversation(t *testing.T, worktreePath, id string, modTime time.Time) {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(worktreePath)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, resolved)
+	dir := filepath.Join(os.Getenv("CLAUDE_CONFIG_DIR"), "projects", name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	path := filepath.Join(dir, id+".jsonl")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write conversation: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestOpenWorkspaceResumesClaudeConversation(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	var out bytes.Buffer
+	mgr.SetOutput(&out)
+	ctx := context.Background()
+	id := WorkspaceID(repoName, "feature/test")
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/test", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if !claude.IsSessionID(ws.ClaudeSession) {
+		t.Fatalf("expected a conversation UUID, got %q", ws.ClaudeSession)
+	}
+	firstPane := ws.TmuxSession + ":0.0"
+	if keys := tmuxStub.sent[firstPane]; len(keys) != 1 || !strings.Contains(keys[0], "--session-id "+ws.ClaudeSession) {
+		t.Fatalf("expected claude to start with the recorded id, got %v", keys)
+	}
+
+	reopen := func() string {
+		t.Helper()
+		delete(tmuxStub.sessions, ws.TmuxSession)
+		tmuxStub.sent = nil
+		out.Reset()
+		if err := mgr.OpenWorkspace(ctx, id, OpenOptions{ResumeClaude: true, FocusExisting: true}); err != nil {
+			t.Fatalf("OpenWorkspace: %v", err)
+		}
+		keys := tmuxStub.sent[firstPane]
+		if len(keys) != 1 {
+			t.Fatalf("expected one command in the claude pane, got %v", keys)
+		}
+		return keys[0]
+	}
+
+	// Nothing saved yet: a fresh conversation under the same id.
+	if cmd := reopen(); !strings.Contains(cmd, "--session-id "+ws.ClaudeSession) || !strings.Contains(out.String(), "starting a new one") {
+		t.Fatalf("expected a fresh session with a warning, got %q / %q", cmd, out.String())
+	}
+
+	// The recorded conversation exists: resume exactly that one.
+	saveConversation(t, ws.WorktreePath, ws.ClaudeSession, time.Now().Add(-time.Hour))
+	saveConversation(t, ws.WorktreePath, claude.NewSessionID(), time.Now().Add(-2*time.Hour))
+	if cmd := reopen(); !strings.HasSuffix(cmd, "--resume "+ws.ClaudeSession) || out.Len() != 0 {
+		t.Fatalf("expected to resume the recorded conversation, got %q / %q", cmd, out.String())
+	}
+
+	// The recorded conversation is gone: fall back to the latest one and
+	// remember it.
+	if err := mgr.regStore.Update(ctx, func(reg *Registry) error {
+		w := reg.Workspaces[id]
+		w.ClaudeSession = claude.NewSessionID()
+		reg.Workspaces[id] = w
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if cmd := reopen(); !strings.HasSuffix(cmd, "--resume "+ws.ClaudeSession) || !strings.Contains(out.String(), "not found") {
+		t.Fatalf("expected to fall back to the latest conversation, got %q / %q", cmd, out.String())
+	}
+	reg, err := mgr.regStore.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := reg.Workspaces[id].ClaudeSession; got != ws.ClaudeSession {
+		t.Fatalf("expected the resumed conversation to be recorded, got %q", got)
+	}
+}
@@ -0,0 +1,238 @@
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/ccw/ccw/internal/config"
+)
+
+// CopiedPath describes one path carried over from the main checkout into a
+// new worktree.
+type CopiedPath struct {
+	// Path is relative to the repo root.
+	Path string
+	// Mode is config.CopyModeCopy or config.CopyModeSymlink.
+	Mode  string
+	IsDir bool
+	// Files is the number of files copied. Zero for symlinks.
+	Files int
+}
+
+// copyRepoFiles brings the copy_files entries from repoPath into
+// worktreePath. Literal paths that don't exist are skipped, as are glob
+// patterns with no matches. Nothing already in the worktree is replaced,
+// by a copy or a symlink, so tracked files always win.
+func copyRepoFiles(repoPath, worktreePath string, entries []config.CopyFile) ([]CopiedPath, error) {
+	var copied []CopiedPath
+	done := make(map[string]bool)
+
+	for _, entry := range entries {
+		matches, err := expandCopyPattern(repoPath, entry.Path)
+		if err != nil {
+			return copied, fmt.Errorf("copy %s: %w", entry.Path, err)
+		}
+
+		for _, rel := range matches {
+			if done[rel] || underAny(rel, done) {
+				continue
+			}
+			src := filepath.Join(repoPath, filepath.FromSlash(rel))
+			dst := filepath.Join(worktreePath, filepath.FromSlash(rel))
+
+			info, err := os.Stat(src)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return copied, fmt.Errorf("copy %s: %w", rel, err)
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				return copied, fmt.Errorf("create dir for %s: %w", rel, err)
+			}
+
+			existing, err := os.Lstat(dst)
+			exists := err == nil
+			// A directory is merged into one already there, file by file;
+			// anything else in the way is left alone.
+			if exists && !(info.IsDir() && existing.IsDir() && !entry.Symlink()) {
+				continue
+			}
+
+			result := CopiedPath{Path: rel, Mode: config.CopyModeCopy, IsDir: info.IsDir()}
+			if entry.Symlink() {
+				if err := os.Symlink(src, dst); err != nil {
+					return copied, fmt.Errorf("link %s: %w", rel, err)
+				}
+				result.Mode = config.CopyModeSymlink
+			} else if info.IsDir() {
+				n, err := copyDir(src, dst)
+				if err != nil {
+					return copied, fmt.Errorf("copy %s: %w", rel, err)
+				}
+				if exists && n == 0 {
+					continue
+				}
+				result.Files = n
+			} else {
+				if err := copyFile(src, dst, info.Mode()); err != nil {
+					return copied, fmt.Errorf("copy %s: %w", rel, err)
+				}
+				result.Files = 1
+			}
+
+			done[rel] = true
+			copied = append(copied, result)
+		}
+	}
+
+	return copied, nil
+}
+
+// copyEntries returns the copy_files list with .env, which is always copied,
+// in front unless the repo config already mentions it.
+func copyEntries(rc config.RepoConfig) []config.CopyFile {
+	for _, cf := range rc.CopyFiles {
+		if path.Clean(filepath.ToSlash(cf.Path)) == ".env" {
+			return rc.CopyFiles
+		}
+	}
+	return append([]config.CopyFile{{Path: ".env"}}, rc.CopyFiles...)
+}
+
+// expandCopyPattern returns the slash-separated repo-relative paths matching
+// pattern, sorted. A pattern without glob syntax is returned as is. Matches
+// inside .git are dropped.
+func expandCopyPattern(repoPath, pattern string) ([]string, error) {
+	pattern = path.Clean(filepath.ToSlash(pattern))
+	if !strings.ContainsAny(pattern, "*?[{") {
+		return []string{pattern}, nil
+	}
+	if !doublestar.ValidatePattern(pattern) {
+		return nil, fmt.Errorf("invalid pattern")
+	}
+
+	matches, err := doublestar.Glob(os.DirFS(repoPath), pattern)
+	if err != nil {
+		return nil, err
+	}
+	out := matches[:0]
+	for _, m := range matches {
+		if m == ".git" || strings.HasPrefix(m, ".git/") {
+			continue
+		}
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// underAny reports whether rel sits inside a directory that was already
+// handled, so "config/**" doesn't copy a file again after copying its dir.
+func underAny(rel string, done map[string]bool) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if done[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// carriedOver reports whether rel (slash-separated, repo-relative) was put in
+// the worktree by copy_files, directly or inside a copied directory.
+func carriedOver(rel string, entries []config.CopyFile) bool {
+	for _, entry := range entries {
+		pattern := path.Clean(filepath.ToSlash(entry.Path))
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyDir recursively copies src to dst, keeping file modes and recreating
+// symlinks as symlinks. Files and links already in dst are kept. Returns
+// the number of files copied.
+func copyDir(src, dst string) (int, error) {
+	files := 0
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		existing, err := os.Lstat(target)
+		if err == nil {
+			if d.IsDir() && !existing.IsDir() {
+				// Something else is in the way; keep it and skip the dir.
+				return filepath.SkipDir
+			}
+			if !d.IsDir() {
+				return nil
+			}
+		}
+		switch {
+		case d.IsDir():
+			if existing != nil {
+				return nil
+			}
+			if err := os.MkdirAll(target, info.Mode().Perm()|0o700); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm()|0o700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			if err := copyFile(p, target, info.Mode()); err != nil {
+				return err
+			}
+			files++
+		}
+		return nil
+	})
+	return files, err
+}
+
+// copyFile copies src to dst, giving dst the permission bits of mode.
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile only applies the mode to new files and is subject to umask.
+	return os.Chmod(dst, mode.Perm())
+}
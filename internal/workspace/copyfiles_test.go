@@ -0,0 +1,149 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccw/ccw/internal/config"
+)
+
+func writeTestFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatalf("chmod %s: %v", path, err)
+	}
+}
+
+func TestCopyRepoFilesGlobsAndDirectories(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	writeTestFile(t, filepath.Join(repo, ".env"), "A=1", 0o600)
+	writeTestFile(t, filepath.Join(repo, ".vscode", "settings.json"), "{}", 0o644)
+	writeTestFile(t, filepath.Join(repo, ".vscode", "tasks", "build.sh"), "#!/bin/sh", 0o755)
+	writeTestFile(t, filepath.Join(repo, "config", "dev.local.json"), "{}", 0o644)
+	writeTestFile(t, filepath.Join(repo, "config", "nested", "prod.local.json"), "{}", 0o644)
+	writeTestFile(t, filepath.Join(repo, "config", "shared.json"), "{}", 0o644)
+
+	copied, err := copyRepoFiles(repo, worktree, []config.CopyFile{
+		{Path: ".env"},
+		{Path: ".vscode"},
+		{Path: "config/**/*.local.json"},
+		{Path: "missing.txt"},
+	})
+	if err != nil {
+		t.Fatalf("copyRepoFiles: %v", err)
+	}
+
+	if len(copied) != 4 {
+		t.Fatalf("expected 4 copied paths, got %+v", copied)
+	}
+	if !copied[1].IsDir || copied[1].Files != 2 {
+		t.Fatalf("expected .vscode to be copied recursively, got %+v", copied[1])
+	}
+
+	info, err := os.Stat(filepath.Join(worktree, ".vscode", "tasks", "build.sh"))
+	if err != nil {
+		t.Fatalf("stat build.sh: %v", err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected mode 0755, got %v", info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Join(worktree, ".env"))
+	if err != nil {
+		t.Fatalf("stat .env: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	if _, err := os.Stat(filepath.Join(worktree, "config", "nested", "prod.local.json")); err != nil {
+		t.Fatalf("expected nested glob match to be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "config", "shared.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected non-matching file to be skipped, err=%v", err)
+	}
+}
+
+func TestCopyRepoFilesSymlinkMode(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	writeTestFile(t, filepath.Join(repo, "node_modules", ".cache", "blob"), "x", 0o644)
+	writeTestFile(t, filepath.Join(repo, "tracked.txt"), "main", 0o644)
+	writeTestFile(t, filepath.Join(worktree, "tracked.txt"), "worktree", 0o644)
+
+	copied, err := copyRepoFiles(repo, worktree, []config.CopyFile{
+		{Path: "node_modules/.cache", Mode: config.CopyModeSymlink},
+		{Path: "tracked.txt", Mode: config.CopyModeSymlink},
+	})
+	if err != nil {
+		t.Fatalf("copyRepoFiles: %v", err)
+	}
+	if len(copied) != 1 || copied[0].Mode != config.CopyModeSymlink {
+		t.Fatalf("expected only the cache to be linked, got %+v", copied)
+	}
+
+	target, err := os.Readlink(filepath.Join(worktree, "node_modules", ".cache"))
+	if err != nil {
+		t.Fatalf("readlink: %v", err)
+	}
+	if target != filepath.Join(repo, "node_modules", ".cache") {
+		t.Fatalf("unexpected link target %q", target)
+	}
+
+	data, err := os.ReadFile(filepath.Join(worktree, "tracked.txt"))
+	if err != nil {
+		t.Fatalf("read tracked.txt: %v", err)
+	}
+	if string(data) != "worktree" {
+		t.Fatalf("expected existing worktree file to be left alone, got %q", data)
+	}
+}
+
+func TestCopyRepoFilesKeepsTrackedFiles(t *testing.T) {
+	repo := t.TempDir()
+	worktree := t.TempDir()
+
+	writeTestFile(t, filepath.Join(repo, "config", "app.json"), "main", 0o644)
+	writeTestFile(t, filepath.Join(repo, "config", "dev.local.json"), "{}", 0o644)
+	writeTestFile(t, filepath.Join(repo, "Makefile"), "main", 0o644)
+	writeTestFile(t, filepath.Join(worktree, "config", "app.json"), "branch", 0o644)
+	writeTestFile(t, filepath.Join(worktree, "Makefile"), "branch", 0o644)
+
+	copied, err := copyRepoFiles(repo, worktree, []config.CopyFile{{Path: "config"}, {Path: "Makefile"}})
+	if err != nil {
+		t.Fatalf("copyRepoFiles: %v", err)
+	}
+	if len(copied) != 1 || copied[0].Path != "config" || copied[0].Files != 1 {
+		t.Fatalf("expected only the untracked config file copied, got %+v", copied)
+	}
+	for _, rel := range []string{"config/app.json", "Makefile"} {
+		data, err := os.ReadFile(filepath.Join(worktree, filepath.FromSlash(rel)))
+		if err != nil || string(data) != "branch" {
+			t.Fatalf("expected %s left alone, got %q, %v", rel, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "config", "dev.local.json")); err != nil {
+		t.Fatalf("expected untracked file copied: %v", err)
+	}
+}
+
+func TestCopyEntriesAddsEnv(t *testing.T) {
+	entries := copyEntries(config.RepoConfig{CopyFiles: []config.CopyFile{{Path: "a"}}})
+	if len(entries) != 2 || entries[0].Path != ".env" {
+		t.Fatalf("expected .env first, got %+v", entries)
+	}
+
+	entries = copyEntries(config.RepoConfig{CopyFiles: []config.CopyFile{{Path: "./.env", Mode: config.CopyModeSymlink}}})
+	if len(entries) != 1 {
+		t.Fatalf("expected configured .env to replace the default, got %+v", entries)
+	}
+}
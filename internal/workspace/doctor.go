@@ -0,0 +1,387 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+// Doctor issue codes.
+const (
+	// IssueMissingRepo: the workspace's repo is gone or no longer a git repo.
+	IssueMissingRepo = "missing_repo"
+	// IssueMissingWorktree: the worktree directory was deleted.
+	IssueMissingWorktree = "missing_worktree"
+	// IssueBrokenWorktree: the directory exists but git doesn't list it as a
+	// worktree of the repo (usually after a move).
+	IssueBrokenWorktree = "broken_worktree"
+	// IssueBranchMismatch: the worktree has a different branch checked out.
+	IssueBranchMismatch = "branch_mismatch"
+	// IssueMissingRemoteBranch: the branch is gone from origin, typically
+	// merged and deleted, then dropped locally by `git fetch --prune`.
+	IssueMissingRemoteBranch = "missing_remote_branch"
+	// IssuePrunableWorktree: git still records a worktree whose directory is
+	// gone.
+	IssuePrunableWorktree = "prunable_worktree"
+	// IssueOrphanWorktreeDir: a directory under the worktree root with no
+	// registry entry.
+	IssueOrphanWorktreeDir = "orphan_worktree_dir"
+	// IssueArchivedBranchMissing: an archived workspace's branch is gone, so
+	// it can't be restored.
+	IssueArchivedBranchMissing = "archived_branch_missing"
+	// IssueOrphanSession: a tmux session running in the worktree root that
+	// no workspace owns.
+	IssueOrphanSession = "orphan_session"
+)
+
+// DoctorIssue is one inconsistency between the registry, git and tmux.
+type DoctorIssue struct {
+	Code      string `json:"code"`
+	Workspace string `json:"workspace,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Session   string `json:"session,omitempty"`
+	Message   string `json:"message"`
+	// Fix describes what --fix does about the issue. Empty when the issue
+	// needs a human.
+	Fix      string `json:"fix,omitempty"`
+	Fixed    bool   `json:"fixed"`
+	FixError string `json:"fix_error,omitempty"`
+
+	fix func(ctx context.Context) error
+}
+
+type DoctorOptions struct {
+	// Fix applies the fix for every issue that has one.
+	Fix bool
+}
+
+// Doctor cross-checks every registered workspace against the filesystem,
+// git and tmux, and looks for worktree directories and sessions that the
+// registry has lost track of. With opts.Fix set, each fixable issue is
+// repaired and marked Fixed, or given a FixError.
+func (m *Manager) Doctor(ctx context.Context, opts DoctorOptions) ([]DoctorIssue, error) {
+	if err := m.checkDepsByName("git"); err != nil {
+		return nil, err
+	}
+
+	reg, err := m.regStore.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(reg.Workspaces))
+	for id := range reg.Workspaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var issues []DoctorIssue
+	worktrees := map[string][]git.Worktree{}
+	registeredPaths := map[string]bool{}
+	registeredSessions := map[string]bool{}
+
+	for _, id := range ids {
+		ws := reg.Workspaces[id]
+		registeredPaths[canonicalPath(ws.WorktreePath)] = true
+		registeredSessions[ws.TmuxSession] = true
+
+		if _, err := git.ValidateRepo(ws.RepoPath); err != nil {
+			issues = append(issues, m.unregisterIssue(IssueMissingRepo, id, ws,
+				fmt.Sprintf("repo %s is missing", ws.RepoPath)))
+			continue
+		}
+
+		if ws.Archived {
+			if issue, ok := m.checkArchived(id, ws); ok {
+				issues = append(issues, issue)
+			}
+			continue
+		}
+
+		list, ok := worktrees[ws.RepoPath]
+		if !ok {
+			list, err = git.ListWorktrees(ws.RepoPath)
+			if err != nil {
+				return nil, fmt.Errorf("list worktrees of %s: %w", ws.Repo, err)
+			}
+			worktrees[ws.RepoPath] = list
+		}
+
+		if issue, ok := m.checkWorktree(id, ws, list); ok {
+			issues = append(issues, issue)
+			continue
+		}
+
+		if !ws.ExternalBranch && ws.PRRef == "" && !git.RemoteTrackingBranchExists(ctx, ws.RepoPath, ws.Branch) {
+			issues = append(issues, DoctorIssue{
+				Code:      IssueMissingRemoteBranch,
+				Workspace: id,
+				Path:      ws.WorktreePath,
+				Message:   fmt.Sprintf("branch %s is gone from origin; if it was merged, remove the workspace with `ccw rm %s`", ws.Branch, id),
+			})
+		}
+	}
+
+	repoPaths := make([]string, 0, len(worktrees))
+	for repoPath := range worktrees {
+		repoPaths = append(repoPaths, repoPath)
+	}
+	sort.Strings(repoPaths)
+	for _, repoPath := range repoPaths {
+		for _, wt := range worktrees[repoPath] {
+			if !wt.Prunable || registeredPaths[canonicalPath(wt.Path)] {
+				continue
+			}
+			issues = append(issues, DoctorIssue{
+				Code:    IssuePrunableWorktree,
+				Path:    wt.Path,
+				Message: fmt.Sprintf("git still records deleted worktree %s", wt.Path),
+				Fix:     "prune the worktree record",
+				fix: func(context.Context) error {
+					return git.PruneWorktrees(repoPath)
+				},
+			})
+		}
+	}
+
+	worktreeRoot := filepath.Join(m.root, "worktrees")
+	orphans, err := m.orphanWorktreeDirs(ctx, worktreeRoot, registeredPaths)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, orphans...)
+
+	// tmux is optional here: with no server running there are no sessions
+	// to be orphaned.
+	if sessions, err := m.tmux.ListSessions(); err == nil {
+		root := canonicalPath(worktreeRoot) + string(filepath.Separator)
+		for _, s := range sessions {
+			if registeredSessions[s.Name] || s.Path == "" {
+				continue
+			}
+			if !strings.HasPrefix(canonicalPath(s.Path)+string(filepath.Separator), root) {
+				continue
+			}
+			name := s.Name
+			issues = append(issues, DoctorIssue{
+				Code:    IssueOrphanSession,
+				Path:    s.Path,
+				Session: name,
+				Message: fmt.Sprintf("tmux session %s is not owned by any workspace", name),
+				Fix:     "kill the session",
+				fix: func(context.Context) error {
+					return m.tmux.KillSession(name)
+				},
+			})
+		}
+	}
+
+	if opts.Fix {
+		for i := range issues {
+			issue := &issues[i]
+			if issue.fix == nil {
+				continue
+			}
+			if err := issue.fix(ctx); err != nil {
+				issue.FixError = err.Error()
+				continue
+			}
+			issue.Fixed = true
+		}
+	}
+
+	return issues, nil
+}
+
+// checkWorktree compares a workspace with git's view of its worktree.
+func (m *Manager) checkWorktree(id string, ws Workspace, list []git.Worktree) (DoctorIssue, bool) {
+	path := canonicalPath(ws.WorktreePath)
+	var found *git.Worktree
+	for i := range list {
+		if canonicalPath(list[i].Path) == path {
+			found = &list[i]
+			break
+		}
+	}
+
+	if _, err := os.Stat(ws.WorktreePath); os.IsNotExist(err) {
+		return m.missingWorktreeIssue(id, ws), true
+	}
+
+	if found == nil {
+		return DoctorIssue{
+			Code:      IssueBrokenWorktree,
+			Workspace: id,
+			Path:      ws.WorktreePath,
+			Message:   fmt.Sprintf("%s is not a worktree of %s", ws.WorktreePath, ws.RepoPath),
+			Fix:       "repair the worktree link",
+			fix: func(context.Context) error {
+				return git.RepairWorktree(ws.RepoPath, ws.WorktreePath)
+			},
+		}, true
+	}
+
+	if found.Branch != ws.Branch {
+		current := found.Branch
+		if found.Detached {
+			current = "a detached HEAD"
+		}
+		return DoctorIssue{
+			Code:      IssueBranchMismatch,
+			Workspace: id,
+			Path:      ws.WorktreePath,
+			Message:   fmt.Sprintf("worktree is on %s, expected %s", current, ws.Branch),
+		}, true
+	}
+
+	return DoctorIssue{}, false
+}
+
+// checkArchived makes sure an archived workspace can still be restored:
+// its branch has to exist locally or on origin.
+func (m *Manager) checkArchived(id string, ws Workspace) (DoctorIssue, bool) {
+	if local, _ := git.BranchExists(ws.RepoPath, ws.Branch); local {
+		return DoctorIssue{}, false
+	}
+	if remote, _ := git.RemoteBranchExists(ws.RepoPath, "origin", ws.Branch); remote {
+		return DoctorIssue{}, false
+	}
+	return m.unregisterIssue(IssueArchivedBranchMissing, id, ws,
+		fmt.Sprintf("archived workspace can't be restored: branch %s is gone", ws.Branch)), true
+}
+
+// missingWorktreeIssue recreates the worktree when its branch still exists
+// locally or on origin, and drops the workspace otherwise.
+func (m *Manager) missingWorktreeIssue(id string, ws Workspace) DoctorIssue {
+	local, _ := git.BranchExists(ws.RepoPath, ws.Branch)
+	remote := false
+	if !local {
+		remote, _ = git.RemoteBranchExists(ws.RepoPath, "origin", ws.Branch)
+	}
+	if !local && !remote {
+		return m.unregisterIssue(IssueMissingWorktree, id, ws,
+			fmt.Sprintf("worktree %s and branch %s are gone", ws.WorktreePath, ws.Branch))
+	}
+
+	return DoctorIssue{
+		Code:      IssueMissingWorktree,
+		Workspace: id,
+		Path:      ws.WorktreePath,
+		Message:   fmt.Sprintf("worktree %s is missing", ws.WorktreePath),
+		Fix:       "recreate the worktree from branch " + ws.Branch,
+		fix: func(context.Context) error {
+			if err := git.PruneWorktrees(ws.RepoPath); err != nil {
+				return err
+			}
+			if !local {
+				if _, err := git.CheckoutExistingBranch(ws.RepoPath, ws.Branch, "", true); err != nil {
+					return err
+				}
+			}
+			return git.CreateWorktree(ws.RepoPath, ws.WorktreePath, ws.Branch)
+		},
+	}
+}
+
+func (m *Manager) unregisterIssue(code, id string, ws Workspace, message string) DoctorIssue {
+	return DoctorIssue{
+		Code:      code,
+		Workspace: id,
+		Path:      ws.WorktreePath,
+		Session:   ws.TmuxSession,
+		Message:   message,
+		Fix:       "unregister the workspace",
+		fix: func(ctx context.Context) error {
+			if alive, err := m.tmux.SessionExists(ws.TmuxSession); err == nil && alive {
+				_ = m.tmux.KillSession(ws.TmuxSession)
+			}
+			return m.regStore.Update(ctx, func(reg *Registry) error {
+				reg.Remove(id)
+				return nil
+			})
+		},
+	}
+}
+
+// orphanWorktreeDirs reports directories under the worktree root that no
+// workspace points at. Worktrees with a branch are re-registered, empty
+// directories removed, and anything else left for a human to look at.
+func (m *Manager) orphanWorktreeDirs(ctx context.Context, worktreeRoot string, registered map[string]bool) ([]DoctorIssue, error) {
+	entries, err := os.ReadDir(worktreeRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var issues []DoctorIssue
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(worktreeRoot, e.Name())
+		if registered[canonicalPath(path)] {
+			continue
+		}
+
+		if empty, _ := isEmptyDir(path); empty {
+			issues = append(issues, DoctorIssue{
+				Code:    IssueOrphanWorktreeDir,
+				Path:    path,
+				Message: fmt.Sprintf("%s is an empty, unregistered directory", path),
+				Fix:     "remove the directory",
+				fix: func(context.Context) error {
+					return os.Remove(path)
+				},
+			})
+			continue
+		}
+
+		repoPath, branch, err := git.WorktreeOwner(path)
+		if err != nil || branch == "" || canonicalPath(repoPath) == canonicalPath(path) {
+			issues = append(issues, DoctorIssue{
+				Code:    IssueOrphanWorktreeDir,
+				Path:    path,
+				Message: fmt.Sprintf("%s is not registered and is not a worktree on a branch", path),
+			})
+			continue
+		}
+
+		repo := filepath.Base(repoPath)
+		id := WorkspaceID(repo, branch)
+		ws := Workspace{
+			Repo:           repo,
+			RepoPath:       repoPath,
+			Branch:         branch,
+			WorktreePath:   path,
+			ClaudeSession:  discoverClaudeSession(path),
+			TmuxSession:    e.Name(),
+			ExternalBranch: true,
+		}
+		issues = append(issues, DoctorIssue{
+			Code:      IssueOrphanWorktreeDir,
+			Workspace: id,
+			Path:      path,
+			Message:   fmt.Sprintf("worktree %s (branch %s) is not registered", path, branch),
+			Fix:       "re-register it as " + id,
+			fix: func(ctx context.Context) error {
+				return m.Adopt(ctx, []AdoptCandidate{{ID: id, Workspace: ws}})
+			},
+		})
+	}
+	return issues, nil
+}
+
+func isEmptyDir(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDoctorReportsAndFixesDrift(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/deleted", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "feature/healthy", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	// Worktree deleted by hand; its branch survives.
+	if err := os.RemoveAll(ws.WorktreePath); err != nil {
+		t.Fatalf("remove worktree: %v", err)
+	}
+	// A worktree in ccw's root that the registry lost track of.
+	lost := filepath.Join(mgr.root, "worktrees", "demo-lost")
+	runGitCmd(t, repoPath, "worktree", "add", "-b", "lost", lost)
+	// An empty leftover directory and a session nobody owns.
+	empty := filepath.Join(mgr.root, "worktrees", "leftover")
+	if err := os.MkdirAll(empty, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := tmuxStub.CreateSession("stray", lost, true); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	issues, err := mgr.Doctor(ctx, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	codes := map[string]int{}
+	for _, issue := range issues {
+		codes[issue.Code]++
+		if issue.Fixed {
+			t.Fatalf("nothing should be fixed without Fix: %+v", issue)
+		}
+	}
+	want := map[string]int{IssueMissingWorktree: 1, IssueOrphanWorktreeDir: 2, IssueOrphanSession: 1}
+	for code, n := range want {
+		if codes[code] != n {
+			t.Fatalf("expected %d %s issue(s), got %+v", n, code, issues)
+		}
+	}
+	if len(issues) != 4 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	issues, err = mgr.Doctor(ctx, DoctorOptions{Fix: true})
+	if err != nil {
+		t.Fatalf("Doctor fix: %v", err)
+	}
+	for _, issue := range issues {
+		if !issue.Fixed {
+			t.Fatalf("expected issue to be fixed: %+v", issue)
+		}
+	}
+
+	if _, err := os.Stat(ws.WorktreePath); err != nil {
+		t.Fatalf("expected worktree to be recreated: %v", err)
+	}
+	if _, err := os.Stat(empty); !os.IsNotExist(err) {
+		t.Fatalf("expected empty dir to be removed, got %v", err)
+	}
+	if _, ok := tmuxStub.sessions["stray"]; ok {
+		t.Fatal("expected stray session to be killed")
+	}
+	reg, err := mgr.regStore.Read(ctx)
+	if err != nil {
+		t.Fatalf("read registry: %v", err)
+	}
+	if adopted, ok := reg.Workspaces[WorkspaceID(repoName, "lost")]; !ok || adopted.WorktreePath != lost {
+		t.Fatalf("expected lost worktree to be re-registered, got %+v", reg.Workspaces)
+	}
+
+	issues, err = mgr.Doctor(ctx, DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	// The adopted branch was never pushed, which is only worth reporting for
+	// branches ccw created.
+	if len(issues) != 0 {
+		t.Fatalf("expected a clean bill of health, got %+v", issues)
+	}
+}
+
+func TestDoctorUnregistersWorkspaceWithoutBranch(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/gone", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	runGitCmd(t, repoPath, "worktree", "remove", "--force", ws.WorktreePath)
+	runGitCmd(t, repoPath, "branch", "-D", "feature/gone")
+	runGitCmd(t, repoPath, "push", "origin", "--delete", "feature/gone")
+
+	issues, err := mgr.Doctor(ctx, DoctorOptions{Fix: true})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Code != IssueMissingWorktree || !issues[0].Fixed {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	reg, err := mgr.regStore.Read(ctx)
+	if err != nil {
+		t.Fatalf("read registry: %v", err)
+	}
+	if len(reg.Workspaces) != 0 {
+		t.Fatalf("expected workspace to be unregistered, got %+v", reg.Workspaces)
+	}
+}
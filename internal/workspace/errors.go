@@ -0,0 +1,75 @@
+package workspace
+
+import (
+	"errors"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+// Stable error codes, reported by ErrorCode. Like event names they are part
+// of the --output=json contract.
+const (
+	CodeWorkspaceNotFound    = "workspace_not_found"
+	CodeAmbiguousWorkspace   = "ambiguous_workspace"
+	CodeWorkspaceExists      = "workspace_exists"
+	CodeWorkspaceArchived    = "workspace_archived"
+	CodeWorkspaceAlreadyOpen = "workspace_already_open"
+	CodeNotInWorkspace       = "not_in_workspace"
+	CodeInvalidArgument      = "invalid_argument"
+	CodeRepoNotFound         = "repo_not_found"
+	CodeBranchExists         = "branch_exists"
+	CodeRemoteBranchExists   = "remote_branch_exists"
+	CodeBranchNotFound       = "branch_not_found"
+	CodeBranchNotMerged      = "branch_not_merged"
+	CodeUnpushedCommits      = "unpushed_commits"
+	CodeMissingDependency    = "missing_dependency"
+	CodeHookFailed           = "hook_failed"
+	CodeUnsupportedVersion   = "unsupported_version"
+	CodeInternal             = "internal"
+)
+
+// Error attaches a stable code to an error. Its message is the wrapped
+// error's.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+func withCode(code string, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+// sentinelCodes maps sentinel errors, which are matched with errors.Is, to
+// their codes.
+var sentinelCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrWorkspaceArchived, CodeWorkspaceArchived},
+	{ErrWorkspaceAlreadyOpen, CodeWorkspaceAlreadyOpen},
+	{ErrNoCurrentWorkspace, CodeNotInWorkspace},
+	{ErrUnsupportedVersion, CodeUnsupportedVersion},
+	{git.ErrRepoNotFound, CodeRepoNotFound},
+	{git.ErrBranchExists, CodeBranchExists},
+	{git.ErrRemoteBranchFound, CodeRemoteBranchExists},
+	{git.ErrBranchNotFound, CodeBranchNotFound},
+	{git.ErrBranchNotMerged, CodeBranchNotMerged},
+}
+
+// ErrorCode returns the stable code for err, or CodeInternal when it has
+// none.
+func ErrorCode(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+	return CodeInternal
+}
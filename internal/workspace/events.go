@@ -0,0 +1,49 @@
+package workspace
+
+import "time"
+
+// Progress events reported by workspace operations. They are part of the
+// --output=json contract, so existing names must not change.
+const (
+	EventBranchCreated       = "branch_created"
+	EventBranchCheckedOut    = "branch_checked_out"
+	EventPushed              = "pushed"
+	EventWorktreeCreated     = "worktree_created"
+	EventFilesCopied         = "files_copied"
+	EventSessionStarted      = "session_started"
+	EventRegistered          = "registered"
+	EventRolledBack          = "rolled_back"
+	EventSessionKilled       = "session_killed"
+	EventWorktreeRemoved     = "worktree_removed"
+	EventBranchDeleted       = "branch_deleted"
+	EventRemoteBranchDeleted = "remote_branch_deleted"
+	EventUnregistered        = "unregistered"
+	EventRetargeted          = "retargeted"
+)
+
+// Event is a progress notification from a workspace operation.
+type Event struct {
+	Type      string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Workspace string    `json:"workspace,omitempty"`
+	Branch    string    `json:"branch,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Session   string    `json:"session,omitempty"`
+	// Parent is the new parent or base, for EventRetargeted.
+	Parent  string `json:"parent,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// SetEventHandler registers fn to receive progress events. Events are
+// delivered synchronously on the calling goroutine.
+func (m *Manager) SetEventHandler(fn func(Event)) {
+	m.onEvent = fn
+}
+
+func (m *Manager) emit(e Event) {
+	if m.onEvent == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	m.onEvent(e)
+}
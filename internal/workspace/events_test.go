@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+func recordEvents(mgr *Manager) *[]Event {
+	var events []Event
+	mgr.SetEventHandler(func(e Event) {
+		if e.Time.IsZero() {
+			panic("event without time")
+		}
+		events = append(events, e)
+	})
+	return &events
+}
+
+func eventTypes(events []Event) []string {
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestCreateAndRemoveWorkspaceEmitEvents(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	events := recordEvents(mgr)
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/events", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	want := []string{EventBranchCreated, EventPushed, EventWorktreeCreated, EventSessionStarted, EventRegistered}
+	if got := eventTypes(*events); !reflect.DeepEqual(got, want) {
+		t.Fatalf("create events = %v, want %v", got, want)
+	}
+	id := WorkspaceID(repoName, "feature/events")
+	for _, e := range *events {
+		if e.Workspace != id {
+			t.Fatalf("%s event for workspace %q, want %q", e.Type, e.Workspace, id)
+		}
+	}
+	if (*events)[2].Path != ws.WorktreePath {
+		t.Fatalf("worktree_created path = %q, want %q", (*events)[2].Path, ws.WorktreePath)
+	}
+
+	*events = nil
+	if err := mgr.RemoveWorkspace(context.Background(), id, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	want = []string{EventWorktreeRemoved, EventBranchDeleted, EventRemoteBranchDeleted, EventUnregistered, EventSessionKilled}
+	if got := eventTypes(*events); !reflect.DeepEqual(got, want) {
+		t.Fatalf("remove events = %v, want %v", got, want)
+	}
+}
+
+func TestCreateWorkspaceEmitsRolledBack(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	stub := newStubTmux()
+	stub.failCreate = true
+	mgr := newManagerForTest(t, reposRoot, stub)
+	events := recordEvents(mgr)
+
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/fail", CreateOptions{NoFetch: true, NoAttach: true}); err == nil {
+		t.Fatalf("expected error from tmux")
+	}
+	want := []string{EventBranchCreated, EventPushed, EventWorktreeCreated, EventRolledBack}
+	if got := eventTypes(*events); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "feature/a", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "feature/b", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	_, err := mgr.CreateWorkspace(ctx, repoName, "feature/a", CreateOptions{NoFetch: true, NoAttach: true})
+	if got := ErrorCode(err); got != CodeBranchExists {
+		t.Fatalf("duplicate branch: code %q (%v), want %q", got, err, CodeBranchExists)
+	}
+	_, err = mgr.CreateWorkspace(ctx, "missing", "feature/c", CreateOptions{NoFetch: true, NoAttach: true})
+	if got := ErrorCode(err); got != CodeRepoNotFound {
+		t.Fatalf("missing repo: code %q (%v), want %q", got, err, CodeRepoNotFound)
+	}
+	_, _, err = mgr.lookupWorkspace(ctx, "nope")
+	if got := ErrorCode(err); got != CodeWorkspaceNotFound {
+		t.Fatalf("unknown workspace: code %q (%v), want %q", got, err, CodeWorkspaceNotFound)
+	}
+	_, _, err = mgr.lookupWorkspace(ctx, "feature")
+	if got := ErrorCode(err); got != CodeAmbiguousWorkspace {
+		t.Fatalf("ambiguous workspace: code %q (%v), want %q", got, err, CodeAmbiguousWorkspace)
+	}
+
+	wrapped := fmt.Errorf("%s: %w", "demo/x", withCode(CodeUnpushedCommits, errors.New("unpushed")))
+	if got := ErrorCode(wrapped); got != CodeUnpushedCommits {
+		t.Fatalf("wrapped: code %q, want %q", got, CodeUnpushedCommits)
+	}
+	if got := ErrorCode(fmt.Errorf("create: %w", git.ErrBranchNotMerged)); got != CodeBranchNotMerged {
+		t.Fatalf("sentinel: code %q, want %q", got, CodeBranchNotMerged)
+	}
+	if got := ErrorCode(errors.New("boom")); got != CodeInternal {
+		t.Fatalf("plain error: code %q, want %q", got, CodeInternal)
+	}
+}
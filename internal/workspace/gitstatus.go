@@ -0,0 +1,103 @@
+package workspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/ccw/ccw/internal/forge"
+	"github.com/ccw/ccw/internal/git"
+)
+
+// GitStatus summarizes a workspace branch's state.
+type GitStatus struct {
+	// Changes is the number of uncommitted (including untracked) files in
+	// the worktree.
+	Changes int `json:"changes"`
+	// HasUpstream is false when origin/<branch> doesn't exist, i.e. the
+	// branch was never pushed; AheadUpstream and BehindUpstream are zero then.
+	HasUpstream    bool `json:"has_upstream"`
+	AheadUpstream  int  `json:"ahead_upstream"`
+	BehindUpstream int  `json:"behind_upstream"`
+	// AheadBase and BehindBase compare against the base branch, preferring
+	// origin/<base>.
+	AheadBase         int       `json:"ahead_base"`
+	BehindBase        int       `json:"behind_base"`
+	LastCommitSubject string    `json:"last_commit_subject"`
+	LastCommitAt      time.Time `json:"last_commit_at"`
+	// Merged is true when the workspace's PR was merged or git finds the
+	// branch's changes in the base. A branch with no commits of its own is
+	// never reported as merged.
+	Merged bool `json:"merged"`
+	// Error is set when part of the status couldn't be collected; the other
+	// fields hold whatever was.
+	Error string `json:"error,omitempty"`
+}
+
+// ListOptions controls what ListWorkspaces collects besides session state.
+type ListOptions struct {
+	// GitStatus fills in WorkspaceStatus.Git. It runs several git commands
+	// per workspace, so it's opt-in.
+	GitStatus bool
+	// Activity fills in WorkspaceStatus.Activity and Agents. It captures
+	// every agent pane of every running session.
+	Activity bool
+}
+
+// collectGitStatus inspects a workspace's branch and worktree. Archived
+// workspaces have no worktree and get nil.
+func collectGitStatus(ctx context.Context, ws Workspace) *GitStatus {
+	if ws.Archived {
+		return nil
+	}
+	st := &GitStatus{}
+	fail := func(err error) {
+		if st.Error == "" {
+			st.Error = err.Error()
+		}
+	}
+
+	if entries, err := git.Status(ctx, ws.WorktreePath); err != nil {
+		fail(err)
+	} else {
+		st.Changes = len(entries)
+	}
+
+	if commit, err := git.LastCommit(ctx, ws.RepoPath, ws.Branch); err != nil {
+		fail(err)
+	} else {
+		st.LastCommitSubject = commit.Subject
+		st.LastCommitAt = commit.Time
+	}
+
+	if git.RemoteTrackingBranchExists(ctx, ws.RepoPath, ws.Branch) {
+		st.HasUpstream = true
+		ahead, behind, err := git.AheadBehind(ctx, ws.RepoPath, ws.Branch, "origin/"+ws.Branch)
+		if err != nil {
+			fail(err)
+		}
+		st.AheadUpstream, st.BehindUpstream = ahead, behind
+	}
+
+	baseRef, err := git.BaseRef(ctx, ws.RepoPath, ws.BaseBranch)
+	if err != nil {
+		fail(err)
+		return st
+	}
+	ahead, behind, err := git.AheadBehind(ctx, ws.RepoPath, ws.Branch, baseRef)
+	if err != nil {
+		fail(err)
+		return st
+	}
+	st.AheadBase, st.BehindBase = ahead, behind
+
+	if ws.PR != nil && ws.PR.State == forge.PRStateMerged {
+		st.Merged = true
+	} else if ahead > 0 {
+		merged, err := git.IsMergedWithPR(ctx, ws.RepoPath, ws.Branch, ws.BaseBranch, false, nil)
+		if err != nil {
+			fail(err)
+		}
+		st.Merged = merged
+	}
+	return st
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListWorkspacesGitStatus(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+	repoDir := filepath.Join(reposRoot, repoName)
+
+	busy, err := mgr.CreateWorkspace(ctx, repoName, "feature/busy", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace busy: %v", err)
+	}
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "feature/idle", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace idle: %v", err)
+	}
+
+	commitFile := func(name, subject string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(busy.WorktreePath, name), []byte(subject), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		runGitCmd(t, busy.WorktreePath, "add", name)
+		runGitCmd(t, busy.WorktreePath, "commit", "-m", subject)
+	}
+	commitFile("a.txt", "pushed work")
+	runGitCmd(t, busy.WorktreePath, "push", "-u", "origin", "feature/busy")
+	commitFile("b.txt", "local work")
+	if err := os.WriteFile(filepath.Join(busy.WorktreePath, "scratch.txt"), []byte("wip"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	runGitCmd(t, repoDir, "commit", "--allow-empty", "-m", "moved on")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+
+	statuses, err := mgr.ListWorkspaces(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListWorkspaces: %v", err)
+	}
+	for _, st := range statuses {
+		if st.Git != nil {
+			t.Fatalf("expected no git status without ListOptions.GitStatus")
+		}
+	}
+
+	statuses, err = mgr.ListWorkspaces(ctx, ListOptions{GitStatus: true})
+	if err != nil {
+		t.Fatalf("ListWorkspaces: %v", err)
+	}
+	byID := map[string]*GitStatus{}
+	for _, st := range statuses {
+		byID[st.ID] = st.Git
+	}
+
+	got := byID[WorkspaceID(repoName, "feature/busy")]
+	if got == nil || got.Error != "" {
+		t.Fatalf("unexpected busy status: %+v", got)
+	}
+	if got.Changes != 1 || !got.HasUpstream || got.AheadUpstream != 1 || got.BehindUpstream != 0 {
+		t.Fatalf("unexpected worktree/upstream status: %+v", got)
+	}
+	if got.AheadBase != 2 || got.BehindBase != 1 || got.Merged {
+		t.Fatalf("unexpected base status: %+v", got)
+	}
+	if got.LastCommitSubject != "local work" || got.LastCommitAt.IsZero() {
+		t.Fatalf("unexpected last commit: %+v", got)
+	}
+
+	idle := byID[WorkspaceID(repoName, "feature/idle")]
+	if idle == nil || idle.Changes != 0 || !idle.HasUpstream || idle.AheadBase != 0 || idle.BehindBase != 1 || idle.Merged {
+		t.Fatalf("unexpected idle status: %+v", idle)
+	}
+
+	info, err := mgr.WorkspaceInfo(ctx, WorkspaceID(repoName, "feature/busy"))
+	if err != nil {
+		t.Fatalf("WorkspaceInfo: %v", err)
+	}
+	if info.Git == nil || info.Git.Changes != 1 {
+		t.Fatalf("expected WorkspaceInfo to include git status, got %+v", info.Git)
+	}
+}
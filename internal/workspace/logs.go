@@ -0,0 +1,348 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/tmux"
+)
+
+// logFollowInterval is how often `ccw logs -f` checks a log for new output.
+const logFollowInterval = 200 * time.Millisecond
+
+// maxLogSize is how big a pane log grows before AppendLog moves it aside
+// to <log>.1, replacing the previous one.
+var maxLogSize int64 = 10 << 20
+
+// PeekOptions configures PeekWorkspace.
+type PeekOptions struct {
+	// Pane is a pane number or anything SendOptions.Pane accepts. Empty
+	// means the first agent pane.
+	Pane string
+	// Lines is how much scrollback to include.
+	Lines int
+}
+
+// LogOptions configures StreamLog.
+type LogOptions struct {
+	// Pane is as for PeekOptions.
+	Pane string
+	// Lines limits the output to the last Lines lines of the log. Zero
+	// prints all of it.
+	Lines int
+	// Follow keeps printing output as it's logged, until ctx is done.
+	Follow bool
+}
+
+// LogDir is where the pane logs of a workspace's session are kept. Logs
+// outlive the session so a crashed agent's last words can still be read.
+func (m *Manager) LogDir(session string) string {
+	return filepath.Join(m.root, "logs", session)
+}
+
+func (m *Manager) logFile(session, pane string) string {
+	return filepath.Join(m.LogDir(session), safeChars.ReplaceAllString(pane, "-")+".log")
+}
+
+// logPipe is the command a pane's output is piped into to log it to path:
+// `ccw log-pipe`, which caps the log's size, or plain cat if the ccw binary
+// can't be found.
+func logPipe(path string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "cat >> " + tmux.ShellQuote(path)
+	}
+	return tmux.ShellQuote(exe) + " log-pipe " + tmux.ShellQuote(path)
+}
+
+// AppendLog appends what it reads from r to the log at path until r ends.
+// Once the log would grow past maxLogSize it's rotated to path.1, so a long
+// running pane keeps at most two logs' worth of output.
+func AppendLog(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if size > 0 && size+int64(n) > maxLogSize {
+				f.Close()
+				if err := os.Rename(path, path+".1"); err != nil {
+					return fmt.Errorf("rotate log: %w", err)
+				}
+				if f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644); err != nil {
+					return err
+				}
+				size = 0
+			}
+			if _, err := f.Write(buf[:n]); err != nil {
+				return err
+			}
+			size += int64(n)
+		}
+		if readErr == io.EOF {
+			return f.Close()
+		} else if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// startPaneLogs pipes each pane's output into its log file. Logging is a
+// convenience, so failures are warnings.
+func (m *Manager) startPaneLogs(session string, panes []config.Pane, targets map[string]string) {
+	if err := os.MkdirAll(m.LogDir(session), 0o755); err != nil {
+		fmt.Fprintf(m.out, "warning: pane logs disabled: %v\n", err)
+		return
+	}
+	for _, p := range panes {
+		if err := m.tmux.PipePane(targets[p.Name], logPipe(m.logFile(session, p.Name))); err != nil {
+			fmt.Fprintf(m.out, "warning: could not log pane %s: %v\n", p.Name, err)
+		}
+	}
+}
+
+// resolvePane finds the pane want refers to in ws, its tmux target, and the
+// agent running in it. want is a pane number as tmux shows it, or anything
+// selectPane accepts. A running session's own panes are searched; otherwise
+// the layout's, and the target is empty.
+func (m *Manager) resolvePane(ws Workspace, want string, alive bool) (config.Pane, string, agent.Agent, error) {
+	rc := m.existingRepoConfig(ws)
+	registry := m.agentsFor(rc)
+
+	var live []sessionPane
+	if alive {
+		var err error
+		if live, err = m.sessionPanes(ws); err != nil {
+			return config.Pane{}, "", nil, err
+		}
+	} else {
+		layout := m.layoutFor(rc).ResolvedPanes()
+		byName := make(map[string]config.Pane, len(layout))
+		for _, p := range layout {
+			byName[p.Name] = p
+		}
+		for i, name := range paneOrder(layout) {
+			live = append(live, sessionPane{Pane: byName[name], Live: tmux.Pane{Index: i}})
+		}
+	}
+	panes := make([]config.Pane, len(live))
+	for i, sp := range live {
+		panes[i] = sp.Pane
+	}
+
+	if n, err := strconv.Atoi(want); err == nil {
+		for _, sp := range live {
+			if sp.Live.Index != n {
+				continue
+			}
+			a, ok := registry.Get(sp.Command)
+			if !ok || m.agentMissing(a) {
+				a = nil
+			}
+			return sp.Pane, sp.Target, a, nil
+		}
+		return config.Pane{}, "", nil, withCode(CodeInvalidArgument, fmt.Errorf("no pane %d in the workspace (have 0-%d)", n, len(live)-1))
+	}
+
+	p, a, err := m.selectPane(registry, panes, want)
+	if err != nil {
+		return config.Pane{}, "", nil, err
+	}
+	for _, sp := range live {
+		if sp.Name == p.Name {
+			return p, sp.Target, a, nil
+		}
+	}
+	return p, "", a, nil
+}
+
+// PeekWorkspace returns the recent output of a pane in a running workspace
+// without attaching to it.
+func (m *Manager) PeekWorkspace(ctx context.Context, id string, opts PeekOptions) (string, error) {
+	if err := m.checkDepsByName("tmux"); err != nil {
+		return "", err
+	}
+	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	alive, err := m.tmux.SessionExists(ws.TmuxSession)
+	if err != nil {
+		return "", err
+	}
+	if !alive {
+		return "", fmt.Errorf("session is not running; see its last output with `ccw logs %s`", resolvedID)
+	}
+
+	_, target, _, err := m.resolvePane(ws, opts.Pane, true)
+	if err != nil {
+		return "", err
+	}
+	content, err := m.tmux.CapturePane(target, opts.Lines)
+	if err != nil {
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+	return content, nil
+}
+
+// PaneLog returns the log file of a workspace pane. If the session is
+// running, logging is switched on first, for sessions started before ccw
+// kept logs.
+func (m *Manager) PaneLog(ctx context.Context, id, pane string) (string, error) {
+	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	alive, err := m.tmux.SessionExists(ws.TmuxSession)
+	if err != nil {
+		return "", err
+	}
+	p, target, _, err := m.resolvePane(ws, pane, alive)
+	if err != nil {
+		return "", err
+	}
+	path := m.logFile(ws.TmuxSession, p.Name)
+
+	if alive {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", fmt.Errorf("create log directory: %w", err)
+		}
+		if err := m.tmux.PipePane(target, logPipe(path)); err != nil {
+			return "", fmt.Errorf("log pane %s: %w", p.Name, err)
+		}
+		// Create the log so it can be followed before the pane prints.
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", err
+		}
+		f.Close()
+	} else if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no log for pane %s of %s, and its session is not running", p.Name, resolvedID)
+	}
+	return path, nil
+}
+
+// StreamLog copies the log at path to w: the last opts.Lines lines, or all
+// of it, and then, with opts.Follow, whatever is appended until ctx is
+// done, following the log across rotations.
+func StreamLog(ctx context.Context, w io.Writer, path string, opts LogOptions) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	if opts.Lines > 0 {
+		start, err := tailOffset(f, opts.Lines)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	if !opts.Follow {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logFollowInterval):
+		}
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		if next := rotatedLog(f, path); next != nil {
+			// Finish the old log before moving on to the new one.
+			if _, err := io.Copy(w, f); err != nil {
+				next.Close()
+				return err
+			}
+			f.Close()
+			f = next
+		}
+	}
+}
+
+// rotatedLog opens the log now at path if it's no longer the file f was
+// opened from, and returns nil otherwise.
+func rotatedLog(f *os.File, path string) *os.File {
+	current, err := os.Stat(path)
+	if err != nil {
+		// Between the rotation and the new log's creation.
+		return nil
+	}
+	opened, err := f.Stat()
+	if err != nil || os.SameFile(opened, current) {
+		return nil
+	}
+	next, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	return next
+}
+
+// tailOffset returns the offset in f where its last n lines start.
+func tailOffset(f *os.File, n int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	end := info.Size()
+	// A trailing newline ends the last line rather than starting another.
+	buf := make([]byte, 1)
+	if end > 0 {
+		if _, err := f.ReadAt(buf, end-1); err != nil {
+			return 0, err
+		}
+		if buf[0] == '\n' {
+			end--
+		}
+	}
+
+	const chunk = 32 * 1024
+	buf = make([]byte, chunk)
+	for pos := end; pos > 0; {
+		size := int64(chunk)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+		if _, err := f.ReadAt(buf[:size], pos); err != nil {
+			return 0, err
+		}
+		for i := size - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				continue
+			}
+			if n--; n == 0 {
+				return pos + i + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccw/ccw/internal/config"
+)
+
+func TestCreateWorkspaceLogsPanes(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, stub)
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/logs", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	want := filepath.Join(mgr.root, "logs", ws.TmuxSession, "left.log")
+	if got := stub.piped[ws.TmuxSession+":0.0"]; !strings.HasSuffix(got, " log-pipe "+want) {
+		t.Fatalf("expected first pane piped to %s, got %q (all: %v)", want, got, stub.piped)
+	}
+	if len(stub.piped) != 2 {
+		t.Fatalf("expected every pane to be logged, got %v", stub.piped)
+	}
+
+	// The log outlives the session, and goes away with the workspace.
+	if err := os.WriteFile(want, []byte("last words\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	delete(stub.sessions, ws.TmuxSession)
+	path, err := mgr.PaneLog(ctx, "feature/logs", "")
+	if err != nil || path != want {
+		t.Fatalf("PaneLog = %q, %v", path, err)
+	}
+	if _, err := mgr.PaneLog(ctx, "feature/logs", "right"); err == nil {
+		t.Fatalf("expected an error for a pane with no log and no session")
+	}
+	if _, err := mgr.PeekWorkspace(ctx, "feature/logs", PeekOptions{}); err == nil || !strings.Contains(err.Error(), "ccw logs") {
+		t.Fatalf("expected peek on a stopped session to point at ccw logs, got %v", err)
+	}
+
+	if err := mgr.RemoveWorkspace(ctx, "feature/logs", RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if _, err := os.Stat(mgr.LogDir(ws.TmuxSession)); !os.IsNotExist(err) {
+		t.Fatalf("expected logs removed with the workspace, got %v", err)
+	}
+}
+
+func TestPeekWorkspaceByPaneNumber(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, stub)
+	mgr.agentAvailable["codex"] = true
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/peek", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	codexPane := stub.order[ws.TmuxSession][1]
+	stub.panes = map[string]string{
+		ws.TmuxSession + ":0.0": "claude output",
+		codexPane:               "codex output",
+	}
+
+	for pane, want := range map[string]string{"": "claude output", "1": "codex output", "codex": "codex output"} {
+		got, err := mgr.PeekWorkspace(ctx, "feature/peek", PeekOptions{Pane: pane, Lines: 200})
+		if err != nil || got != want {
+			t.Fatalf("peek pane %q = %q, %v; want %q", pane, got, err, want)
+		}
+	}
+	if _, err := mgr.PeekWorkspace(ctx, "feature/peek", PeekOptions{Pane: "2"}); ErrorCode(err) != CodeInvalidArgument {
+		t.Fatalf("expected out-of-range pane to be rejected, got %v", err)
+	}
+
+	// Panes keep what they were started with when the layout changes later.
+	mgr.cfg.Layout = config.Layout{Left: "codex", Right: "claude"}
+	for pane, want := range map[string]string{"": "claude output", "0": "claude output", "codex": "codex output"} {
+		got, err := mgr.PeekWorkspace(ctx, "feature/peek", PeekOptions{Pane: pane, Lines: 200})
+		if err != nil || got != want {
+			t.Fatalf("after layout change, peek pane %q = %q, %v; want %q", pane, got, err, want)
+		}
+	}
+}
+
+func TestStreamLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for lines, want := range map[int]string{0: "one\ntwo\nthree\n", 2: "two\nthree\n", 5: "one\ntwo\nthree\n"} {
+		var out bytes.Buffer
+		if err := StreamLog(ctx, &out, path, LogOptions{Lines: lines}); err != nil {
+			t.Fatalf("StreamLog: %v", err)
+		}
+		if out.String() != want {
+			t.Fatalf("lines %d: got %q, want %q", lines, out.String(), want)
+		}
+	}
+
+	// Following picks up what's appended until the context ends.
+	ctx, cancel := context.WithTimeout(ctx, 3*logFollowInterval)
+	defer cancel()
+	go func() {
+		time.Sleep(logFollowInterval / 2)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString("four\n")
+		f.Close()
+	}()
+	var out bytes.Buffer
+	if err := StreamLog(ctx, &out, path, LogOptions{Lines: 1, Follow: true}); err != nil {
+		t.Fatalf("StreamLog follow: %v", err)
+	}
+	if out.String() != "three\nfour\n" {
+		t.Fatalf("follow: got %q", out.String())
+	}
+}
+
+func TestAppendLogRotates(t *testing.T) {
+	old := maxLogSize
+	maxLogSize = 10
+	defer func() { maxLogSize = old }()
+
+	path := filepath.Join(t.TempDir(), "pane.log")
+	if err := os.WriteFile(path, []byte("earlier\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendLog(strings.NewReader("more\n"), path); err != nil {
+		t.Fatalf("AppendLog: %v", err)
+	}
+	for p, want := range map[string]string{path: "more\n", path + ".1": "earlier\n"} {
+		data, err := os.ReadFile(p)
+		if err != nil || string(data) != want {
+			t.Fatalf("%s = %q, %v; want %q", filepath.Base(p), data, err, want)
+		}
+	}
+}
+
+func TestStreamLogFollowsRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.log")
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*logFollowInterval)
+	defer cancel()
+	go func() {
+		time.Sleep(logFollowInterval / 2)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString("two\n")
+		f.Close()
+		os.Rename(path, path+".1")
+		os.WriteFile(path, []byte("three\n"), 0o644)
+	}()
+	var out bytes.Buffer
+	if err := StreamLog(ctx, &out, path, LogOptions{Follow: true}); err != nil {
+		t.Fatalf("StreamLog: %v", err)
+	}
+	if out.String() != "one\ntwo\nthree\n" {
+		t.Fatalf("got %q", out.String())
+	}
+}
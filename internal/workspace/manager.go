@@ -13,11 +13,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ccw/ccw/internal/agent"
 	"github.com/ccw/ccw/internal/claude"
 	"github.com/ccw/ccw/internal/config"
 	"github.com/ccw/ccw/internal/deps"
+	"github.com/ccw/ccw/internal/forge"
 	"github.com/ccw/ccw/internal/git"
-	"github.com/ccw/ccw/internal/github"
+	"github.com/ccw/ccw/internal/hooks"
 	"github.com/ccw/ccw/internal/tmux"
 	"golang.org/x/term"
 )
@@ -30,8 +32,13 @@ type TmuxRunner interface {
 	KillSession(name string) error
 	CloseClientTTYs(ttys []string)
 	AttachSession(name string) error
-	SplitPane(session string, horizontal bool, path string) error
-	SendKeys(target string, keys []string, enter bool) error
+	SplitPane(target string, horizontal bool, path string, size int) (string, error)
+	SendText(target, text string, enter bool) error
+	CapturePane(target string, lines int) (string, error)
+	PipePane(target, command string) error
+	Panes(session string) ([]tmux.Pane, error)
+	TagPane(target, name, launch string) error
+	ListSessions() ([]tmux.Session, error)
 }
 
 var ErrWorkspaceAlreadyOpen = errors.New("workspace already open")
@@ -49,23 +56,61 @@ type Manager struct {
 	regStore *Store
 	tmux     TmuxRunner
 
-	codexAvailable bool
+	agents *agent.Registry
+	// agentAvailable records whether each optional agent's CLI was found,
+	// keyed by dependency name.
+	agentAvailable map[string]bool
 	skipDeps       bool
-	claudeCaps     claude.Capabilities
-	capsDetected   bool
 
-	// GitHub clients keyed by repoPath
-	ghClients map[string]*github.Client
-
-	// skipGitHubCheck skips GitHub repo validation (for testing only)
-	skipGitHubCheck bool
+	// Forge providers (GitHub, GitLab, Gitea) keyed by repoPath
+	forges map[string]forge.Provider
+
+	// skipForgeCheck skips forge CLI auth checks and PR/MR lookups (for
+	// testing only)
+	skipForgeCheck bool
+
+	// out receives hook output and warnings. Defaults to stderr so stdout
+	// stays clean for --json.
+	out io.Writer
+	// onEvent receives progress events; see SetEventHandler.
+	onEvent func(Event)
+	// readyPoll and readySettle tune waitReady.
+	readyPoll   time.Duration
+	readySettle time.Duration
+	// processes lists running processes for agent activity; a field so
+	// tests can replace it.
+	processes func() (map[int][]int, error)
 }
 
 type CreateOptions struct {
 	BaseBranch string
 	NoAttach   bool
 	NoFetch    bool
-	Message    string
+	// Message is the initial prompt for the workspace's agent, used as is.
+	Message string
+	// PromptFile and PromptTemplate (a name in ~/.ccw/prompts) give the
+	// initial prompt instead. Both are Go templates that can use
+	// {{.Repo}}, {{.Branch}}, {{.BaseBranch}}, {{.Workspace}} and
+	// PromptVars.
+	PromptFile     string
+	PromptTemplate string
+	PromptVars     map[string]string
+	// OnFilesCopied is called with what was carried over from the main
+	// checkout (.env and copy_files) once the worktree exists.
+	OnFilesCopied func(copied []CopiedPath)
+	// CheckoutExisting uses a branch that already exists locally or on
+	// origin instead of creating one. The branch is not pushed, and `ccw rm`
+	// leaves it alone.
+	CheckoutExisting bool
+	// FromPR checks out the head of this PR/MR number. The branch argument
+	// to CreateWorkspace must be empty; it comes from the PR.
+	FromPR int
+	Tags   []string
+	// Notes describes the workspace. Defaults to the prompt, as rendered.
+	Notes string
+	// On stacks the new workspace on an existing one in the same repo: the
+	// branch starts from the parent's local branch, which becomes its base.
+	On string
 }
 
 type RemoveOptions struct {
@@ -83,6 +128,15 @@ type WorkspaceStatus struct {
 	Workspace    Workspace
 	SessionAlive bool
 	HasClients   bool
+	// Git is filled in by WorkspaceInfo, and by ListWorkspaces when
+	// ListOptions.GitStatus is set. Nil for archived workspaces.
+	Git *GitStatus `json:",omitempty"`
+	// Activity is what the workspace's agents are doing, led by the one
+	// that most needs the user, and Agents has each agent's. Filled in by
+	// WorkspaceInfo, and by ListWorkspaces when ListOptions.Activity is set,
+	// for running sessions only.
+	Activity Activity        `json:",omitempty"`
+	Agents   []AgentActivity `json:",omitempty"`
 }
 
 func NewManager(root string, tmuxRunner TmuxRunner) (*Manager, error) {
@@ -120,71 +174,237 @@ func NewManager(root string, tmuxRunner TmuxRunner) (*Manager, error) {
 		cfgStore: cfgStore,
 		regStore: regStore,
 		tmux:     tmuxRunner,
+		agents:   agent.FromConfig(cfg.Agents),
+		out:      os.Stderr,
+
+		readyPoll:   readyPollInterval,
+		readySettle: readySettle,
+		processes:   listProcesses,
 	}
 
+	m.agents.SetCacheDir(root)
 	m.detectOptionalDeps()
 	return m, nil
 }
 
+// SetOutput redirects hook output and warnings.
+func (m *Manager) SetOutput(w io.Writer) {
+	m.out = w
+}
+
+// repoConfig returns the effective settings for a repo: its committed
+// .ccw.json/.ccw.yaml merged with the user's Repos entry. On a bad repo file
+// the user's settings are returned alongside the error.
+func (m *Manager) repoConfig(repo, repoPath string) (config.RepoConfig, error) {
+	user := m.cfg.Repos[repo]
+	shared, found, err := config.LoadRepoFile(repoPath)
+	if err != nil {
+		return user, err
+	}
+	if !found {
+		return user, nil
+	}
+	return config.MergeRepoConfig(shared, user), nil
+}
+
+// existingRepoConfig is repoConfig for workspaces that already exist, where a
+// broken repo file shouldn't block opening or removing them.
+func (m *Manager) existingRepoConfig(ws Workspace) config.RepoConfig {
+	rc, err := m.repoConfig(ws.Repo, ws.RepoPath)
+	if err != nil {
+		fmt.Fprintf(m.out, "warning: %v; using settings from %s only\n", err, m.cfgStore.Path())
+	}
+	return rc
+}
+
+func (m *Manager) layoutFor(rc config.RepoConfig) config.Layout {
+	if rc.Layout != nil {
+		return *rc.Layout
+	}
+	return m.cfg.Layout
+}
+
+func (m *Manager) agentsFor(rc config.RepoConfig) *agent.Registry {
+	if len(rc.Agents) == 0 {
+		return m.agents
+	}
+	return m.agents.With(rc.Agents)
+}
+
+// runHooks runs one lifecycle point's hooks for a workspace in dir.
+func (m *Manager) runHooks(ctx context.Context, point string, list []config.Hook, dir, id string, ws Workspace) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	base := ws.BaseBranch
+	if base == "" {
+		if detected, err := git.DetectDefaultBranch(ctx, ws.RepoPath); err == nil {
+			base = detected
+		}
+	}
+
+	// A worktree deleted by hand shouldn't make removal hooks impossible.
+	if _, err := os.Stat(dir); err != nil {
+		dir = ws.RepoPath
+	}
+
+	env := hooks.Env{
+		Hook:         point,
+		WorkspaceID:  id,
+		Repo:         ws.Repo,
+		RepoPath:     ws.RepoPath,
+		Branch:       ws.Branch,
+		BaseBranch:   base,
+		WorktreePath: ws.WorktreePath,
+		TmuxSession:  ws.TmuxSession,
+	}
+	if err := hooks.Run(ctx, list, dir, env, m.out); err != nil {
+		return withCode(CodeHookFailed, err)
+	}
+	return nil
+}
+
 func (m *Manager) detectOptionalDeps() {
-	for _, dep := range deps.DefaultDependencies() {
-		if dep.Name != "codex" {
+	m.agentAvailable = make(map[string]bool)
+	for _, a := range m.agents.All() {
+		if dep := a.Dependency(); dep.Optional {
+			m.agentAvailable[dep.Name] = deps.Check(dep).Found
+		}
+	}
+}
+
+// Dependencies returns ccw's own tool dependencies, the forge CLIs, and those
+// of every registered agent.
+func (m *Manager) Dependencies() []deps.Dependency {
+	all := append(deps.DefaultDependencies(), forge.Dependencies()...)
+	return append(all, m.AgentDependencies()...)
+}
+
+// AgentDependencies returns the dependencies of every registered agent.
+func (m *Manager) AgentDependencies() []deps.Dependency {
+	var all []deps.Dependency
+	for _, a := range m.agents.All() {
+		all = append(all, a.Dependency())
+	}
+	return all
+}
+
+// layoutAgents returns the agents referenced by a repo's layout, in pane
+// order.
+func (m *Manager) layoutAgents(rc config.RepoConfig) []agent.Agent {
+	registry := m.agentsFor(rc)
+	var agents []agent.Agent
+	seen := map[string]bool{}
+	for _, p := range m.layoutFor(rc).ResolvedPanes() {
+		a, ok := registry.Get(p.Command)
+		if !ok || seen[a.Name()] {
 			continue
 		}
-		res := deps.Check(dep)
-		m.codexAvailable = res.Found
+		seen[a.Name()] = true
+		agents = append(agents, a)
 	}
+	return agents
 }
 
-// getGitHubClient returns a GitHub client for the given repo path.
-// Returns nil if gh is not available or repo is not GitHub-hosted.
-func (m *Manager) getGitHubClient(repoPath string) *github.Client {
-	if m.ghClients == nil {
-		m.ghClients = make(map[string]*github.Client)
+// checkAgentDeps checks every agent a repo's layout uses.
+func (m *Manager) checkAgentDeps(rc config.RepoConfig) error {
+	var toCheck []deps.Dependency
+	for _, a := range m.layoutAgents(rc) {
+		toCheck = append(toCheck, a.Dependency())
 	}
+	return m.checkDeps(toCheck)
+}
 
-	if client, ok := m.ghClients[repoPath]; ok {
-		return client
+// forgeFor returns the forge provider for the given repo path, detected from
+// its origin remote and cached per repo.
+func (m *Manager) forgeFor(repoPath string) (forge.Provider, error) {
+	if p, ok := m.forges[repoPath]; ok {
+		return p, nil
+	}
+	if m.forges == nil {
+		m.forges = make(map[string]forge.Provider)
 	}
 
-	client := github.NewClient(repoPath)
-	m.ghClients[repoPath] = client
-	return client
+	p, err := forge.Detect(repoPath, m.cfg.Forges)
+	if err != nil {
+		return nil, err
+	}
+	m.forges[repoPath] = p
+	return p, nil
 }
 
-// getPRChecker returns a MergeChecker function for the given repo path.
-// The checker uses GitHub PR status to determine if a branch was merged.
-func (m *Manager) getPRChecker(repoPath string) git.MergeChecker {
-	client := m.getGitHubClient(repoPath)
-	if client == nil || !client.IsGitHubRepo() {
-		return nil
+// checkForge makes sure the repo's forge CLI is installed and logged in.
+// Plain git remotes need neither.
+func (m *Manager) checkForge(ctx context.Context, repoPath string) (forge.Provider, error) {
+	p, err := m.forgeFor(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if m.skipForgeCheck {
+		return p, nil
+	}
+	dep, ok := p.Dependency()
+	if !ok {
+		return p, nil
+	}
+	dep.Optional = false
+	if err := m.checkDeps([]deps.Dependency{dep}); err != nil {
+		return nil, err
 	}
-	return client.IsPRMerged
+	if err := p.CheckAuth(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
-func (m *Manager) checkDepsByName(names ...string) error {
-	if m.skipDeps || os.Getenv("CCW_SKIP_DEPS") == "1" {
-		return nil
+// getPRChecker returns a MergeChecker for the given repo path that asks its
+// forge for PR/MR state. Returns nil when PR lookups are disabled, in which
+// case git heuristics alone decide.
+func (m *Manager) getPRChecker(ctx context.Context, repoPath string) (git.MergeChecker, error) {
+	if m.skipForgeCheck {
+		return nil, nil
 	}
+	p, err := m.checkForge(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if p.Kind() == forge.KindPlain {
+		return nil, nil
+	}
+	return p.MergeStatus, nil
+}
 
-	var toCheck []deps.Dependency
-	all := deps.DefaultDependencies()
+func (m *Manager) checkDepsByName(names ...string) error {
+	return m.checkDeps(m.dependenciesByName(names...))
+}
+
+func (m *Manager) dependenciesByName(names ...string) []deps.Dependency {
+	all := m.Dependencies()
 	if len(names) == 0 {
-		toCheck = all
-	} else {
-		for _, name := range names {
-			for _, dep := range all {
-				if dep.Name == name {
-					toCheck = append(toCheck, dep)
-				}
+		return all
+	}
+
+	var found []deps.Dependency
+	for _, name := range names {
+		for _, dep := range all {
+			if dep.Name == name {
+				found = append(found, dep)
 			}
 		}
 	}
+	return found
+}
+
+func (m *Manager) checkDeps(toCheck []deps.Dependency) error {
+	if m.skipDeps || os.Getenv("CCW_SKIP_DEPS") == "1" {
+		return nil
+	}
 
 	results := deps.CheckAll(toCheck)
 	for _, res := range results {
-		if res.Dependency.Name == "codex" {
-			m.codexAvailable = res.Found
+		if res.Dependency.Optional {
+			m.agentAvailable[res.Dependency.Name] = res.Found
 		}
 	}
 
@@ -197,19 +417,35 @@ func (m *Manager) checkDepsByName(names ...string) error {
 	for _, res := range missing {
 		hints = append(hints, fmt.Sprintf("%s (%s)", res.Dependency.DisplayName, res.Dependency.InstallHint))
 	}
-	return fmt.Errorf("missing dependencies: %s", strings.Join(hints, "; "))
+	return withCode(CodeMissingDependency, fmt.Errorf("missing dependencies: %s", strings.Join(hints, "; ")))
 }
 
 func (m *Manager) CreateWorkspace(ctx context.Context, repo, branch string, opts CreateOptions) (Workspace, error) {
-	if err := m.checkDepsByName("git", "tmux", "claude", "gh"); err != nil {
+	if err := m.checkDepsByName("git", "tmux"); err != nil {
 		return Workspace{}, err
 	}
 
 	if err := validateName(repo); err != nil {
-		return Workspace{}, fmt.Errorf("invalid repo name: %w", err)
+		return Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("invalid repo name: %w", err))
 	}
-	if err := validateBranch(branch); err != nil {
-		return Workspace{}, fmt.Errorf("invalid branch name: %w", err)
+	if opts.FromPR > 0 {
+		if branch != "" {
+			return Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("cannot pass a branch together with a PR number"))
+		}
+	} else if err := validateBranch(branch); err != nil {
+		return Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("invalid branch name: %w", err))
+	}
+
+	tags, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return Workspace{}, err
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	prompt, promptIsTemplate, err := m.loadPrompt(opts)
+	if err != nil {
+		return Workspace{}, err
 	}
 
 	reposDir, err := m.cfg.ExpandedReposDir()
@@ -222,21 +458,67 @@ func (m *Manager) CreateWorkspace(ctx context.Context, repo, branch string, opts
 		return Workspace{}, err
 	}
 
-	// Validate this is a GitHub-hosted repo (unless skipped for testing)
-	if !m.skipGitHubCheck {
-		ghClient := m.getGitHubClient(repoPath)
-		if !ghClient.IsGitHubRepo() {
-			return Workspace{}, fmt.Errorf("repository %q is not hosted on GitHub. ccw requires GitHub repositories.", repo)
-		}
-
-		// Check gh authentication
-		if err := github.CheckAuthenticated(); err != nil {
+	rc, err := m.repoConfig(repo, repoPath)
+	if err != nil {
+		return Workspace{}, err
+	}
+	if err := m.checkAgentDeps(rc); err != nil {
+		return Workspace{}, err
+	}
+	for _, cf := range rc.CopyFiles {
+		if err := cf.Validate(); err != nil {
 			return Workspace{}, err
 		}
 	}
 
+	provider, err := m.checkForge(ctx, repoPath)
+	if err != nil {
+		return Workspace{}, err
+	}
+
 	baseBranch := opts.BaseBranch
-	// If baseBranch is empty, git.CreateBranch will auto-detect main/master
+	external := opts.CheckoutExisting || opts.FromPR > 0
+	var parentID string
+	if opts.On != "" {
+		if baseBranch != "" || external {
+			return Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("--on cannot be combined with --base, --checkout-existing or --from-pr"))
+		}
+		id, parent, err := m.lookupWorkspace(ctx, opts.On)
+		if err != nil {
+			return Workspace{}, fmt.Errorf("parent workspace: %w", err)
+		}
+		if parent.RepoPath != repoPath {
+			return Workspace{}, fmt.Errorf("parent workspace %s is in repo %s, not %s", id, parent.Repo, repo)
+		}
+		parentID = id
+		baseBranch = parent.Branch
+	}
+	var pr *forge.PullRequest
+	var fetchRef string
+	if opts.FromPR > 0 {
+		found, err := provider.ViewPR(ctx, strconv.Itoa(opts.FromPR))
+		if err != nil {
+			return Workspace{}, fmt.Errorf("look up PR #%d: %w", opts.FromPR, err)
+		}
+		pr = &found
+		branch = found.HeadBranch
+		if found.CrossRepository || branch == "" {
+			// A fork's branch isn't on origin and its name may clash with
+			// ours, so check out the PR ref under a name of our own.
+			branch = fmt.Sprintf("pr/%d", found.Number)
+			fetchRef = found.FetchRef
+		}
+		if err := validateBranch(branch); err != nil {
+			return Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("invalid branch name: %w", err))
+		}
+		if baseBranch == "" {
+			baseBranch = found.BaseBranch
+		}
+	}
+	if baseBranch == "" {
+		baseBranch = rc.BaseBranch
+	}
+	// If baseBranch is still empty, git.CreateBranch will auto-detect main/master
 
 	worktreeRoot := filepath.Join(m.root, "worktrees")
 	workspaceID := WorkspaceID(repo, branch)
@@ -246,55 +528,78 @@ func (m *Manager) CreateWorkspace(ctx context.Context, repo, branch string, opts
 		return Workspace{}, err
 	}
 
-	rb := rollback{}
+	if promptIsTemplate {
+		prompt, err = renderPrompt(ctx, prompt, opts.PromptVars, workspaceID, Workspace{Repo: repo, RepoPath: repoPath, Branch: branch, BaseBranch: baseBranch})
+		if err != nil {
+			return Workspace{}, err
+		}
+	}
 
-	if err := git.CreateBranch(repoPath, branch, baseBranch, !opts.NoFetch); err != nil {
-		return Workspace{}, err
+	rb := rollback{done: func() {
+		m.emit(Event{Type: EventRolledBack, Workspace: workspaceID, Branch: branch})
+	}}
+
+	if external {
+		created, err := git.CheckoutExistingBranch(repoPath, branch, fetchRef, !opts.NoFetch)
+		if err != nil {
+			return Workspace{}, err
+		}
+		if created {
+			rb.Add(func() { _ = git.DeleteBranch(repoPath, branch, true) })
+		}
+		m.emit(Event{Type: EventBranchCheckedOut, Workspace: workspaceID, Branch: branch})
+	} else if parentID != "" {
+		// The parent's local branch may be ahead of origin; start from it.
+		if err := git.CreateBranchAt(repoPath, branch, baseBranch); err != nil {
+			return Workspace{}, err
+		}
+		rb.Add(func() { _ = git.DeleteBranch(repoPath, branch, true) })
+		m.emit(Event{Type: EventBranchCreated, Workspace: workspaceID, Branch: branch})
+	} else {
+		if err := git.CreateBranch(repoPath, branch, baseBranch, !opts.NoFetch); err != nil {
+			return Workspace{}, err
+		}
+		rb.Add(func() { _ = git.DeleteBranch(repoPath, branch, true) })
+		m.emit(Event{Type: EventBranchCreated, Workspace: workspaceID, Branch: branch})
 	}
-	rb.Add(func() { _ = git.DeleteBranch(repoPath, branch, true) })
 
-	if err := git.PushBranch(repoPath, branch); err != nil {
-		rb.Run()
-		return Workspace{}, err
+	if !external {
+		if err := git.PushBranch(repoPath, branch); err != nil {
+			rb.Run()
+			return Workspace{}, err
+		}
+		rb.Add(func() { _ = git.DeleteRemoteBranch(repoPath, "origin", branch) })
+		m.emit(Event{Type: EventPushed, Workspace: workspaceID, Branch: branch})
 	}
-	rb.Add(func() { _ = git.DeleteRemoteBranch(repoPath, "origin", branch) })
 
 	if err := git.CreateWorktree(repoPath, worktreePath, branch); err != nil {
 		rb.Run()
 		return Workspace{}, err
 	}
 	rb.Add(func() { _ = git.RemoveWorktree(repoPath, worktreePath, true) })
+	m.emit(Event{Type: EventWorktreeCreated, Workspace: workspaceID, Branch: branch, Path: worktreePath})
 
-	// Copy .env from main repo to worktree if it exists
-	envSrc := filepath.Join(repoPath, ".env")
-	envDst := filepath.Join(worktreePath, ".env")
-	if err := copyFileIfExists(envSrc, envDst); err != nil {
+	// Copy .env and the per-repo copy_files from the main checkout
+	copied, err := copyRepoFiles(repoPath, worktreePath, copyEntries(rc))
+	if err != nil {
 		rb.Run()
-		return Workspace{}, fmt.Errorf("copy .env: %w", err)
+		return Workspace{}, err
 	}
-
-	// Copy additional per-repo files from config
-	if rc, ok := m.cfg.Repos[repo]; ok {
-		for _, f := range rc.CopyFiles {
-			src := filepath.Join(repoPath, f)
-			dst := filepath.Join(worktreePath, f)
-			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
-				rb.Run()
-				return Workspace{}, fmt.Errorf("create dir for %s: %w", f, err)
-			}
-			if err := copyFileIfExists(src, dst); err != nil {
-				rb.Run()
-				return Workspace{}, fmt.Errorf("copy %s: %w", f, err)
-			}
+	if opts.OnFilesCopied != nil && len(copied) > 0 {
+		opts.OnFilesCopied(copied)
+	}
+	if len(copied) > 0 {
+		paths := make([]string, 0, len(copied))
+		for _, c := range copied {
+			paths = append(paths, c.Path)
 		}
+		m.emit(Event{Type: EventFilesCopied, Workspace: workspaceID, Path: worktreePath, Message: summarizePaths(paths, 5)})
 	}
 
-	if err := m.bootstrapSession(ctx, safeName, worktreePath, false); err != nil {
-		rb.Run()
-		return Workspace{}, err
+	notes := strings.TrimSpace(opts.Notes)
+	if notes == "" {
+		notes = strings.TrimSpace(prompt)
 	}
-	rb.Add(func() { _ = m.tmux.KillSession(safeName) })
-
 	now := time.Now().UTC()
 	ws := Workspace{
 		Repo:           repo,
@@ -302,18 +607,40 @@ func (m *Manager) CreateWorkspace(ctx context.Context, repo, branch string, opts
 		Branch:         branch,
 		BaseBranch:     baseBranch,
 		WorktreePath:   worktreePath,
-		ClaudeSession:  safeName,
+		ClaudeSession:  claude.NewSessionID(),
 		TmuxSession:    safeName,
 		CreatedAt:      now,
 		LastAccessedAt: now,
+		Message:        prompt,
+		Notes:          notes,
+		Tags:           tags,
+		ExternalBranch: external && fetchRef == "",
+		PRRef:          fetchRef,
+		Parent:         parentID,
+	}
+	if pr != nil {
+		ws.PR = &PullRequest{Number: pr.Number, URL: pr.URL, State: pr.State, CheckedAt: now}
+	}
+
+	if err := m.runHooks(ctx, hooks.PostCreate, rc.Hooks.PostCreate, worktreePath, workspaceID, ws); err != nil {
+		rb.Run()
+		return Workspace{}, err
 	}
 
+	if err := m.bootstrapSession(ctx, safeName, worktreePath, rc, agentLaunch{claudeSession: ws.ClaudeSession, prompt: prompt}); err != nil {
+		rb.Run()
+		return Workspace{}, err
+	}
+	rb.Add(func() { _ = m.tmux.KillSession(safeName) })
+	m.emit(Event{Type: EventSessionStarted, Workspace: workspaceID, Session: safeName})
+
 	if err := m.regStore.Update(ctx, func(reg *Registry) error {
 		return reg.Add(workspaceID, ws)
 	}); err != nil {
 		rb.Run()
 		return Workspace{}, err
 	}
+	m.emit(Event{Type: EventRegistered, Workspace: workspaceID, Branch: branch, Path: worktreePath})
 
 	if !opts.NoAttach && term.IsTerminal(int(os.Stdout.Fd())) {
 		if err := m.tmux.AttachSession(safeName); err != nil {
@@ -324,30 +651,122 @@ func (m *Manager) CreateWorkspace(ctx context.Context, repo, branch string, opts
 	return ws, nil
 }
 
-func (m *Manager) bootstrapSession(ctx context.Context, name, path string, resume bool) error {
-	if err := m.tmux.CreateSession(name, path, true); err != nil {
+func (m *Manager) bootstrapSession(ctx context.Context, name, path string, rc config.RepoConfig, launch agentLaunch) error {
+	layout := m.layoutFor(rc)
+	if err := layout.Validate(); err != nil {
 		return err
 	}
+	panes := layout.ResolvedPanes()
 
-	if err := m.tmux.SplitPane(name, true, path); err != nil {
+	if err := m.tmux.CreateSession(name, paneDir(path, panes[0]), true); err != nil {
 		return err
 	}
 
-	caps := m.claudeCapabilities(ctx)
-	claudeCmd := claude.BuildLaunchCommand(name, resume, caps, m.cfg.ClaudeDangerouslySkipPerms)
-	if err := m.tmux.SendKeys(name+":0.0", []string{claudeCmd}, true); err != nil {
-		return err
+	// The first pane is never shifted by later splits, so it keeps index 0.
+	// Every other pane is addressed by the ID tmux hands back.
+	targets := map[string]string{panes[0].Name: name + ":0.0"}
+	prev := targets[panes[0].Name]
+	for _, p := range panes[1:] {
+		from := prev
+		if p.From != "" {
+			from = targets[p.From]
+		}
+		id, err := m.tmux.SplitPane(from, p.Split != config.SplitVertical, paneDir(path, p), p.Size)
+		if err != nil {
+			return err
+		}
+		targets[p.Name] = id
+		prev = id
+	}
+	for _, p := range panes {
+		// A tmux without pane options leaves the pane untagged; such
+		// sessions are matched to the layout by position instead.
+		_ = m.tmux.TagPane(targets[p.Name], p.Name, p.Command)
+	}
+	m.startPaneLogs(name, panes, targets)
+	// Hook events from an earlier session no longer apply.
+	os.Remove(m.statusFile(name))
+
+	registry := m.agentsFor(rc)
+	promptAt, promptAgent, typePrompt := -1, agent.Agent(nil), false
+	if launch.prompt != "" {
+		promptAt, promptAgent = m.promptPane(registry, panes)
+		typePrompt = promptAgent != nil && !agent.AcceptsPrompt(promptAgent)
+		if promptAt < 0 {
+			fmt.Fprintln(m.out, "warning: no agent pane in the layout; the initial prompt was not sent")
+		}
 	}
-
-	if m.codexAvailable {
-		_ = m.tmux.SendKeys(name+":0.1", []string{"codex --dangerously-bypass-approvals-and-sandbox"}, true)
+	for i, p := range panes {
+		paneLaunch := launch
+		if i != promptAt || typePrompt {
+			paneLaunch.prompt = ""
+		}
+		cmd := m.paneCommand(ctx, registry, name, p, paneLaunch)
+		if cmd == "" {
+			continue
+		}
+		if err := m.tmux.SendText(targets[p.Name], cmd, true); err != nil {
+			return err
+		}
 	}
 
+	if typePrompt {
+		// The shell showing the typed launch command looks just like an
+		// agent at rest, so only a ready marker tells them apart.
+		if len(agent.ReadyMarkers(promptAgent)) == 0 {
+			fmt.Fprintf(m.out, "warning: %s has no ready_markers, so ccw can't tell when it accepts input; the initial prompt was not sent\n", promptAgent.Name())
+		} else if err := m.sendWhenReady(ctx, targets[panes[promptAt].Name], promptAgent, launch.prompt); err != nil {
+			fmt.Fprintf(m.out, "warning: could not send the initial prompt: %v\n", err)
+		}
+	}
 	return nil
 }
 
+// paneCommand returns the command to type into a layout pane. Agent names are
+// expanded to their launch commands; an empty result leaves the shell idle.
+// Optional agents that are not installed are skipped.
+func (m *Manager) paneCommand(ctx context.Context, registry *agent.Registry, session string, p config.Pane, launch agentLaunch) string {
+	a, ok := registry.Get(p.Command)
+	if !ok {
+		return p.Command
+	}
+	if m.agentMissing(a) {
+		return ""
+	}
+
+	if !m.skipDeps && os.Getenv("CCW_SKIP_DEPS") != "1" {
+		a.Detect(ctx)
+	}
+
+	opts := agent.LaunchOptions{
+		SessionName:     session,
+		SkipPermissions: m.cfg.ClaudeDangerouslySkipPerms,
+		Prompt:          launch.prompt,
+	}
+	if a.Name() == "claude" {
+		opts.SessionID = launch.claudeSession
+	}
+	if launch.resume {
+		return a.ResumeCommand(opts)
+	}
+	return a.LaunchCommand(opts)
+}
+
+// agentMissing reports whether a is an optional agent that isn't installed.
+func (m *Manager) agentMissing(a agent.Agent) bool {
+	available, known := m.agentAvailable[a.Dependency().Name]
+	return known && !available
+}
+
+func paneDir(worktreePath string, p config.Pane) string {
+	if p.Dir == "" {
+		return worktreePath
+	}
+	return filepath.Join(worktreePath, p.Dir)
+}
+
 func (m *Manager) OpenWorkspace(ctx context.Context, id string, opts OpenOptions) error {
-	if err := m.checkDepsByName("git", "tmux", "claude"); err != nil {
+	if err := m.checkDepsByName("git", "tmux"); err != nil {
 		return err
 	}
 
@@ -355,16 +774,34 @@ func (m *Manager) OpenWorkspace(ctx context.Context, id string, opts OpenOptions
 	if err != nil {
 		return err
 	}
+	if ws.Archived {
+		return fmt.Errorf("%w: run `ccw restore %s` to bring it back", ErrWorkspaceArchived, resolvedID)
+	}
+
+	rc := m.existingRepoConfig(ws)
+	if err := m.checkAgentDeps(rc); err != nil {
+		return err
+	}
+
+	if err := m.runHooks(ctx, hooks.PreOpen, rc.Hooks.PreOpen, ws.WorktreePath, resolvedID, ws); err != nil {
+		return err
+	}
 
 	sessionExists, err := m.tmux.SessionExists(ws.TmuxSession)
 	if err != nil {
 		return err
 	}
 
+	claudeSession := ws.ClaudeSession
 	if !sessionExists {
-		if err := m.bootstrapSession(ctx, ws.TmuxSession, ws.WorktreePath, opts.ResumeClaude); err != nil {
+		launch := m.claudeLaunch(ws, rc, opts.ResumeClaude)
+		if err := m.bootstrapSession(ctx, ws.TmuxSession, ws.WorktreePath, rc, launch); err != nil {
 			return err
 		}
+		m.emit(Event{Type: EventSessionStarted, Workspace: resolvedID, Session: ws.TmuxSession})
+		if launch.claudeSession != "" {
+			claudeSession = launch.claudeSession
+		}
 	}
 
 	if sessionExists {
@@ -374,7 +811,7 @@ func (m *Manager) OpenWorkspace(ctx context.Context, id string, opts OpenOptions
 		}
 	}
 
-	if err := m.updateLastAccessed(ctx, resolvedID); err != nil {
+	if err := m.updateLastAccessed(ctx, resolvedID, claudeSession); err != nil {
 		return err
 	}
 
@@ -389,14 +826,16 @@ func (m *Manager) CloseWorkspace(ctx context.Context, id string) error {
 		return err
 	}
 
-	_, ws, err := m.lookupWorkspace(ctx, id)
+	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	clientTTYs, _ := m.tmux.ClientTTYs(ws.TmuxSession)
 
-	if err := m.tmux.KillSession(ws.TmuxSession); err != nil && !errors.Is(err, tmux.ErrSessionMissing) {
+	if err := m.tmux.KillSession(ws.TmuxSession); err == nil {
+		m.emit(Event{Type: EventSessionKilled, Workspace: resolvedID, Session: ws.TmuxSession})
+	} else if !errors.Is(err, tmux.ErrSessionMissing) {
 		return err
 	}
 
@@ -407,7 +846,9 @@ func (m *Manager) CloseWorkspace(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *Manager) updateLastAccessed(ctx context.Context, id string) error {
+// updateLastAccessed stamps the workspace as used and records the claude
+// conversation it was opened with.
+func (m *Manager) updateLastAccessed(ctx context.Context, id, claudeSession string) error {
 	now := time.Now().UTC()
 	return m.regStore.Update(ctx, func(reg *Registry) error {
 		ws, ok := reg.Workspaces[id]
@@ -415,12 +856,13 @@ func (m *Manager) updateLastAccessed(ctx context.Context, id string) error {
 			return fmt.Errorf("workspace %s not found", id)
 		}
 		ws.LastAccessedAt = now
+		ws.ClaudeSession = claudeSession
 		reg.Workspaces[id] = ws
 		return nil
 	})
 }
 
-func (m *Manager) ListWorkspaces(ctx context.Context) ([]WorkspaceStatus, error) {
+func (m *Manager) ListWorkspaces(ctx context.Context, opts ListOptions) ([]WorkspaceStatus, error) {
 	reg, err := m.regStore.Read(ctx)
 	if err != nil {
 		return nil, err
@@ -432,35 +874,70 @@ func (m *Manager) ListWorkspaces(ctx context.Context) ([]WorkspaceStatus, error)
 	}
 	sort.Strings(ids)
 
-	var statuses []WorkspaceStatus
+	sessions := m.sessionStates()
+	statuses := make([]WorkspaceStatus, 0, len(ids))
 	for _, id := range ids {
-		ws := reg.Workspaces[id]
-		alive, err := m.tmux.SessionExists(ws.TmuxSession)
-		if err != nil {
-			alive = false
-		}
-		hasClients := false
-		if alive {
-			hasClients, _ = m.tmux.HasAttachedClients(ws.TmuxSession)
-		}
-		statuses = append(statuses, WorkspaceStatus{
-			ID:           id,
-			Workspace:    ws,
-			SessionAlive: alive,
-			HasClients:   hasClients,
+		statuses = append(statuses, m.workspaceStatus(id, reg.Workspaces[id], sessions))
+	}
+
+	if opts.GitStatus {
+		forEachLimit(len(statuses), statusConcurrency, func(i int) {
+			callCtx, cancel := context.WithTimeout(ctx, statusCallTimeout)
+			defer cancel()
+			statuses[i].Git = collectGitStatus(callCtx, statuses[i].Workspace)
 		})
 	}
+	if opts.Activity {
+		m.collectActivity(statuses)
+	}
 
 	return statuses, nil
 }
 
+// sessionStates maps every tmux session to its attached client count,
+// using a single list-sessions call. It returns nil when tmux can't be
+// queried, in which case workspaceStatus asks about each session instead.
+func (m *Manager) sessionStates() map[string]int {
+	sessions, err := m.tmux.ListSessions()
+	if err != nil {
+		return nil
+	}
+	states := make(map[string]int, len(sessions))
+	for _, s := range sessions {
+		states[s.Name] = s.Attached
+	}
+	return states
+}
+
+// workspaceStatus reports a workspace's session state, from sessions when
+// it was listed and by asking tmux otherwise.
+func (m *Manager) workspaceStatus(id string, ws Workspace, sessions map[string]int) WorkspaceStatus {
+	st := WorkspaceStatus{ID: id, Workspace: ws}
+	if sessions != nil {
+		attached, ok := sessions[ws.TmuxSession]
+		st.SessionAlive = ok
+		st.HasClients = attached > 0
+		return st
+	}
+
+	alive, err := m.tmux.SessionExists(ws.TmuxSession)
+	if err != nil {
+		alive = false
+	}
+	st.SessionAlive = alive
+	if alive {
+		st.HasClients, _ = m.tmux.HasAttachedClients(ws.TmuxSession)
+	}
+	return st
+}
+
 func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOptions) error {
 	if err := m.checkDepsByName("git", "tmux"); err != nil {
 		return err
 	}
 
 	if err := validateName(id); err != nil {
-		return fmt.Errorf("invalid workspace identifier: %w", err)
+		return withCode(CodeInvalidArgument, fmt.Errorf("invalid workspace identifier: %w", err))
 	}
 
 	resolvedID, ws, err := m.lookupWorkspace(ctx, id)
@@ -468,18 +945,39 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 		return err
 	}
 
+	// Branches checked out with --checkout-existing or --from-pr belong to
+	// someone else; never delete them.
+	if ws.ExternalBranch {
+		opts.KeepBranch = true
+	}
+
 	// Track if branch is confirmed merged (via PR check) - used to force delete local branch
 	merged := false
 
+	// A fork PR's local copy is safe to delete unless commits were added to
+	// it that the PR doesn't have.
+	if ws.PRRef != "" && !opts.KeepBranch && !opts.Force {
+		if exists, _ := git.BranchExists(ws.RepoPath, ws.Branch); exists {
+			ahead, err := git.CommitsNotInRemoteRef(ctx, ws.RepoPath, ws.Branch, ws.PRRef)
+			if err != nil {
+				return err
+			}
+			if ahead > 0 {
+				return withCode(CodeUnpushedCommits, fmt.Errorf("branch %q has %d commits not in the pull request. Use --force/--keep-branch.", ws.Branch, ahead))
+			}
+		}
+		merged = true
+	}
+
 	// Run all safety checks BEFORE any destructive actions
-	if !opts.KeepBranch && !opts.Force {
+	if !opts.KeepBranch && !opts.Force && ws.PRRef == "" {
 		branchExists, _ := git.BranchExists(ws.RepoPath, ws.Branch)
 
 		if branchExists {
 			// Resolve base branch for error messages
 			baseBranch := ws.BaseBranch
 			if baseBranch == "" {
-				if detected, err := git.DetectDefaultBranch(ws.RepoPath); err == nil {
+				if detected, err := git.DetectDefaultBranch(ctx, ws.RepoPath); err == nil {
 					baseBranch = detected
 				}
 			}
@@ -489,13 +987,9 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 				return err
 			}
 
-			var prChecker git.MergeChecker
-			if !m.skipGitHubCheck {
-				// Check gh authentication before PR-based merge detection
-				if err := github.CheckAuthenticated(); err != nil {
-					return err
-				}
-				prChecker = m.getPRChecker(ws.RepoPath)
+			prChecker, err := m.getPRChecker(ctx, ws.RepoPath)
+			if err != nil {
+				return err
 			}
 			merged, err = git.IsMergedWithPR(ctx, ws.RepoPath, ws.Branch, ws.BaseBranch, false, prChecker)
 			if err != nil {
@@ -507,7 +1001,7 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 				if opts.ConfirmFunc != nil && opts.ConfirmFunc(msg, files) {
 					opts.Force = true
 				} else if opts.ConfirmFunc == nil {
-					return fmt.Errorf("branch %q is not merged into %q.\nUse --force to delete anyway, or --keep-branch to only remove the workspace.", ws.Branch, baseBranch)
+					return withCode(CodeBranchNotMerged, fmt.Errorf("branch %q is not merged into %q.\nUse --force to delete anyway, or --keep-branch to only remove the workspace.", ws.Branch, baseBranch))
 				} else {
 					return fmt.Errorf("aborted")
 				}
@@ -520,7 +1014,7 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 					return err
 				}
 				if unpushed {
-					return fmt.Errorf("branch %q has unpushed commits. Push or use --force/--keep-branch.", ws.Branch)
+					return withCode(CodeUnpushedCommits, fmt.Errorf("branch %q has unpushed commits. Push or use --force/--keep-branch.", ws.Branch))
 				}
 
 				remoteUnmerged, err := git.RemoteBranchHasUnmergedCommitsWithPR(ctx, ws.RepoPath, ws.Branch, ws.BaseBranch, prChecker)
@@ -533,7 +1027,7 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 					if opts.ConfirmFunc != nil && opts.ConfirmFunc(msg, files) {
 						opts.Force = true
 					} else if opts.ConfirmFunc == nil {
-						return fmt.Errorf("remote branch %q has commits not merged into %q.\nUse --force to delete anyway, or --keep-branch to only remove the workspace.", "origin/"+ws.Branch, baseBranch)
+						return withCode(CodeBranchNotMerged, fmt.Errorf("remote branch %q has commits not merged into %q.\nUse --force to delete anyway, or --keep-branch to only remove the workspace.", "origin/"+ws.Branch, baseBranch))
 					} else {
 						return fmt.Errorf("aborted")
 					}
@@ -542,12 +1036,26 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 		}
 	}
 
+	rc := m.existingRepoConfig(ws)
+	if err := m.runHooks(ctx, hooks.PreRemove, rc.Hooks.PreRemove, ws.WorktreePath, resolvedID, ws); err != nil {
+		if !opts.Force {
+			return withCode(CodeHookFailed, fmt.Errorf("%w\nUse --force to remove anyway.", err))
+		}
+		fmt.Fprintf(m.out, "warning: %v\n", err)
+	}
+
+	// Remember where the branch ends so stacked children can be rebased
+	// off it once it's gone.
+	tip, _ := git.ResolveRef(ctx, ws.RepoPath, ws.Branch)
+
 	// Now perform destructive actions
 	var errs []error
 
 	if !opts.KeepWorktree {
 		if err := git.RemoveWorktree(ws.RepoPath, ws.WorktreePath, true); err != nil {
 			errs = append(errs, fmt.Errorf("remove worktree: %w", err))
+		} else {
+			m.emit(Event{Type: EventWorktreeRemoved, Workspace: resolvedID, Path: ws.WorktreePath})
 		}
 	}
 
@@ -556,30 +1064,56 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 
 		if branchExists {
 			// Force delete if we verified branch is merged via PR (git -d may fail if remote is gone)
-			if err := git.DeleteBranch(ws.RepoPath, ws.Branch, opts.Force || merged); err != nil && !errors.Is(err, git.ErrBranchNotFound) {
+			if err := git.DeleteBranch(ws.RepoPath, ws.Branch, opts.Force || merged); err == nil {
+				m.emit(Event{Type: EventBranchDeleted, Workspace: resolvedID, Branch: ws.Branch})
+			} else if !errors.Is(err, git.ErrBranchNotFound) {
 				errs = append(errs, fmt.Errorf("delete branch: %w", err))
 			}
 		}
 
-		// Delete remote branch if it exists
-		if exists, _ := git.RemoteBranchExists(ws.RepoPath, "origin", ws.Branch); exists {
-			if err := git.DeleteRemoteBranch(ws.RepoPath, "origin", ws.Branch); err != nil {
-				errs = append(errs, fmt.Errorf("delete remote branch: %w", err))
+		// Delete remote branch if it exists. A fork PR's branch has none, so
+		// an origin branch of the same name is someone else's.
+		if ws.PRRef == "" {
+			if exists, _ := git.RemoteBranchExists(ws.RepoPath, "origin", ws.Branch); exists {
+				if err := m.retargetChildPRs(ctx, resolvedID, ws); err != nil {
+					fmt.Fprintf(m.out, "warning: kept origin/%s, which stacked pull requests still target: %v\n", ws.Branch, err)
+				} else if err := git.DeleteRemoteBranch(ws.RepoPath, "origin", ws.Branch); err != nil {
+					errs = append(errs, fmt.Errorf("delete remote branch: %w", err))
+				} else {
+					m.emit(Event{Type: EventRemoteBranchDeleted, Workspace: resolvedID, Branch: ws.Branch})
+				}
 			}
 		}
 	}
 
+	var retargeted []string
 	if err := m.regStore.Update(ctx, func(reg *Registry) error {
 		reg.Remove(resolvedID)
+		retargeted = reg.retargetChildren(resolvedID, ws, tip)
 		return nil
 	}); err != nil {
 		errs = append(errs, fmt.Errorf("update registry: %w", err))
+	} else {
+		m.emit(Event{Type: EventUnregistered, Workspace: resolvedID})
+	}
+	for _, child := range retargeted {
+		onto := ws.Parent
+		if onto == "" {
+			onto = ws.BaseBranch
+		}
+		if onto == "" {
+			onto = "the default branch"
+		}
+		fmt.Fprintf(m.out, "re-targeted %s onto %s; run `ccw sync %s` to rebase it\n", child, onto, child)
+		m.emit(Event{Type: EventRetargeted, Workspace: child, Parent: onto})
 	}
 
 	clientTTYs, _ := m.tmux.ClientTTYs(ws.TmuxSession)
 
 	// Kill tmux session LAST since ccw rm might be called from within the workspace
-	if err := m.tmux.KillSession(ws.TmuxSession); err != nil && !errors.Is(err, tmux.ErrSessionMissing) {
+	if err := m.tmux.KillSession(ws.TmuxSession); err == nil {
+		m.emit(Event{Type: EventSessionKilled, Workspace: resolvedID, Session: ws.TmuxSession})
+	} else if !errors.Is(err, tmux.ErrSessionMissing) {
 		errs = append(errs, fmt.Errorf("kill session: %w", err))
 	}
 
@@ -588,6 +1122,15 @@ func (m *Manager) RemoveWorkspace(ctx context.Context, id string, opts RemoveOpt
 	// Close the iTerm control window if it exists (best-effort, no error on failure)
 	tmux.CloseITermControlWindow(ws.TmuxSession)
 
+	if err := os.RemoveAll(m.LogDir(ws.TmuxSession)); err != nil {
+		errs = append(errs, fmt.Errorf("remove pane logs: %w", err))
+	}
+	os.Remove(m.statusFile(ws.TmuxSession))
+
+	if err := m.runHooks(ctx, hooks.PostRemove, rc.Hooks.PostRemove, ws.RepoPath, resolvedID, ws); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return combineErrors(errs)
 	}
@@ -608,7 +1151,7 @@ func combineErrors(errs []error) error {
 
 func (m *Manager) lookupWorkspace(ctx context.Context, query string) (string, Workspace, error) {
 	if err := validateName(query); err != nil {
-		return "", Workspace{}, fmt.Errorf("invalid workspace identifier: %w", err)
+		return "", Workspace{}, withCode(CodeInvalidArgument, fmt.Errorf("invalid workspace identifier: %w", err))
 	}
 
 	reg, err := m.regStore.Read(ctx)
@@ -630,7 +1173,7 @@ func (m *Manager) lookupWorkspace(ctx context.Context, query string) (string, Wo
 		sort.Strings(ids)
 
 		if idx > len(ids) {
-			return "", Workspace{}, fmt.Errorf("workspace index %d out of range (have %d workspaces)", idx, len(ids))
+			return "", Workspace{}, withCode(CodeWorkspaceNotFound, fmt.Errorf("workspace index %d out of range (have %d workspaces)", idx, len(ids)))
 		}
 		id := ids[idx-1] // 1-based index
 		return id, reg.Workspaces[id], nil
@@ -644,10 +1187,10 @@ func (m *Manager) lookupWorkspace(ctx context.Context, query string) (string, Wo
 	}
 
 	if len(matches) > 1 {
-		return "", Workspace{}, fmt.Errorf("multiple workspaces match: %s\nPlease specify a full workspace ID (repo/branch).", strings.Join(matches, ", "))
+		return "", Workspace{}, withCode(CodeAmbiguousWorkspace, fmt.Errorf("multiple workspaces match: %s\nPlease specify a full workspace ID (repo/branch).", strings.Join(matches, ", ")))
 	}
 
-	return "", Workspace{}, fmt.Errorf("workspace %s not found (try ccw ls)", query)
+	return "", Workspace{}, withCode(CodeWorkspaceNotFound, fmt.Errorf("workspace %s not found (try ccw ls)", query))
 }
 
 // FindCurrent identifies the workspace the caller is "inside" using, in order:
@@ -699,27 +1242,25 @@ func currentTmuxSession() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetWorkspace resolves query, a workspace name or index, from the registry
+// alone. Unlike WorkspaceInfo it runs no git, tmux or process checks.
+func (m *Manager) GetWorkspace(ctx context.Context, query string) (string, Workspace, error) {
+	return m.lookupWorkspace(ctx, query)
+}
+
 func (m *Manager) WorkspaceInfo(ctx context.Context, query string) (WorkspaceStatus, error) {
 	id, ws, err := m.lookupWorkspace(ctx, query)
 	if err != nil {
 		return WorkspaceStatus{}, err
 	}
 
-	alive, err := m.tmux.SessionExists(ws.TmuxSession)
-	if err != nil {
-		alive = false
-	}
-	hasClients := false
-	if alive {
-		hasClients, _ = m.tmux.HasAttachedClients(ws.TmuxSession)
-	}
-
-	return WorkspaceStatus{
-		ID:           id,
-		Workspace:    ws,
-		SessionAlive: alive,
-		HasClients:   hasClients,
-	}, nil
+	st := m.workspaceStatus(id, ws, nil)
+	callCtx, cancel := context.WithTimeout(ctx, statusCallTimeout)
+	defer cancel()
+	st.Git = collectGitStatus(callCtx, ws)
+	statuses := []WorkspaceStatus{st}
+	m.collectActivity(statuses)
+	return statuses[0], nil
 }
 
 func (m *Manager) StaleWorkspaces(ctx context.Context, force bool) ([]WorkspaceStatus, error) {
@@ -727,51 +1268,106 @@ func (m *Manager) StaleWorkspaces(ctx context.Context, force bool) ([]WorkspaceS
 		return nil, err
 	}
 
-	// Check gh authentication for PR-based merge detection (unless skipped)
-	if !m.skipGitHubCheck {
-		if err := github.CheckAuthenticated(); err != nil {
-			return nil, err
-		}
+	reg, err := m.regStore.Read(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	reg, err := m.regStore.Read(ctx)
+	ids := make([]string, 0, len(reg.Workspaces))
+	for id := range reg.Workspaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	checkers, err := m.mergeCheckers(ctx, reg.Workspaces)
 	if err != nil {
 		return nil, err
 	}
 
+	merged := make([]bool, len(ids))
+	errs := make([]error, len(ids))
+	forEachLimit(len(ids), statusConcurrency, func(i int) {
+		ws := reg.Workspaces[ids[i]]
+		callCtx, cancel := context.WithTimeout(ctx, statusCallTimeout)
+		defer cancel()
+		merged[i], errs[i] = git.IsMergedWithPR(callCtx, ws.RepoPath, ws.Branch, ws.BaseBranch, false, checkers[ws.RepoPath])
+	})
+
 	var results []WorkspaceStatus
-	for id, ws := range reg.Workspaces {
-		var prChecker git.MergeChecker
-		if !m.skipGitHubCheck {
-			prChecker = m.getPRChecker(ws.RepoPath)
-		}
-		merged, err := git.IsMergedWithPR(ctx, ws.RepoPath, ws.Branch, ws.BaseBranch, false, prChecker)
-		if err != nil {
+	var sessions map[string]int
+	for i, id := range ids {
+		if errs[i] != nil {
 			if force {
 				continue
 			}
+			return nil, errs[i]
+		}
+		if !merged[i] {
+			continue
+		}
+		if sessions == nil {
+			sessions = m.sessionStates()
+		}
+		results = append(results, m.workspaceStatus(id, reg.Workspaces[id], sessions))
+	}
+	return results, nil
+}
+
+// mergeCheckers returns a MergeChecker for each repo used by workspaces.
+// Forges that can list all their PRs at once are queried once per repo, in
+// parallel; the others are asked per branch. Repos with no forge, or when
+// forge lookups are disabled, map to nil and git heuristics decide.
+func (m *Manager) mergeCheckers(ctx context.Context, workspaces map[string]Workspace) (map[string]git.MergeChecker, error) {
+	checkers := make(map[string]git.MergeChecker)
+	if m.skipForgeCheck {
+		return checkers, nil
+	}
+
+	// checkForge caches per repo and isn't safe for concurrent use, so
+	// providers are resolved up front.
+	var repos []string
+	var listers []forge.MergeStatusLister
+	for _, ws := range workspaces {
+		if _, seen := checkers[ws.RepoPath]; seen {
+			continue
+		}
+		p, err := m.checkForge(ctx, ws.RepoPath)
+		if err != nil {
 			return nil, err
 		}
-		if merged {
-			alive, err := m.tmux.SessionExists(ws.TmuxSession)
-			if err != nil {
-				alive = false
-			}
-			hasClients := false
-			if alive {
-				hasClients, _ = m.tmux.HasAttachedClients(ws.TmuxSession)
-			}
-			results = append(results, WorkspaceStatus{
-				ID:           id,
-				Workspace:    ws,
-				SessionAlive: alive,
-				HasClients:   hasClients,
-			})
+		checkers[ws.RepoPath] = nil
+		if p.Kind() == forge.KindPlain {
+			continue
+		}
+		checkers[ws.RepoPath] = p.MergeStatus
+		if lister, ok := p.(forge.MergeStatusLister); ok {
+			repos = append(repos, ws.RepoPath)
+			listers = append(listers, lister)
 		}
 	}
 
-	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
-	return results, nil
+	statuses := make([]map[string]bool, len(repos))
+	forEachLimit(len(repos), statusConcurrency, func(i int) {
+		callCtx, cancel := context.WithTimeout(ctx, statusCallTimeout)
+		defer cancel()
+		// On failure the repo keeps its per-branch checker.
+		statuses[i], _ = listers[i].MergeStatuses(callCtx)
+	})
+	for i, repo := range repos {
+		if statuses[i] != nil {
+			checkers[repo] = listedMergeChecker(statuses[i])
+		}
+	}
+	return checkers, nil
+}
+
+// listedMergeChecker answers from a MergeStatuses listing. Branches missing
+// from it fall back to git heuristics.
+func listedMergeChecker(statuses map[string]bool) git.MergeChecker {
+	return func(ctx context.Context, branch string) (bool, bool, error) {
+		merged, found := statuses[branch]
+		return merged, found, nil
+	}
 }
 
 func (m *Manager) GetConfig() config.Config {
@@ -791,6 +1387,12 @@ func (m *Manager) SetConfigValue(key, value string) (config.Config, error) {
 			return cfg, fmt.Errorf("invalid claude_rename_delay: %w", err)
 		}
 		cfg.ClaudeRenameDelay = delay
+	case "agent_ready_timeout":
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout <= 0 {
+			return cfg, fmt.Errorf("invalid agent_ready_timeout %q (want a positive number of seconds)", value)
+		}
+		cfg.AgentReadyTimeout = timeout
 	case "layout.left":
 		cfg.Layout.Left = value
 	case "layout.right":
@@ -799,14 +1401,38 @@ func (m *Manager) SetConfigValue(key, value string) (config.Config, error) {
 		cfg.ClaudeDangerouslySkipPerms = strings.ToLower(value) == "true"
 	case "onboarded":
 		cfg.Onboarded = strings.ToLower(value) == "true"
+	case "sync_strategy":
+		value = strings.ToLower(value)
+		if value != "" && value != git.SyncRebase && value != git.SyncMerge {
+			return cfg, fmt.Errorf("invalid sync_strategy %q (want %s or %s)", value, git.SyncRebase, git.SyncMerge)
+		}
+		cfg.SyncStrategy = value
 	default:
-		return cfg, fmt.Errorf("unknown config key: %s", key)
+		host, ok := strings.CutPrefix(key, "forges.")
+		if !ok || host == "" {
+			return cfg, fmt.Errorf("unknown config key: %s", key)
+		}
+		host = strings.ToLower(host)
+		forges := make(map[string]string, len(cfg.Forges)+1)
+		for h, kind := range cfg.Forges {
+			forges[h] = kind
+		}
+		if value == "" {
+			delete(forges, host)
+		} else {
+			if _, err := forge.New(value, host, ""); err != nil {
+				return cfg, err
+			}
+			forges[host] = value
+		}
+		cfg.Forges = forges
 	}
 
 	if err := m.cfgStore.Save(cfg); err != nil {
 		return cfg, err
 	}
 	m.cfg = cfg
+	m.forges = nil
 	return cfg, nil
 }
 
@@ -819,26 +1445,6 @@ func (m *Manager) ResetConfig() (config.Config, error) {
 	return cfg, nil
 }
 
-func (m *Manager) claudeCapabilities(ctx context.Context) claude.Capabilities {
-	if m.capsDetected {
-		return m.claudeCaps
-	}
-
-	m.capsDetected = true
-	if m.skipDeps || os.Getenv("CCW_SKIP_DEPS") == "1" {
-		m.claudeCaps = claude.DefaultCapabilities()
-		return m.claudeCaps
-	}
-
-	caps, err := claude.DetectCapabilities(ctx)
-	if err != nil {
-		m.claudeCaps = claude.DefaultCapabilities()
-		return m.claudeCaps
-	}
-	m.claudeCaps = caps
-	return m.claudeCaps
-}
-
 func validateName(name string) error {
 	if name == "" {
 		return fmt.Errorf("name cannot be empty")
@@ -871,6 +1477,8 @@ func validateBranch(branch string) error {
 
 type rollback struct {
 	steps []func()
+	// done, if set, is called after the steps have been undone.
+	done func()
 }
 
 func (r *rollback) Add(fn func()) {
@@ -881,25 +1489,7 @@ func (r *rollback) Run() {
 	for i := len(r.steps) - 1; i >= 0; i-- {
 		r.steps[i]()
 	}
-}
-
-// copyFileIfExists copies src to dst if src exists. Returns nil if src doesn't exist.
-func copyFileIfExists(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
+	if r.done != nil && len(r.steps) > 0 {
+		r.done()
 	}
-	defer in.Close()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	return err
 }
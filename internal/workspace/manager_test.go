@@ -3,20 +3,58 @@ package workspace
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/forge"
 	"github.com/ccw/ccw/internal/git"
+	"github.com/ccw/ccw/internal/tmux"
 )
 
 type stubTmux struct {
+	// mu guards the calls ccw send makes from several goroutines.
+	mu         sync.Mutex
 	sessions   map[string]bool
+	paths      map[string]string
 	failCreate bool
 	failSplit  bool
 	clientTTYs []string
 	closedTTYs []string
+	splits     []stubSplit
+	sent       map[string][]string
+	nextPane   int
+	// attached holds client counts reported by ListSessions.
+	attached    map[string]int
+	existsCalls int
+	// panes holds what CapturePane returns for each target. afterSend
+	// replaces it when keys are sent to the target.
+	panes     map[string]string
+	afterSend map[string]string
+	captures  int
+	// piped maps pane targets to the commands PipePane sent them to.
+	piped map[string]string
+	// livePanes holds what Panes returns for each session. Sessions not in
+	// it report the panes created through the stub, in layout order.
+	livePanes map[string][]tmux.Pane
+	// order lists each session's pane targets in tmux's index order, and
+	// tags the name and launch command TagPane gave each target.
+	order map[string][]string
+	tags  map[string][2]string
+}
+
+type stubSplit struct {
+	target     string
+	horizontal bool
+	path       string
+	size       int
 }
 
 func newStubTmux() *stubTmux {
@@ -24,6 +62,9 @@ func newStubTmux() *stubTmux {
 }
 
 func (s *stubTmux) SessionExists(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.existsCalls++
 	return s.sessions[name], nil
 }
 
@@ -32,6 +73,14 @@ func (s *stubTmux) CreateSession(name, path string, detached bool) error {
 		return fmt.Errorf("create session fail")
 	}
 	s.sessions[name] = true
+	if s.paths == nil {
+		s.paths = map[string]string{}
+	}
+	s.paths[name] = path
+	if s.order == nil {
+		s.order = map[string][]string{}
+	}
+	s.order[name] = []string{name + ":0.0"}
 	return nil
 }
 
@@ -52,17 +101,48 @@ func (s *stubTmux) AttachSession(name string) error {
 	return nil
 }
 
-func (s *stubTmux) SplitPane(session string, horizontal bool, path string) error {
+func (s *stubTmux) SplitPane(target string, horizontal bool, path string, size int) (string, error) {
 	if s.failSplit {
-		return fmt.Errorf("split fail")
+		return "", fmt.Errorf("split fail")
+	}
+	if len(s.sessions) == 0 {
+		return "", fmt.Errorf("session missing")
+	}
+	s.splits = append(s.splits, stubSplit{target: target, horizontal: horizontal, path: path, size: size})
+	s.nextPane++
+	id := fmt.Sprintf("%%%d", s.nextPane)
+	for session, targets := range s.order {
+		for i, t := range targets {
+			if t == target {
+				s.order[session] = append(targets[:i+1:i+1], append([]string{id}, targets[i+1:]...)...)
+				return id, nil
+			}
+		}
 	}
-	if !s.sessions[session] {
-		return fmt.Errorf("session missing")
+	return id, nil
+}
+
+func (s *stubTmux) TagPane(target, name, launch string) error {
+	if s.tags == nil {
+		s.tags = map[string][2]string{}
 	}
+	s.tags[target] = [2]string{name, launch}
 	return nil
 }
 
-func (s *stubTmux) SendKeys(target string, keys []string, enter bool) error {
+func (s *stubTmux) SendText(target, text string, enter bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent == nil {
+		s.sent = map[string][]string{}
+	}
+	s.sent[target] = append(s.sent[target], text)
+	if content, ok := s.afterSend[target]; ok {
+		if s.panes == nil {
+			s.panes = map[string]string{}
+		}
+		s.panes[target] = content
+	}
 	return nil
 }
 
@@ -70,6 +150,44 @@ func (s *stubTmux) HasAttachedClients(session string) (bool, error) {
 	return false, nil
 }
 
+func (s *stubTmux) PipePane(target, command string) error {
+	if s.piped == nil {
+		s.piped = map[string]string{}
+	}
+	s.piped[target] = command
+	return nil
+}
+
+func (s *stubTmux) Panes(session string) ([]tmux.Pane, error) {
+	if live, ok := s.livePanes[session]; ok {
+		return live, nil
+	}
+	var panes []tmux.Pane
+	for i, target := range s.order[session] {
+		p := tmux.Pane{Index: i, Name: s.tags[target][0], Launch: s.tags[target][1]}
+		if strings.HasPrefix(target, "%") {
+			p.ID = target
+		}
+		panes = append(panes, p)
+	}
+	return panes, nil
+}
+
+func (s *stubTmux) CapturePane(target string, lines int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.captures++
+	return s.panes[target], nil
+}
+
+func (s *stubTmux) ListSessions() ([]tmux.Session, error) {
+	var sessions []tmux.Session
+	for name := range s.sessions {
+		sessions = append(sessions, tmux.Session{Name: name, Path: s.paths[name], Attached: s.attached[name]})
+	}
+	return sessions, nil
+}
+
 func initRepoForManager(t *testing.T) (string, string) {
 	t.Helper()
 	root := t.TempDir()
@@ -109,9 +227,15 @@ func newManagerForTest(t *testing.T, reposRoot string, tmuxRunner *stubTmux) *Ma
 		t.Fatalf("NewManager: %v", err)
 	}
 
+	// Keep claude conversation lookups away from the real ~/.claude.
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(root, "claude"))
+
 	mgr.cfg.ReposDir = reposRoot
 	mgr.skipDeps = true
-	mgr.skipGitHubCheck = true
+	mgr.skipForgeCheck = true
+	mgr.readyPoll = time.Millisecond
+	mgr.readySettle = 5 * time.Millisecond
+	mgr.processes = func() (map[int][]int, error) { return nil, nil }
 	if err := mgr.cfgStore.Save(mgr.cfg); err != nil {
 		t.Fatalf("save config: %v", err)
 	}
@@ -336,6 +460,63 @@ func TestStaleWorkspacesDetectsMerged(t *testing.T) {
 	}
 }
 
+func TestListWorkspacesListsSessionsOnce(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	ctx := context.Background()
+
+	var sessions []string
+	for _, branch := range []string{"feature/a", "feature/b", "feature/c"} {
+		ws, err := mgr.CreateWorkspace(ctx, repoName, branch, CreateOptions{NoFetch: true, NoAttach: true})
+		if err != nil {
+			t.Fatalf("CreateWorkspace %s: %v", branch, err)
+		}
+		sessions = append(sessions, ws.TmuxSession)
+	}
+	delete(tmuxStub.sessions, sessions[2])
+	tmuxStub.attached = map[string]int{sessions[0]: 1}
+	tmuxStub.existsCalls = 0
+
+	statuses, err := mgr.ListWorkspaces(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListWorkspaces: %v", err)
+	}
+	if tmuxStub.existsCalls != 0 {
+		t.Fatalf("expected no per-session tmux calls, got %d", tmuxStub.existsCalls)
+	}
+	want := []struct{ alive, clients bool }{{true, true}, {true, false}, {false, false}}
+	for i, st := range statuses {
+		if st.SessionAlive != want[i].alive || st.HasClients != want[i].clients {
+			t.Fatalf("%s: got alive=%v clients=%v, want %+v", st.ID, st.SessionAlive, st.HasClients, want[i])
+		}
+	}
+}
+
+func TestListedMergeChecker(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	for _, branch := range []string{"listed", "unlisted"} {
+		runGitCmd(t, repoPath, "checkout", "-b", branch, "main")
+		if err := os.WriteFile(filepath.Join(repoPath, branch+".txt"), []byte(branch), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		runGitCmd(t, repoPath, "add", ".")
+		runGitCmd(t, repoPath, "commit", "-m", branch)
+	}
+	runGitCmd(t, repoPath, "checkout", "main")
+
+	checker := listedMergeChecker(map[string]bool{"listed": true})
+	merged, err := git.IsMergedWithPR(context.Background(), repoPath, "listed", "main", false, checker)
+	if err != nil || !merged {
+		t.Fatalf("expected the listing to mark listed merged, got %v (%v)", merged, err)
+	}
+	merged, err = git.IsMergedWithPR(context.Background(), repoPath, "unlisted", "main", false, checker)
+	if err != nil || merged {
+		t.Fatalf("expected git heuristics for unlisted, got %v (%v)", merged, err)
+	}
+}
+
 func TestSetConfigValue(t *testing.T) {
 	reposRoot, _ := initRepoForManager(t)
 	tmuxStub := newStubTmux()
@@ -356,6 +537,28 @@ func TestSetConfigValue(t *testing.T) {
 	if !cfg.ClaudeDangerouslySkipPerms {
 		t.Fatalf("expected claude_dangerously_skip_permissions to be true")
 	}
+
+	cfg, err = mgr.SetConfigValue("sync_strategy", "Merge")
+	if err != nil {
+		t.Fatalf("SetConfigValue: %v", err)
+	}
+	if cfg.SyncStrategy != "merge" {
+		t.Fatalf("expected sync_strategy to be merge, got %q", cfg.SyncStrategy)
+	}
+	if _, err := mgr.SetConfigValue("sync_strategy", "squash"); err == nil {
+		t.Fatalf("expected an invalid sync_strategy to be rejected")
+	}
+
+	cfg, err = mgr.SetConfigValue("agent_ready_timeout", "60")
+	if err != nil || cfg.AgentReadyTimeout != 60 || cfg.ClaudeRenameDelay != config.Default().ClaudeRenameDelay {
+		t.Fatalf("agent_ready_timeout = %d, claude_rename_delay = %d, %v", cfg.AgentReadyTimeout, cfg.ClaudeRenameDelay, err)
+	}
+	if mgr.readyTimeout() != time.Minute {
+		t.Fatalf("readyTimeout = %s, want 1m", mgr.readyTimeout())
+	}
+	if _, err := mgr.SetConfigValue("agent_ready_timeout", "0"); err == nil {
+		t.Fatalf("expected a zero agent_ready_timeout to be rejected")
+	}
 }
 
 func TestCreateWorkspacePathTraversalValidation(t *testing.T) {
@@ -615,3 +818,232 @@ func TestRemoveWorkspace_MissingBranchStillCleansUp(t *testing.T) {
 		t.Fatal("expected registry entry to be removed")
 	}
 }
+
+func TestCreateWorkspaceBuildsConfiguredLayout(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{
+		{Name: "agent", Command: "claude"},
+		{Name: "tests", Command: "make watch", Split: config.SplitVertical, Size: 30, Dir: "sub"},
+		{Name: "shell", From: "agent", Size: 40},
+	}}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/layout", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	if got, want := len(tmuxStub.splits), 2; got != want {
+		t.Fatalf("expected %d splits, got %d", want, got)
+	}
+	first := ws.TmuxSession + ":0.0"
+	tests := tmuxStub.splits[0]
+	if tests.target != first || tests.horizontal || tests.size != 30 || tests.path != filepath.Join(ws.WorktreePath, "sub") {
+		t.Fatalf("unexpected tests split: %+v", tests)
+	}
+	shell := tmuxStub.splits[1]
+	if shell.target != first || !shell.horizontal || shell.size != 40 {
+		t.Fatalf("expected shell pane to split the agent pane: %+v", shell)
+	}
+
+	if keys := tmuxStub.sent[first]; len(keys) != 1 || !strings.HasPrefix(keys[0], "claude") {
+		t.Fatalf("expected claude in first pane, got %v", keys)
+	}
+	if keys := tmuxStub.sent["%1"]; len(keys) != 1 || keys[0] != "make watch" {
+		t.Fatalf("expected watcher command in tests pane, got %v", keys)
+	}
+	if keys := tmuxStub.sent["%2"]; len(keys) != 0 {
+		t.Fatalf("expected shell pane to stay idle, got %v", keys)
+	}
+}
+
+func TestCreateWorkspaceRejectsInvalidLayout(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{
+		{Name: "agent", Command: "claude"},
+		{Name: "agent", Command: "codex"},
+	}}
+
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/layout", CreateOptions{NoFetch: true, NoAttach: true}); err == nil {
+		t.Fatalf("expected duplicate pane names to be rejected")
+	}
+
+	exists, err := git.BranchExists(filepath.Join(reposRoot, repoName), "feature/layout")
+	if err != nil {
+		t.Fatalf("BranchExists: %v", err)
+	}
+	if exists {
+		t.Fatalf("branch should be removed on rollback")
+	}
+}
+
+func TestCreateWorkspaceLaunchesConfiguredAgents(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.agents = agent.FromConfig(map[string]config.AgentConfig{
+		"aider":  {Command: "aider --no-auto-commits"},
+		"gemini": {Command: "gemini", Optional: true},
+	})
+	mgr.agentAvailable["gemini"] = false
+	mgr.cfg.Layout = config.Layout{Left: "aider", Right: "gemini"}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/agents", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	if keys := tmuxStub.sent[ws.TmuxSession+":0.0"]; len(keys) != 1 || keys[0] != "aider --no-auto-commits" {
+		t.Fatalf("expected aider launch command, got %v", keys)
+	}
+	if keys := tmuxStub.sent["%1"]; len(keys) != 0 {
+		t.Fatalf("expected missing optional agent to be skipped, got %v", keys)
+	}
+}
+
+func TestCreateWorkspaceRunsPostCreateHook(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.SetOutput(io.Discard)
+	mgr.cfg.Repos = map[string]config.RepoConfig{
+		repoName: {Hooks: config.Hooks{PostCreate: []config.Hook{{Run: `echo "$CCW_WORKSPACE_ID $CCW_BASE_BRANCH" > hook.txt`}}}},
+	}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/hook", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ws.WorktreePath, "hook.txt"))
+	if err != nil {
+		t.Fatalf("expected hook to run in worktree: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "demo/feature/hook main" {
+		t.Fatalf("unexpected hook env: %q", got)
+	}
+}
+
+func TestCreateWorkspaceRollsBackOnPostCreateHookFailure(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.SetOutput(io.Discard)
+	mgr.cfg.Repos = map[string]config.RepoConfig{
+		repoName: {Hooks: config.Hooks{PostCreate: []config.Hook{{Run: "exit 1"}}}},
+	}
+
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/hook", CreateOptions{NoFetch: true, NoAttach: true}); err == nil {
+		t.Fatalf("expected post-create hook failure")
+	}
+
+	exists, err := git.BranchExists(filepath.Join(reposRoot, repoName), "feature/hook")
+	if err != nil {
+		t.Fatalf("BranchExists: %v", err)
+	}
+	if exists {
+		t.Fatalf("branch should be removed on rollback")
+	}
+	if len(tmuxStub.sessions) != 0 {
+		t.Fatalf("expected no tmux session after rollback")
+	}
+}
+
+func TestRemoveWorkspaceAbortsOnPreRemoveHookFailure(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.SetOutput(io.Discard)
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/hook", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	marker := filepath.Join(t.TempDir(), "post-remove")
+	mgr.cfg.Repos = map[string]config.RepoConfig{
+		repoName: {Hooks: config.Hooks{
+			PreRemove:  []config.Hook{{Run: "exit 1"}},
+			PostRemove: []config.Hook{{Run: "touch " + marker}},
+		}},
+	}
+
+	id := WorkspaceID(repoName, "feature/hook")
+	if err := mgr.RemoveWorkspace(context.Background(), id, RemoveOptions{KeepBranch: true}); err == nil {
+		t.Fatalf("expected pre-remove hook failure to abort removal")
+	}
+	if _, err := os.Stat(ws.WorktreePath); err != nil {
+		t.Fatalf("expected worktree to remain: %v", err)
+	}
+
+	if err := mgr.RemoveWorkspace(context.Background(), id, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("forced RemoveWorkspace: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("expected post-remove hook to run: %v", err)
+	}
+}
+
+func TestCreateWorkspaceUsesRepoLocalConfig(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	runGitCmd(t, repoPath, "branch", "develop")
+	runGitCmd(t, repoPath, "push", "origin", "develop")
+
+	repoFile := `{
+  "copy_files": ["shared.txt"],
+  "base_branch": "develop",
+  "layout": {"panes": [{"name": "agent", "command": "claude"}, {"name": "dev", "command": "npm run dev"}]}
+}`
+	if err := os.WriteFile(filepath.Join(repoPath, config.RepoFileName), []byte(repoFile), 0o644); err != nil {
+		t.Fatalf("write repo file: %v", err)
+	}
+	for _, name := range []string{"shared.txt", "mine.txt"} {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.cfg.Repos = map[string]config.RepoConfig{repoName: {CopyFiles: []config.CopyFile{{Path: "mine.txt"}}}}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/shared", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	if ws.BaseBranch != "develop" {
+		t.Fatalf("expected base branch from repo file, got %q", ws.BaseBranch)
+	}
+	for _, name := range []string{"shared.txt", "mine.txt"} {
+		if _, err := os.Stat(filepath.Join(ws.WorktreePath, name)); err != nil {
+			t.Fatalf("expected %s to be copied: %v", name, err)
+		}
+	}
+	if keys := tmuxStub.sent["%1"]; len(keys) != 1 || keys[0] != "npm run dev" {
+		t.Fatalf("expected repo layout to be used, got %v", keys)
+	}
+}
+
+func TestCreateWorkspaceAllowsPlainGitRemote(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	// The test origin is a local bare repo, so no forge CLI should be needed.
+	mgr.skipForgeCheck = false
+
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/plain", CreateOptions{NoFetch: true, NoAttach: true}); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+
+	p, err := mgr.forgeFor(filepath.Join(reposRoot, repoName))
+	if err != nil {
+		t.Fatalf("forgeFor: %v", err)
+	}
+	if p.Kind() != forge.KindPlain {
+		t.Fatalf("expected plain git forge, got %s", p.Kind())
+	}
+}
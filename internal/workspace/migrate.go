@@ -0,0 +1,24 @@
+package workspace
+
+import "github.com/ccw/ccw/internal/storage"
+
+// registryMigrations upgrade workspaces.json one version at a time. Append a
+// migration here whenever CurrentVersion is bumped.
+var registryMigrations = []storage.Migration{
+	{From: 1, Apply: migrateRegistryV1},
+}
+
+// migrateRegistryV1 moves to v2, which adds tags, notes, agent session IDs
+// and archived state. Only notes need a value: the prompt a workspace was
+// created with becomes its description.
+func migrateRegistryV1(doc storage.Document) error {
+	for _, ws := range doc.Objects("workspaces") {
+		if notes, _ := ws["notes"].(string); notes != "" {
+			continue
+		}
+		if msg, _ := ws["message"].(string); msg != "" {
+			ws["notes"] = msg
+		}
+	}
+	return nil
+}
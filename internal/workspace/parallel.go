@@ -0,0 +1,32 @@
+package workspace
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// statusConcurrency bounds how many workspaces (or repos) are inspected
+	// at once.
+	statusConcurrency = 8
+	// statusCallTimeout caps the git and forge calls made for one workspace
+	// or repo, so a single hung CLI can't stall a whole listing.
+	statusCallTimeout = 30 * time.Second
+)
+
+// forEachLimit calls fn for 0..n-1 on at most limit goroutines and waits
+// for all of them.
+func forEachLimit(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
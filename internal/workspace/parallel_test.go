@@ -0,0 +1,30 @@
+package workspace
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachLimit(t *testing.T) {
+	results := make([]int, 50)
+	var running, peak atomic.Int32
+	forEachLimit(len(results), 4, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		results[i] = i * i
+		running.Add(-1)
+	})
+	for i, r := range results {
+		if r != i*i {
+			t.Fatalf("result %d not filled in: %d", i, r)
+		}
+	}
+	if peak.Load() > 4 {
+		t.Fatalf("expected at most 4 concurrent calls, saw %d", peak.Load())
+	}
+}
@@ -0,0 +1,167 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccw/ccw/internal/forge"
+	"github.com/ccw/ccw/internal/git"
+)
+
+type PROptions struct {
+	// Title and Body override the ones derived from the workspace's
+	// message and commits.
+	Title string
+	Body  string
+	Draft bool
+}
+
+// CreatePR pushes the workspace branch and opens a PR/MR from it into the
+// base branch. The PR is recorded on the workspace.
+func (m *Manager) CreatePR(ctx context.Context, query string, opts PROptions) (forge.PullRequest, error) {
+	if err := m.checkDepsByName("git"); err != nil {
+		return forge.PullRequest{}, err
+	}
+
+	id, ws, err := m.lookupWorkspace(ctx, query)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	if ws.PR != nil && (ws.PR.State == forge.PRStateOpen || ws.PR.State == forge.PRStateDraft) {
+		return forge.PullRequest{}, fmt.Errorf("workspace %s already has PR #%d: %s", id, ws.PR.Number, ws.PR.URL)
+	}
+
+	p, err := m.checkForge(ctx, ws.RepoPath)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+
+	base := ws.BaseBranch
+	if base == "" {
+		if base, err = git.DetectDefaultBranch(ctx, ws.RepoPath); err != nil {
+			return forge.PullRequest{}, err
+		}
+	}
+
+	subjects, err := git.CommitSubjects(ws.RepoPath, ws.Branch, base)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	if len(subjects) == 0 {
+		return forge.PullRequest{}, fmt.Errorf("branch %q has no commits on top of %q", ws.Branch, base)
+	}
+
+	if err := git.PushBranch(ws.RepoPath, ws.Branch); err != nil {
+		return forge.PullRequest{}, fmt.Errorf("push %s: %w", ws.Branch, err)
+	}
+
+	title, body := prContent(ws, subjects)
+	if opts.Title != "" {
+		title = opts.Title
+	}
+	if opts.Body != "" {
+		body = opts.Body
+	}
+
+	pr, err := p.CreatePR(ctx, forge.CreatePROptions{
+		Head:  ws.Branch,
+		Base:  base,
+		Title: title,
+		Body:  body,
+		Draft: opts.Draft,
+	})
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	return pr, m.recordPR(ctx, id, pr)
+}
+
+// ViewPR fetches the workspace's PR/MR and refreshes the stored state. A PR
+// opened outside ccw is found by branch and recorded too.
+func (m *Manager) ViewPR(ctx context.Context, query string) (forge.PullRequest, error) {
+	id, ws, err := m.lookupWorkspace(ctx, query)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	p, err := m.checkForge(ctx, ws.RepoPath)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+
+	pr, err := p.ViewPR(ctx, prRef(ws))
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	return pr, m.recordPR(ctx, id, pr)
+}
+
+// OpenPR shows the workspace's PR/MR in the browser.
+func (m *Manager) OpenPR(ctx context.Context, query string) error {
+	_, ws, err := m.lookupWorkspace(ctx, query)
+	if err != nil {
+		return err
+	}
+	p, err := m.checkForge(ctx, ws.RepoPath)
+	if err != nil {
+		return err
+	}
+	return p.OpenPR(ctx, prRef(ws))
+}
+
+func (m *Manager) recordPR(ctx context.Context, id string, pr forge.PullRequest) error {
+	now := time.Now().UTC()
+	return m.regStore.Update(ctx, func(reg *Registry) error {
+		ws, ok := reg.Workspaces[id]
+		if !ok {
+			return fmt.Errorf("workspace %s not found", id)
+		}
+		ws.PR = &PullRequest{
+			Number:    pr.Number,
+			URL:       pr.URL,
+			State:     pr.State,
+			CheckedAt: now,
+		}
+		reg.Workspaces[id] = ws
+		return nil
+	})
+}
+
+// prRef is how a workspace's PR is looked up: by number once known,
+// otherwise by branch.
+func prRef(ws Workspace) string {
+	if ws.PR != nil && ws.PR.Number > 0 {
+		return strconv.Itoa(ws.PR.Number)
+	}
+	return ws.Branch
+}
+
+// prContent derives a PR title and body. The initial `ccw new -m` message
+// describes the intent best, so it wins; otherwise a single commit provides
+// both, and several commits are listed under the branch name.
+func prContent(ws Workspace, subjects []string) (title, body string) {
+	var commits strings.Builder
+	for _, s := range subjects {
+		fmt.Fprintf(&commits, "- %s\n", s)
+	}
+
+	if msg := strings.TrimSpace(ws.Message); msg != "" {
+		title, _, _ = strings.Cut(msg, "\n")
+		return truncateTitle(title), msg + "\n\n## Commits\n\n" + commits.String()
+	}
+	if len(subjects) == 1 {
+		return truncateTitle(subjects[0]), ""
+	}
+	return ws.Branch, commits.String()
+}
+
+func truncateTitle(s string) string {
+	const max = 72
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= max {
+		return string(r)
+	}
+	return strings.TrimSpace(string(r[:max-3])) + "..."
+}
@@ -0,0 +1,271 @@
+package workspace
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/deps"
+	"github.com/ccw/ccw/internal/forge"
+	"github.com/ccw/ccw/internal/git"
+)
+
+// stubForge is a forge.Provider that records the PR it was asked to open.
+type stubForge struct {
+	created []forge.CreatePROptions
+	pr      forge.PullRequest
+	viewed  []string
+	// retargeted records RetargetPRs calls as "head from to".
+	retargeted []string
+}
+
+func (s *stubForge) Kind() string                        { return forge.KindGitHub }
+func (s *stubForge) Host() string                        { return "github.com" }
+func (s *stubForge) Dependency() (deps.Dependency, bool) { return deps.Dependency{}, false }
+func (s *stubForge) CheckAuth(ctx context.Context) error { return nil }
+
+func (s *stubForge) MergeStatus(ctx context.Context, branch string) (bool, bool, error) {
+	return s.pr.State == forge.PRStateMerged, s.pr.Number > 0, nil
+}
+
+func (s *stubForge) CreatePR(ctx context.Context, opts forge.CreatePROptions) (forge.PullRequest, error) {
+	s.created = append(s.created, opts)
+	return s.pr, nil
+}
+
+func (s *stubForge) ViewPR(ctx context.Context, ref string) (forge.PullRequest, error) {
+	s.viewed = append(s.viewed, ref)
+	return s.pr, nil
+}
+
+func (s *stubForge) OpenPR(ctx context.Context, ref string) error { return nil }
+
+func (s *stubForge) RetargetPRs(ctx context.Context, head, from, to string) error {
+	s.retargeted = append(s.retargeted, head+" "+from+" "+to)
+	return nil
+}
+
+func TestCreatePRRecordsPullRequest(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/pr", CreateOptions{
+		NoFetch:  true,
+		NoAttach: true,
+		Message:  "Add login page\n\nUse the new auth flow.",
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	runGitCmd(t, ws.WorktreePath, "commit", "--allow-empty", "-m", "add login form")
+
+	stub := &stubForge{pr: forge.PullRequest{Number: 42, URL: "https://github.com/o/r/pull/42", State: forge.PRStateOpen}}
+	mgr.forges = map[string]forge.Provider{ws.RepoPath: stub}
+
+	id := WorkspaceID(repoName, "feature/pr")
+	pr, err := mgr.CreatePR(context.Background(), id, PROptions{})
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if pr.Number != 42 {
+		t.Fatalf("unexpected PR: %+v", pr)
+	}
+
+	if len(stub.created) != 1 {
+		t.Fatalf("expected one PR to be created, got %d", len(stub.created))
+	}
+	opts := stub.created[0]
+	if opts.Head != "feature/pr" || opts.Base != "main" || opts.Title != "Add login page" {
+		t.Fatalf("unexpected create options: %+v", opts)
+	}
+	if !strings.Contains(opts.Body, "- add login form") {
+		t.Fatalf("expected commits in body, got %q", opts.Body)
+	}
+
+	reg, err := mgr.regStore.Read(context.Background())
+	if err != nil {
+		t.Fatalf("read registry: %v", err)
+	}
+	stored := reg.Workspaces[id].PR
+	if stored == nil || stored.Number != 42 || stored.State != forge.PRStateOpen {
+		t.Fatalf("expected PR to be recorded, got %+v", stored)
+	}
+
+	if _, err := mgr.CreatePR(context.Background(), id, PROptions{}); err == nil {
+		t.Fatalf("expected second CreatePR to fail while the PR is open")
+	}
+
+	stub.pr.State = forge.PRStateMerged
+	if _, err := mgr.ViewPR(context.Background(), id); err != nil {
+		t.Fatalf("ViewPR: %v", err)
+	}
+	if stub.viewed[0] != "42" {
+		t.Fatalf("expected lookup by PR number, got %q", stub.viewed[0])
+	}
+	reg, _ = mgr.regStore.Read(context.Background())
+	if reg.Workspaces[id].PR.State != forge.PRStateMerged {
+		t.Fatalf("expected stored state to be refreshed, got %+v", reg.Workspaces[id].PR)
+	}
+}
+
+func TestCreatePRRequiresCommits(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/empty", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	stub := &stubForge{}
+	mgr.forges = map[string]forge.Provider{ws.RepoPath: stub}
+
+	if _, err := mgr.CreatePR(context.Background(), WorkspaceID(repoName, "feature/empty"), PROptions{}); err == nil {
+		t.Fatalf("expected error for branch without commits")
+	}
+	if len(stub.created) != 0 {
+		t.Fatalf("expected no PR to be created")
+	}
+}
+
+func TestPRContent(t *testing.T) {
+	title, body := prContent(Workspace{Branch: "feature/x"}, []string{"Fix the thing"})
+	if title != "Fix the thing" || body != "" {
+		t.Fatalf("single commit: title=%q body=%q", title, body)
+	}
+
+	title, body = prContent(Workspace{Branch: "feature/x"}, []string{"one", "two"})
+	if title != "feature/x" || body != "- one\n- two\n" {
+		t.Fatalf("several commits: title=%q body=%q", title, body)
+	}
+
+	long := strings.Repeat("a", 100)
+	title, _ = prContent(Workspace{Branch: "feature/x", Message: long}, []string{"one"})
+	if len(title) != 72 || !strings.HasSuffix(title, "...") {
+		t.Fatalf("expected truncated title, got %q", title)
+	}
+}
+
+func TestCreateWorkspaceFromPRKeepsBranchOnRemove(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	runGitCmd(t, repoPath, "checkout", "-b", "colleague/fix")
+	runGitCmd(t, repoPath, "commit", "--allow-empty", "-m", "their fix")
+	runGitCmd(t, repoPath, "push", "origin", "colleague/fix")
+	runGitCmd(t, repoPath, "checkout", "main")
+	runGitCmd(t, repoPath, "branch", "-D", "colleague/fix")
+
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	stub := &stubForge{pr: forge.PullRequest{
+		Number:     5,
+		URL:        "https://github.com/o/r/pull/5",
+		State:      forge.PRStateOpen,
+		HeadBranch: "colleague/fix",
+		BaseBranch: "main",
+	}}
+	mgr.forges = map[string]forge.Provider{repoPath: stub}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "", CreateOptions{NoAttach: true, FromPR: 5})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if ws.Branch != "colleague/fix" || ws.BaseBranch != "main" || !ws.ExternalBranch {
+		t.Fatalf("unexpected workspace: %+v", ws)
+	}
+	if ws.PR == nil || ws.PR.Number != 5 {
+		t.Fatalf("expected PR to be recorded, got %+v", ws.PR)
+	}
+
+	id := WorkspaceID(repoName, "colleague/fix")
+	if err := mgr.RemoveWorkspace(context.Background(), id, RemoveOptions{}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if exists, _ := git.BranchExists(repoPath, "colleague/fix"); !exists {
+		t.Fatal("expected local branch to be kept")
+	}
+	if exists, _ := git.RemoteBranchExists(repoPath, "origin", "colleague/fix"); !exists {
+		t.Fatal("expected remote branch to be kept")
+	}
+}
+
+func TestCreateWorkspaceFromForkPR(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	runGitCmd(t, repoPath, "push", "origin", "HEAD:refs/pull/9/head")
+
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	mgr.forges = map[string]forge.Provider{repoPath: &stubForge{pr: forge.PullRequest{
+		Number:          9,
+		State:           forge.PRStateOpen,
+		HeadBranch:      "main",
+		BaseBranch:      "main",
+		CrossRepository: true,
+		FetchRef:        "refs/pull/9/head",
+	}}}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "", CreateOptions{NoAttach: true, FromPR: 9})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if ws.Branch != "pr/9" {
+		t.Fatalf("expected fork PR to be checked out as pr/9, got %q", ws.Branch)
+	}
+	if ws.ExternalBranch || ws.PRRef != "refs/pull/9/head" {
+		t.Fatalf("expected pr/9 to be ccw's own branch, got %+v", ws)
+	}
+	ctx := context.Background()
+	id := WorkspaceID(repoName, "pr/9")
+
+	// Local commits the PR lacks block removal.
+	runGitCmd(t, ws.WorktreePath, "commit", "--allow-empty", "-m", "local tweak")
+	if err := mgr.RemoveWorkspace(ctx, id, RemoveOptions{}); ErrorCode(err) != CodeUnpushedCommits {
+		t.Fatalf("expected local commits to block removal, got %v", err)
+	}
+	if err := mgr.RemoveWorkspace(ctx, id, RemoveOptions{KeepBranch: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+
+	// The author force-pushes; the stale pr/9 must not stop a new checkout.
+	runGitCmd(t, repoPath, "checkout", "-b", "rewrite")
+	runGitCmd(t, repoPath, "commit", "--allow-empty", "-m", "rewritten")
+	runGitCmd(t, repoPath, "push", "--force", "origin", "HEAD:refs/pull/9/head")
+	runGitCmd(t, repoPath, "checkout", "main")
+	if ws, err = mgr.CreateWorkspace(ctx, repoName, "", CreateOptions{NoAttach: true, FromPR: 9}); err != nil {
+		t.Fatalf("CreateWorkspace after force-push: %v", err)
+	}
+	got, _ := git.ResolveRef(ctx, repoPath, "pr/9")
+	want, _ := git.ResolveRef(ctx, repoPath, "rewrite")
+	if got != want {
+		t.Fatalf("expected pr/9 at the new PR head %s, got %s", want, got)
+	}
+
+	if err := mgr.RemoveWorkspace(ctx, id, RemoveOptions{}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if exists, _ := git.BranchExists(repoPath, "pr/9"); exists {
+		t.Fatal("expected ccw's pr/9 branch to be deleted")
+	}
+}
+
+func TestCreateWorkspaceCheckoutExisting(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	repoPath := filepath.Join(reposRoot, repoName)
+	runGitCmd(t, repoPath, "branch", "existing")
+
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+
+	if _, err := mgr.CreateWorkspace(context.Background(), repoName, "existing", CreateOptions{NoFetch: true, NoAttach: true}); err == nil {
+		t.Fatal("expected plain create to refuse an existing branch")
+	}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "existing", CreateOptions{NoFetch: true, NoAttach: true, CheckoutExisting: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if !ws.ExternalBranch {
+		t.Fatal("expected workspace to be marked as using an external branch")
+	}
+	if exists, _ := git.RemoteBranchExists(repoPath, "origin", "existing"); exists {
+		t.Fatal("expected existing branch not to be pushed")
+	}
+}
@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/git"
+	"github.com/ccw/ccw/internal/prompts"
+)
+
+// loadPrompt reads the initial prompt CreateOptions asks for. A prompt file
+// or template still needs expanding with renderPrompt; an inline Message is
+// used as is.
+func (m *Manager) loadPrompt(opts CreateOptions) (text string, isTemplate bool, err error) {
+	sources := 0
+	for _, set := range []bool{opts.Message != "", opts.PromptFile != "", opts.PromptTemplate != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", false, withCode(CodeInvalidArgument, fmt.Errorf("pass only one of a message, a prompt file or a prompt template"))
+	}
+
+	switch {
+	case opts.PromptFile != "":
+		data, err := os.ReadFile(opts.PromptFile)
+		if err != nil {
+			return "", false, withCode(CodeInvalidArgument, fmt.Errorf("read prompt file: %w", err))
+		}
+		return string(data), true, nil
+	case opts.PromptTemplate != "":
+		text, err := prompts.Load(prompts.Dir(m.root), opts.PromptTemplate)
+		if err != nil {
+			return "", false, withCode(CodeInvalidArgument, err)
+		}
+		return text, true, nil
+	}
+	return opts.Message, false, nil
+}
+
+// renderPrompt expands a prompt file or template for a new workspace. It
+// can use vars plus Repo, Branch, BaseBranch and Workspace, which take
+// precedence.
+func renderPrompt(ctx context.Context, text string, vars map[string]string, id string, ws Workspace) (string, error) {
+	base := ws.BaseBranch
+	if base == "" {
+		if detected, err := git.DetectDefaultBranch(ctx, ws.RepoPath); err == nil {
+			base = detected
+		}
+	}
+
+	data := make(map[string]string, len(vars)+4)
+	for k, v := range vars {
+		data[k] = v
+	}
+	data["Repo"] = ws.Repo
+	data["Branch"] = ws.Branch
+	data["BaseBranch"] = base
+	data["Workspace"] = id
+
+	out, err := prompts.Render(text, data)
+	if err != nil {
+		return "", withCode(CodeInvalidArgument, err)
+	}
+	return out, nil
+}
+
+// promptPane picks the pane that receives the initial prompt: the first one
+// running an installed agent. Returns -1 when no pane runs an agent.
+func (m *Manager) promptPane(registry *agent.Registry, panes []config.Pane) (int, agent.Agent) {
+	for i, p := range panes {
+		a, ok := registry.Get(p.Command)
+		if !ok || m.agentMissing(a) {
+			continue
+		}
+		return i, a
+	}
+	return -1, nil
+}
+
+// PromptsDir is where named prompt templates are kept.
+func (m *Manager) PromptsDir() string {
+	return prompts.Dir(m.root)
+}
+
+// PromptTemplates lists the named prompt templates.
+func (m *Manager) PromptTemplates() ([]string, error) {
+	return prompts.List(m.PromptsDir())
+}
@@ -0,0 +1,148 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/prompts"
+)
+
+func TestCreateWorkspacePassesMessageToAgent(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{
+		{Name: "shell"},
+		{Name: "agent", Command: "claude"},
+		{Name: "side", Command: "codex"},
+	}}
+	mgr.agentAvailable["codex"] = true
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/prompt", CreateOptions{
+		NoFetch:  true,
+		NoAttach: true,
+		Message:  "fix the user's bug",
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if ws.Message != "fix the user's bug" {
+		t.Fatalf("Message = %q", ws.Message)
+	}
+
+	keys := tmuxStub.sent["%1"]
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "claude") || !strings.HasSuffix(keys[0], ` 'fix the user'\''s bug'`) {
+		t.Fatalf("expected prompt argument on the claude pane, got %v", keys)
+	}
+	if keys := tmuxStub.sent["%2"]; len(keys) != 1 || strings.Contains(keys[0], "bug") {
+		t.Fatalf("expected only the first agent pane to get the prompt, got %v", keys)
+	}
+}
+
+func TestCreateWorkspaceTypesPromptIntoPlainAgents(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.agents = agent.FromConfig(map[string]config.AgentConfig{
+		"aider": {Command: "aider", ReadyMarkers: []string{"> "}},
+	})
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{{Name: "agent", Command: "aider"}}}
+	session := SafeName(repoName, "feature/typed")
+	tmuxStub.panes = map[string]string{session + ":0.0": "aider v0.80\n> "}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/typed", CreateOptions{
+		NoFetch:  true,
+		NoAttach: true,
+		Message:  "add tests",
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	keys := tmuxStub.sent[ws.TmuxSession+":0.0"]
+	if len(keys) != 2 || keys[0] != "aider" || keys[1] != "add tests" {
+		t.Fatalf("expected launch then typed prompt, got %v", keys)
+	}
+}
+
+func TestCreateWorkspaceSkipsTypedPromptWithoutReadyMarkers(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	var out bytes.Buffer
+	mgr.SetOutput(&out)
+	mgr.agents = agent.FromConfig(map[string]config.AgentConfig{
+		"aider": {Command: "aider"},
+	})
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{{Name: "agent", Command: "aider"}}}
+	// All the pane shows is the shell with the launch command, which
+	// settles like an agent waiting for input.
+	session := SafeName(repoName, "feature/unmarked")
+	tmuxStub.panes = map[string]string{session + ":0.0": "$ aider"}
+
+	ws, err := mgr.CreateWorkspace(context.Background(), repoName, "feature/unmarked", CreateOptions{
+		NoFetch:  true,
+		NoAttach: true,
+		Message:  "add tests",
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if keys := tmuxStub.sent[ws.TmuxSession+":0.0"]; len(keys) != 1 || keys[0] != "aider" {
+		t.Fatalf("expected only the launch command, got %v", keys)
+	}
+	if !strings.Contains(out.String(), "no ready_markers") {
+		t.Fatalf("expected a warning about ready_markers, got %q", out.String())
+	}
+}
+
+func TestCreateWorkspaceRendersPromptTemplate(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	tmuxStub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, tmuxStub)
+	mgr.cfg.Layout = config.Layout{Panes: []config.Pane{{Name: "agent", Command: "claude"}}}
+
+	dir := prompts.Dir(mgr.root)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fix-issue.md"), []byte("Fix issue #{{.Issue}} in {{.Repo}} on {{.Branch}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "fix/1", CreateOptions{NoFetch: true, NoAttach: true, PromptTemplate: "fix-issue"}); err == nil {
+		t.Fatalf("expected error for missing template variable")
+	} else if ErrorCode(err) != CodeInvalidArgument {
+		t.Fatalf("missing variable: code %q (%v)", ErrorCode(err), err)
+	}
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "fix/42", CreateOptions{
+		NoFetch:        true,
+		NoAttach:       true,
+		PromptTemplate: "fix-issue",
+		PromptVars:     map[string]string{"Issue": "42"},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	want := "Fix issue #42 in " + repoName + " on fix/42"
+	if ws.Message != want {
+		t.Fatalf("Message = %q, want %q", ws.Message, want)
+	}
+	if ws.Notes != want {
+		t.Fatalf("Notes = %q, want the rendered prompt %q", ws.Notes, want)
+	}
+	if keys := tmuxStub.sent[ws.TmuxSession+":0.0"]; len(keys) != 1 || !strings.HasSuffix(keys[0], " '"+want+"'") {
+		t.Fatalf("expected rendered prompt on the claude pane, got %v", keys)
+	}
+
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "fix/43", CreateOptions{NoFetch: true, NoAttach: true, Message: "x", PromptTemplate: "fix-issue"}); err == nil {
+		t.Fatalf("expected error for two prompt sources")
+	}
+}
@@ -0,0 +1,97 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+)
+
+const (
+	// readyPollInterval is how often a starting agent's pane is captured.
+	readyPollInterval = 200 * time.Millisecond
+	// readySettle is how long a pane must stay unchanged before an agent
+	// without ready markers counts as ready.
+	readySettle = time.Second
+)
+
+// errNotReady is returned by waitReady when the agent didn't show it was
+// ready in time.
+var errNotReady = errors.New("agent did not become ready")
+
+// paneShows reports whether captured pane content contains one of markers.
+func paneShows(content string, markers []string) bool {
+	for _, marker := range markers {
+		if marker != "" && strings.Contains(content, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// readyTimeout is the longest to wait for an agent, from the
+// agent_ready_timeout setting (in seconds).
+func (m *Manager) readyTimeout() time.Duration {
+	seconds := m.cfg.AgentReadyTimeout
+	if seconds <= 0 {
+		seconds = config.DefaultAgentReadyTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// waitReady polls the pane at target until the agent in it accepts input:
+// until one of its ready markers appears, or, for agents without markers,
+// until the pane has shown the same non-empty output for readySettle. The
+// latter only suits an agent that is already running; a shell that has
+// just been given the launch command settles too.
+// Returns errNotReady after readyTimeout.
+func (m *Manager) waitReady(ctx context.Context, target string, markers []string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.readyTimeout())
+	defer cancel()
+
+	var last string
+	var since time.Time
+	for {
+		content, err := m.tmux.CapturePane(target, 0)
+		if err != nil {
+			return fmt.Errorf("capture pane: %w", err)
+		}
+		if paneShows(content, markers) {
+			return nil
+		}
+		if len(markers) == 0 && strings.TrimSpace(content) != "" {
+			now := time.Now()
+			if content != last {
+				last, since = content, now
+			} else if now.Sub(since) >= m.readySettle {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errNotReady
+			}
+			return ctx.Err()
+		case <-time.After(m.readyPoll):
+		}
+	}
+}
+
+// sendWhenReady types text into an agent's pane once the agent accepts
+// input. If it doesn't show it's ready in time, the text is sent anyway
+// with a warning, as the agent may just be slow or unrecognized.
+func (m *Manager) sendWhenReady(ctx context.Context, target string, a agent.Agent, text string) error {
+	err := m.waitReady(ctx, target, agent.ReadyMarkers(a))
+	if errors.Is(err, errNotReady) {
+		fmt.Fprintf(m.out, "warning: %s did not show it was ready within %s; sending anyway\n", a.Name(), m.readyTimeout())
+	} else if err != nil {
+		return err
+	}
+	return m.tmux.SendText(target, text, true)
+}
@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/agent"
+)
+
+func TestPaneShows(t *testing.T) {
+	markers := agent.NewClaude().ReadyMarkers()
+	if paneShows("✻ Welcome to Claude Code!\n", markers) {
+		t.Fatalf("expected welcome banner alone not to count as ready")
+	}
+	if !paneShows("╭──╮\n│ >  │\n╰──╯\n  ? for shortcuts\n", markers) {
+		t.Fatalf("expected shortcuts hint to count as ready")
+	}
+	if paneShows("anything", []string{""}) {
+		t.Fatalf("expected empty marker to be ignored")
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, t.TempDir(), stub)
+	mgr.cfg.AgentReadyTimeout = 1
+	ctx := context.Background()
+
+	stub.panes = map[string]string{"s:0.0": "  ? for shortcuts"}
+	if err := mgr.waitReady(ctx, "s:0.0", agent.NewClaude().ReadyMarkers()); err != nil {
+		t.Fatalf("waitReady with marker: %v", err)
+	}
+	if stub.captures != 1 {
+		t.Fatalf("expected one capture for a ready pane, got %d", stub.captures)
+	}
+
+	// Without markers, output that stops changing is ready; a blank pane
+	// never is.
+	stub.panes = map[string]string{"s:0.0": "aider> "}
+	if err := mgr.waitReady(ctx, "s:0.0", nil); err != nil {
+		t.Fatalf("waitReady settled: %v", err)
+	}
+	stub.panes = map[string]string{}
+	if err := mgr.waitReady(ctx, "s:0.0", nil); !errors.Is(err, errNotReady) {
+		t.Fatalf("waitReady on blank pane = %v, want errNotReady", err)
+	}
+}
+
+func TestSendWhenReadySendsAfterTimeout(t *testing.T) {
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, t.TempDir(), stub)
+	mgr.cfg.AgentReadyTimeout = 1
+	var out bytes.Buffer
+	mgr.SetOutput(&out)
+
+	if err := mgr.sendWhenReady(context.Background(), "s:0.0", agent.NewClaude(), "hello"); err != nil {
+		t.Fatalf("sendWhenReady: %v", err)
+	}
+	if keys := stub.sent["s:0.0"]; len(keys) != 1 || keys[0] != "hello" {
+		t.Fatalf("expected keys to be sent anyway, got %v", keys)
+	}
+	if !strings.Contains(out.String(), "did not show it was ready") {
+		t.Fatalf("expected a warning, got %q", out.String())
+	}
+}
@@ -17,7 +17,7 @@ import (
 const (
 	registryFileName = "workspaces.json"
 	lockFileName     = "workspaces.json.lock"
-	CurrentVersion   = 1
+	CurrentVersion   = 2
 	lockRetry        = 50 * time.Millisecond
 )
 
@@ -33,6 +33,42 @@ type Workspace struct {
 	TmuxSession    string    `json:"tmux_session"`
 	CreatedAt      time.Time `json:"created_at"`
 	LastAccessedAt time.Time `json:"last_accessed_at"`
+	// Message is the initial prompt given to `ccw new -m`.
+	Message string       `json:"message,omitempty"`
+	PR      *PullRequest `json:"pr,omitempty"`
+	// ExternalBranch marks a branch ccw checked out rather than created, so
+	// removing the workspace keeps the branch.
+	ExternalBranch bool `json:"external_branch,omitempty"`
+	// PRRef is the forge ref (e.g. refs/pull/12/head) a fork PR's branch was
+	// fetched from into a local branch ccw named. That branch is ccw's, so
+	// removing the workspace deletes it, locally only.
+	PRRef string `json:"pr_ref,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+	// Notes is a free-text description. Defaults to Message.
+	Notes string `json:"notes,omitempty"`
+	// AgentSessions holds conversation IDs for agents other than claude,
+	// keyed by agent name, so they can be resumed too.
+	AgentSessions map[string]string `json:"agent_sessions,omitempty"`
+	// Archived workspaces keep their branch and registry entry but have no
+	// worktree or tmux session.
+	Archived   bool      `json:"archived,omitempty"`
+	ArchivedAt time.Time `json:"archived_at,omitzero"`
+	// Parent is the ID of the workspace this one is stacked on; its branch
+	// is BaseBranch.
+	Parent string `json:"parent,omitempty"`
+	// ForkPoint is the last commit of a parent that was removed while this
+	// workspace was stacked on it. The next sync replays only the commits
+	// after it onto the new base, then clears it.
+	ForkPoint string `json:"fork_point,omitempty"`
+}
+
+// PullRequest is the PR/MR opened for a workspace, as last seen by ccw.
+type PullRequest struct {
+	Number    int       `json:"number"`
+	URL       string    `json:"url"`
+	State     string    `json:"state"`
+	CheckedAt time.Time `json:"checked_at"`
 }
 
 type Registry struct {
@@ -147,8 +183,14 @@ func (s *Store) load() (Registry, error) {
 		return Registry{}, fmt.Errorf("read registry: %w", err)
 	}
 
-	var reg Registry
-	if err := json.Unmarshal(data, &reg); err != nil {
+	// Migrate in memory only: Read holds just a shared lock. The upgraded
+	// document reaches disk, with a backup of the old one, on the next save
+	// under Update's exclusive lock.
+	migrated, _, err := storage.Migrate(data, CurrentVersion, registryMigrations)
+	if err != nil {
+		if errors.Is(err, storage.ErrNewerVersion) {
+			return Registry{}, fmt.Errorf("%w: %v", ErrUnsupportedVersion, err)
+		}
 		backupReg, _, backupErr := s.loadLatestBackup()
 		if backupErr == nil {
 			// Recover silently from latest backup.
@@ -157,6 +199,11 @@ func (s *Store) load() (Registry, error) {
 		return Registry{}, fmt.Errorf("parse registry and no usable backup: %w", err)
 	}
 
+	var reg Registry
+	if err := json.Unmarshal(migrated, &reg); err != nil {
+		return Registry{}, fmt.Errorf("parse registry: %w", err)
+	}
+
 	if reg.Version != CurrentVersion {
 		return Registry{}, ErrUnsupportedVersion
 	}
@@ -190,13 +237,8 @@ func (s *Store) save(reg Registry) error {
 		return fmt.Errorf("encode registry: %w", err)
 	}
 
-	tmpPath := s.registryPath() + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
-		return fmt.Errorf("write temp registry: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, s.registryPath()); err != nil {
-		return fmt.Errorf("atomically write registry: %w", err)
+	if err := storage.WriteFileAtomic(s.registryPath(), data, 0o644); err != nil {
+		return fmt.Errorf("write registry: %w", err)
 	}
 
 	return nil
@@ -235,6 +277,12 @@ func (s *Store) loadLatestBackup() (Registry, string, error) {
 		return Registry{}, "", err
 	}
 
+	// Backups can predate the last migration.
+	data, _, err = storage.Migrate(data, CurrentVersion, registryMigrations)
+	if err != nil {
+		return Registry{}, "", err
+	}
+
 	var reg Registry
 	if err := json.Unmarshal(data, &reg); err != nil {
 		return Registry{}, "", err
@@ -251,7 +299,7 @@ func (r *Registry) Add(id string, ws Workspace) error {
 	}
 
 	if _, exists := r.Workspaces[id]; exists {
-		return fmt.Errorf("workspace %s already exists", id)
+		return withCode(CodeWorkspaceExists, fmt.Errorf("workspace %s already exists", id))
 	}
 
 	r.Workspaces[id] = ws
@@ -3,6 +3,7 @@ package workspace
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -287,3 +288,72 @@ func TestRegistryConcurrentUpdates(t *testing.T) {
 		t.Fatalf("expected 5 workspaces, got %d", len(reg.Workspaces))
 	}
 }
+
+func TestRegistryMigratesV1(t *testing.T) {
+	store := newTestStore(t, 200*time.Millisecond)
+	if err := os.MkdirAll(store.root, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	v1 := `{
+  "version": 1,
+  "workspaces": {
+    "demo/feature/a": {"repo": "demo", "branch": "feature/a", "message": "fix the login bug"},
+    "demo/feature/b": {"repo": "demo", "branch": "feature/b"}
+  }
+}`
+	if err := os.WriteFile(store.registryPath(), []byte(v1), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	reg, err := store.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if reg.Version != CurrentVersion {
+		t.Fatalf("expected version %d, got %d", CurrentVersion, reg.Version)
+	}
+	if ws := reg.Workspaces["demo/feature/a"]; ws.Notes != "fix the login bug" || ws.Message != "fix the login bug" {
+		t.Fatalf("expected message to become notes, got %+v", ws)
+	}
+	if ws := reg.Workspaces["demo/feature/b"]; ws.Notes != "" || ws.Branch != "feature/b" {
+		t.Fatalf("unexpected workspace: %+v", ws)
+	}
+
+	// Reading leaves the file alone; the next update writes the upgrade.
+	data, err := os.ReadFile(store.registryPath())
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != v1 {
+		t.Fatalf("expected Read not to rewrite the file, got %s", data)
+	}
+	if err := store.Update(context.Background(), func(*Registry) error { return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	data, err = os.ReadFile(store.registryPath())
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), fmt.Sprintf(`"version": %d`, CurrentVersion)) || !strings.Contains(string(data), `"notes": "fix the login bug"`) {
+		t.Fatalf("expected upgraded file on disk, got %s", data)
+	}
+	backups, _ := filepath.Glob(store.registryPath() + ".bak-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected the v1 file to be backed up, got %v", backups)
+	}
+}
+
+func TestRegistryNewerVersionIsRejected(t *testing.T) {
+	store := newTestStore(t, 200*time.Millisecond)
+	if err := os.MkdirAll(store.root, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	data := fmt.Sprintf(`{"version": %d, "workspaces": {}}`, CurrentVersion+1)
+	if err := os.WriteFile(store.registryPath(), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := store.Read(context.Background()); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/tmux"
+)
+
+const (
+	// PaneAgent targets the first layout pane running an agent.
+	PaneAgent = "agent"
+	// PaneShell targets the first layout pane left as a plain shell.
+	PaneShell = "shell"
+
+	// defaultReplyTimeout bounds SendOptions.Wait when no timeout is given.
+	defaultReplyTimeout = 10 * time.Minute
+	// replyScrollback is how many lines of history are searched for a reply.
+	replyScrollback = 2000
+)
+
+// SendOptions configures SendToWorkspaces.
+type SendOptions struct {
+	// Pane picks the pane to type into: PaneAgent (the default), PaneShell,
+	// an agent name such as "codex", a pane name from the layout, or a pane
+	// number.
+	Pane string
+	// Wait waits for the agent to finish responding and captures its reply.
+	Wait bool
+	// Timeout bounds Wait. Zero means ten minutes.
+	Timeout time.Duration
+}
+
+// SendResult reports what SendToWorkspaces did in one workspace.
+type SendResult struct {
+	ID string `json:"id"`
+	// Pane is the layout pane the text went to.
+	Pane  string `json:"pane,omitempty"`
+	Reply string `json:"reply,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// SendToWorkspaces types text into a pane of each workspace's running
+// session, concurrently. A workspace that can't be reached gets an Error in
+// its result instead of stopping the others.
+func (m *Manager) SendToWorkspaces(ctx context.Context, ids []string, text string, opts SendOptions) ([]SendResult, error) {
+	if err := m.checkDepsByName("tmux"); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, withCode(CodeInvalidArgument, fmt.Errorf("nothing to send"))
+	}
+
+	type target struct {
+		id string
+		ws Workspace
+	}
+	targets := make([]target, 0, len(ids))
+	for _, q := range ids {
+		id, ws, err := m.lookupWorkspace(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target{id, ws})
+	}
+
+	results := make([]SendResult, len(targets))
+	forEachLimit(len(targets), statusConcurrency, func(i int) {
+		t := targets[i]
+		res := SendResult{ID: t.id}
+		pane, reply, err := m.sendToWorkspace(ctx, t.id, t.ws, text, opts)
+		res.Pane, res.Reply = pane, reply
+		if err != nil {
+			res.Error = err.Error()
+		}
+		results[i] = res
+	})
+	return results, nil
+}
+
+func (m *Manager) sendToWorkspace(ctx context.Context, id string, ws Workspace, text string, opts SendOptions) (string, string, error) {
+	if ws.Archived {
+		return "", "", fmt.Errorf("%w: run `ccw restore %s` first", ErrWorkspaceArchived, id)
+	}
+	alive, err := m.tmux.SessionExists(ws.TmuxSession)
+	if err != nil {
+		return "", "", err
+	}
+	if !alive {
+		return "", "", fmt.Errorf("session is not running; run `ccw open %s` first", id)
+	}
+
+	pane, target, a, err := m.resolvePane(ws, opts.Pane, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	var before string
+	if opts.Wait {
+		if before, err = m.tmux.CapturePane(target, 0); err != nil {
+			return pane.Name, "", fmt.Errorf("capture pane: %w", err)
+		}
+	}
+
+	if a != nil {
+		err = m.sendWhenReady(ctx, target, a, text)
+	} else {
+		err = m.tmux.SendText(target, text, true)
+	}
+	if err != nil || !opts.Wait {
+		return pane.Name, "", err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultReplyTimeout
+	}
+	if err := m.waitReply(ctx, target, a, before, timeout); err != nil {
+		return pane.Name, "", err
+	}
+	content, err := m.tmux.CapturePane(target, replyScrollback)
+	if err != nil {
+		return pane.Name, "", fmt.Errorf("capture pane: %w", err)
+	}
+	var markers []string
+	if a != nil {
+		markers = agent.ReadyMarkers(a)
+	}
+	return pane.Name, replyText(content, text, markers), nil
+}
+
+// selectPane finds the layout pane SendOptions.Pane refers to, and the agent
+// running in it (nil for a shell or plain command).
+func (m *Manager) selectPane(registry *agent.Registry, panes []config.Pane, want string) (config.Pane, agent.Agent, error) {
+	if want == "" {
+		want = PaneAgent
+	}
+	for _, p := range panes {
+		a, isAgent := registry.Get(p.Command)
+		if isAgent && m.agentMissing(a) {
+			continue
+		}
+		var match bool
+		switch want {
+		case PaneAgent:
+			match = isAgent
+		case PaneShell:
+			match = p.Command == ""
+		default:
+			match = p.Name == want || p.Command == want
+		}
+		if !match {
+			continue
+		}
+		if !isAgent {
+			a = nil
+		}
+		return p, a, nil
+	}
+	return config.Pane{}, nil, withCode(CodeInvalidArgument, fmt.Errorf("no %s pane in the workspace's layout", want))
+}
+
+// sessionPane is a pane of a running session: the layout pane it was
+// created for, and where it is now.
+type sessionPane struct {
+	config.Pane
+	Target string
+	Live   tmux.Pane
+}
+
+// sessionPanes returns the panes of ws's running session in tmux's order.
+// Panes ccw tagged when it started the session keep the name and command
+// they were started with, even if the layout has changed since; untagged
+// panes in a tagged session, such as ones split by hand, are plain shells
+// named by their number. Sessions with no tags, from older versions of ccw,
+// are matched to the current layout by position.
+func (m *Manager) sessionPanes(ws Workspace) ([]sessionPane, error) {
+	live, err := m.tmux.Panes(ws.TmuxSession)
+	if err != nil {
+		return nil, fmt.Errorf("list panes: %w", err)
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Index < live[j].Index })
+
+	tagged := false
+	for _, lp := range live {
+		tagged = tagged || lp.Name != ""
+	}
+	var layout map[string]config.Pane
+	var order []string
+	if !tagged {
+		resolved := m.layoutFor(m.existingRepoConfig(ws)).ResolvedPanes()
+		layout = make(map[string]config.Pane, len(resolved))
+		for _, p := range resolved {
+			layout[p.Name] = p
+		}
+		order = paneOrder(resolved)
+	}
+
+	panes := make([]sessionPane, 0, len(live))
+	for i, lp := range live {
+		sp := sessionPane{Live: lp, Target: lp.ID}
+		switch {
+		case lp.Name != "":
+			sp.Pane = config.Pane{Name: lp.Name, Command: lp.Launch}
+		case !tagged && i < len(order):
+			sp.Pane = layout[order[i]]
+		default:
+			sp.Pane = config.Pane{Name: strconv.Itoa(lp.Index)}
+		}
+		if sp.Target == "" {
+			sp.Target = fmt.Sprintf("%s:0.%d", ws.TmuxSession, lp.Index)
+		}
+		panes = append(panes, sp)
+	}
+	return panes, nil
+}
+
+// paneOrder returns the layout's pane names in tmux's pane index order.
+// tmux numbers a split pane right after the pane it split, so the numbering
+// follows from the layout as long as no one has moved panes by hand.
+func paneOrder(panes []config.Pane) []string {
+	if len(panes) == 0 {
+		return nil
+	}
+	order := []string{panes[0].Name}
+	prev := panes[0].Name
+	for _, p := range panes[1:] {
+		from := prev
+		if p.From != "" {
+			from = p.From
+		}
+		at := len(order)
+		for i, name := range order {
+			if name == from {
+				at = i + 1
+				break
+			}
+		}
+		order = append(order[:at], append([]string{p.Name}, order[at:]...)...)
+		prev = p.Name
+	}
+	return order
+}
+
+// errNoReply is returned by waitReply when the agent is still busy at the
+// timeout.
+var errNoReply = errors.New("timed out waiting for a reply")
+
+// waitReply waits for the pane to change from before and then come to
+// rest: no busy marker showing, a ready marker showing if the agent has
+// any, and the same content for readySettle.
+func (m *Manager) waitReply(ctx context.Context, target string, a agent.Agent, before string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var ready, busy []string
+	if a != nil {
+		ready, busy = agent.ReadyMarkers(a), agent.BusyMarkers(a)
+	}
+	var last string
+	var since time.Time
+	for {
+		content, err := m.tmux.CapturePane(target, 0)
+		if err != nil {
+			return fmt.Errorf("capture pane: %w", err)
+		}
+		idle := content != before && !paneShows(content, busy) && (len(ready) == 0 || paneShows(content, ready))
+		if !idle {
+			last = ""
+		} else if content != last {
+			last, since = content, time.Now()
+		} else if time.Since(since) >= m.readySettle {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errNoReply
+			}
+			return ctx.Err()
+		case <-time.After(m.readyPoll):
+		}
+	}
+}
+
+// replyText pulls an agent's answer out of its pane: the lines after the
+// last echo of the sent text, without the input box and hints the agent
+// draws below it.
+func replyText(content, sent string, markers []string) string {
+	lines := strings.Split(content, "\n")
+
+	echo := strings.TrimSpace(strings.SplitN(sent, "\n", 2)[0])
+	if r := []rune(echo); len(r) > 40 {
+		echo = string(r[:40])
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], echo) {
+			lines = lines[i+1:]
+			break
+		}
+	}
+
+	// Drop the agent's chrome from the bottom up: blank lines, box
+	// drawing and hint lines.
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(lines[end-1])
+		if line == "" || strings.ContainsRune("╭│╰─>", []rune(line)[0]) || paneShows(line, markers) {
+			end--
+			continue
+		}
+		break
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
+}
@@ -0,0 +1,144 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/agent"
+	"github.com/ccw/ccw/internal/config"
+	"github.com/ccw/ccw/internal/tmux"
+)
+
+func TestPaneOrderFollowsSplits(t *testing.T) {
+	panes := []config.Pane{
+		{Name: "agent", Command: "claude"},
+		{Name: "tests"},
+		{Name: "shell", From: "agent"},
+	}
+	got := strings.Join(paneOrder(panes), ",")
+	if got != "agent,shell,tests" {
+		t.Fatalf("pane order = %s", got)
+	}
+}
+
+func TestSessionPanes(t *testing.T) {
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, t.TempDir(), stub)
+	ws := Workspace{TmuxSession: "s"}
+
+	// Untagged sessions follow the default layout by position.
+	stub.livePanes = map[string][]tmux.Pane{"s": {{Index: 0}, {Index: 1, ID: "%4"}}}
+	panes, err := mgr.sessionPanes(ws)
+	if err != nil || len(panes) != 2 {
+		t.Fatalf("sessionPanes = %+v, %v", panes, err)
+	}
+	if panes[0].Name != "left" || panes[0].Command != "claude" || panes[0].Target != "s:0.0" {
+		t.Fatalf("first pane = %+v", panes[0])
+	}
+	if panes[1].Name != "right" || panes[1].Target != "%4" {
+		t.Fatalf("second pane = %+v", panes[1])
+	}
+
+	// Tags win over the layout, and a pane split by hand is a shell.
+	stub.livePanes["s"] = []tmux.Pane{
+		{Index: 1, ID: "%2", Name: "agent", Launch: "codex"},
+		{Index: 0, ID: "%1", Name: "tests", Launch: "go test ./..."},
+		{Index: 2, ID: "%3"},
+	}
+	panes, err = mgr.sessionPanes(ws)
+	if err != nil || len(panes) != 3 {
+		t.Fatalf("sessionPanes = %+v, %v", panes, err)
+	}
+	if panes[0].Name != "tests" || panes[1].Name != "agent" || panes[1].Command != "codex" || panes[1].Target != "%2" {
+		t.Fatalf("tagged panes = %+v", panes)
+	}
+	if panes[2].Name != "2" || panes[2].Command != "" {
+		t.Fatalf("untagged pane = %+v", panes[2])
+	}
+}
+
+func TestSelectPane(t *testing.T) {
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, t.TempDir(), stub)
+	panes := []config.Pane{
+		{Name: "shell"},
+		{Name: "left", Command: "claude"},
+		{Name: "right", Command: "codex"},
+	}
+	registry := agent.Default()
+
+	p, a, err := mgr.selectPane(registry, panes, "")
+	if err != nil || p.Name != "left" || a == nil || a.Name() != "claude" {
+		t.Fatalf("default pane = %+v, %v, %v", p, a, err)
+	}
+	if p, a, _ := mgr.selectPane(registry, panes, PaneShell); p.Name != "shell" || a != nil {
+		t.Fatalf("shell pane = %+v, %v", p, a)
+	}
+
+	mgr.agentAvailable["codex"] = false
+	if _, _, err := mgr.selectPane(registry, panes, "codex"); ErrorCode(err) != CodeInvalidArgument {
+		t.Fatalf("expected missing codex to be rejected, got %v", err)
+	}
+	mgr.agentAvailable["codex"] = true
+	if p, _, err := mgr.selectPane(registry, panes, "codex"); err != nil || p.Name != "right" {
+		t.Fatalf("codex pane = %+v, %v", p, err)
+	}
+}
+
+func TestReplyText(t *testing.T) {
+	content := `✻ Welcome to Claude Code!
+
+> run the tests and summarize
+
+⏺ Bash(go test ./...)
+  ⎿  ok  	example.com/x	0.01s
+
+⏺ All tests pass.
+
+╭──────────────────────────╮
+│ >                        │
+╰──────────────────────────╯
+  ? for shortcuts
+`
+	got := replyText(content, "run the tests and summarize", agent.NewClaude().ReadyMarkers())
+	want := "⏺ Bash(go test ./...)\n  ⎿  ok  \texample.com/x\t0.01s\n\n⏺ All tests pass."
+	if got != want {
+		t.Fatalf("replyText = %q, want %q", got, want)
+	}
+}
+
+func TestSendToWorkspacesWaitsForReply(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	stub := newStubTmux()
+	mgr := newManagerForTest(t, reposRoot, stub)
+	ctx := context.Background()
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/send", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	target := ws.TmuxSession + ":0.0"
+	stub.panes = map[string]string{target: "╭─╮\n│ > │\n╰─╯\n  ? for shortcuts\n"}
+	stub.afterSend = map[string]string{target: "> summarize\n\n⏺ Done.\n\n  ? for shortcuts\n"}
+
+	results, err := mgr.SendToWorkspaces(ctx, []string{"feature/send"}, "summarize", SendOptions{Wait: true})
+	if err != nil {
+		t.Fatalf("SendToWorkspaces: %v", err)
+	}
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Pane != "left" || results[0].Reply != "⏺ Done." {
+		t.Fatalf("unexpected result: %+v", results[0])
+	}
+	if keys := stub.sent[target]; keys[len(keys)-1] != "summarize" {
+		t.Fatalf("expected text typed into the claude pane, got %v", keys)
+	}
+
+	delete(stub.sessions, ws.TmuxSession)
+	results, err = mgr.SendToWorkspaces(ctx, []string{"feature/send"}, "again", SendOptions{})
+	if err != nil || results[0].Error == "" {
+		t.Fatalf("expected an error result for a stopped session, got %+v, %v", results, err)
+	}
+}
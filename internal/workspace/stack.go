@@ -0,0 +1,130 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ccw/ccw/internal/forge"
+	"github.com/ccw/ccw/internal/git"
+)
+
+// Children returns the IDs of the workspaces stacked directly on id,
+// sorted.
+func (r *Registry) Children(id string) []string {
+	var children []string
+	for childID, ws := range r.Workspaces {
+		if ws.Parent == id {
+			children = append(children, childID)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// retargetChildren re-bases the workspaces stacked on a removed workspace
+// onto what it was based on: its own parent, if it was stacked too, or its
+// base branch. tip is the removed branch's last commit, recorded as the
+// children's fork point so the next sync replays only their own commits.
+// Returns the re-targeted IDs.
+func (r *Registry) retargetChildren(removedID string, removed Workspace, tip string) []string {
+	children := r.Children(removedID)
+	for _, id := range children {
+		ws := r.Workspaces[id]
+		ws.Parent = removed.Parent
+		ws.BaseBranch = removed.BaseBranch
+		if tip != "" {
+			ws.ForkPoint = tip
+		}
+		r.Workspaces[id] = ws
+	}
+	return children
+}
+
+// withDescendants returns ids followed by every workspace stacked on them,
+// directly or not, ordered so each parent comes before its children.
+// Unknown IDs are dropped.
+func (r *Registry) withDescendants(ids []string) []string {
+	seen := make(map[string]bool)
+	var order []string
+	var visit func(id string)
+	visit = func(id string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		order = append(order, id)
+		for _, child := range r.Children(id) {
+			visit(child)
+		}
+	}
+
+	// Start from the roots among ids so a parent listed after its child
+	// still comes first.
+	requested := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+	for _, id := range ids {
+		if _, ok := r.Workspaces[id]; !ok || r.hasAncestorIn(id, requested) {
+			continue
+		}
+		visit(id)
+	}
+	// Only a cycle in a hand-edited registry leaves requested IDs unvisited.
+	for _, id := range ids {
+		if _, ok := r.Workspaces[id]; ok {
+			visit(id)
+		}
+	}
+	return order
+}
+
+// hasAncestorIn reports whether any workspace id is stacked on, directly or
+// not, is in set.
+func (r *Registry) hasAncestorIn(id string, set map[string]bool) bool {
+	seen := map[string]bool{id: true}
+	for parent := r.Workspaces[id].Parent; parent != "" && !seen[parent]; parent = r.Workspaces[parent].Parent {
+		if set[parent] {
+			return true
+		}
+		seen[parent] = true
+	}
+	return false
+}
+
+// retargetChildPRs moves the open PRs of the workspaces stacked on a
+// removed workspace onto the branch they are re-targeted onto, before the
+// removed branch is deleted from origin and the forge closes them. An
+// error means some PR may still target the removed branch.
+func (m *Manager) retargetChildPRs(ctx context.Context, removedID string, removed Workspace) error {
+	reg, err := m.regStore.Read(ctx)
+	if err != nil {
+		return err
+	}
+	children := reg.Children(removedID)
+	if len(children) == 0 {
+		return nil
+	}
+
+	onto := removed.BaseBranch
+	if onto == "" {
+		if onto, err = git.DetectDefaultBranch(ctx, removed.RepoPath); err != nil {
+			return err
+		}
+	}
+	provider, err := m.forgeFor(removed.RepoPath)
+	if err != nil {
+		return err
+	}
+	retargeter, ok := provider.(forge.PRRetargeter)
+	if !ok {
+		return fmt.Errorf("ccw can't re-target %s pull requests; move them onto %s by hand", provider.Kind(), onto)
+	}
+	for _, id := range children {
+		if err := retargeter.RetargetPRs(ctx, reg.Workspaces[id].Branch, removed.Branch, onto); err != nil {
+			return fmt.Errorf("re-target the pull requests of %s: %w", id, err)
+		}
+	}
+	return nil
+}
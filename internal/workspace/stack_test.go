@@ -0,0 +1,174 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccw/ccw/internal/forge"
+	"github.com/ccw/ccw/internal/git"
+)
+
+func TestStackedWorkspaces(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	var out strings.Builder
+	mgr.out = &out
+	ctx := context.Background()
+	repoDir := filepath.Join(reposRoot, repoName)
+
+	commitFile := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		runGitCmd(t, dir, "add", name)
+		runGitCmd(t, dir, "commit", "-m", "edit "+name)
+	}
+
+	parent, err := mgr.CreateWorkspace(ctx, repoName, "feature/a", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace parent: %v", err)
+	}
+	parentID := WorkspaceID(repoName, "feature/a")
+	// Unpushed work on the parent must still end up in the child.
+	commitFile(parent.WorktreePath, "a.txt", "a\n")
+
+	if _, err := mgr.CreateWorkspace(ctx, repoName, "feature/b", CreateOptions{NoAttach: true, On: parentID, BaseBranch: "main"}); err == nil {
+		t.Fatalf("expected --on with --base to be rejected")
+	}
+	child, err := mgr.CreateWorkspace(ctx, repoName, "feature/b", CreateOptions{NoFetch: true, NoAttach: true, On: parentID})
+	if err != nil {
+		t.Fatalf("CreateWorkspace child: %v", err)
+	}
+	childID := WorkspaceID(repoName, "feature/b")
+	if child.Parent != parentID || child.BaseBranch != "feature/a" {
+		t.Fatalf("unexpected stack fields: parent=%q base=%q", child.Parent, child.BaseBranch)
+	}
+	if _, err := os.Stat(filepath.Join(child.WorktreePath, "a.txt")); err != nil {
+		t.Fatalf("expected the child to start from the parent's local branch: %v", err)
+	}
+	commitFile(child.WorktreePath, "b.txt", "b\n")
+
+	// Syncing the parent cascades to the child.
+	commitFile(repoDir, "main.txt", "main\n")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+	results, err := mgr.SyncWorkspaces(ctx, []string{parentID}, SyncOptions{})
+	if err != nil {
+		t.Fatalf("SyncWorkspaces: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != parentID || results[1].ID != childID {
+		t.Fatalf("expected the parent then the child, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Status != SyncUpdated {
+			t.Fatalf("expected %s to be updated, got %+v", r.ID, r)
+		}
+	}
+	if results[1].Base != "feature/a" || results[1].Ahead != 1 || results[1].Behind != 0 {
+		t.Fatalf("expected the child to sit one commit on top of its parent, got %+v", results[1])
+	}
+
+	// Squash-merge the parent and remove it: the child moves onto main.
+	runGitCmd(t, repoDir, "merge", "--squash", "feature/a")
+	runGitCmd(t, repoDir, "commit", "-m", "feature a (squashed)")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+	if err := mgr.RemoveWorkspace(ctx, parentID, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	info, err := mgr.WorkspaceInfo(ctx, childID)
+	if err != nil {
+		t.Fatalf("WorkspaceInfo: %v", err)
+	}
+	// The parent had no explicit base, so neither does the child now: the
+	// default branch is detected when needed.
+	if info.Workspace.Parent != "" || info.Workspace.BaseBranch != "" || info.Workspace.ForkPoint == "" {
+		t.Fatalf("expected the child to be re-targeted onto the default branch, got %+v", info.Workspace)
+	}
+	if !strings.Contains(out.String(), "re-targeted "+childID) {
+		t.Fatalf("expected a re-target notice, got %q", out.String())
+	}
+
+	results, err = mgr.SyncWorkspaces(ctx, []string{childID}, SyncOptions{})
+	if err != nil {
+		t.Fatalf("SyncWorkspaces: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != SyncUpdated || results[0].Ahead != 1 {
+		t.Fatalf("expected only the child's own commit replayed onto main, got %+v", results)
+	}
+	if ahead, _, err := git.AheadBehind(ctx, repoDir, "feature/b", "origin/main"); err != nil || ahead != 1 {
+		t.Fatalf("expected feature/b one commit ahead of main, got %d (%v)", ahead, err)
+	}
+	info, _ = mgr.WorkspaceInfo(ctx, childID)
+	if info.Workspace.ForkPoint != "" {
+		t.Fatalf("expected the fork point to be cleared after syncing")
+	}
+}
+
+func TestWithDescendants(t *testing.T) {
+	reg := Registry{Workspaces: map[string]Workspace{
+		"r/a": {},
+		"r/b": {Parent: "r/a"},
+		"r/c": {Parent: "r/b"},
+		"r/d": {Parent: "r/a"},
+		"r/x": {},
+	}}
+	if got := strings.Join(reg.withDescendants([]string{"r/c", "r/a"}), ","); got != "r/a,r/b,r/c,r/d" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+	if got := strings.Join(reg.withDescendants([]string{"r/b", "r/missing"}), ","); got != "r/b,r/c" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+func TestRemoveWorkspaceRetargetsStackedPRs(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	var out strings.Builder
+	mgr.out = &out
+	ctx := context.Background()
+	repoDir := filepath.Join(reposRoot, repoName)
+
+	stack := func(parent, child string) string {
+		t.Helper()
+		ws, err := mgr.CreateWorkspace(ctx, repoName, parent, CreateOptions{NoFetch: true, NoAttach: true})
+		if err != nil {
+			t.Fatalf("CreateWorkspace %s: %v", parent, err)
+		}
+		runGitCmd(t, ws.WorktreePath, "push", "-u", "origin", parent)
+		parentID := WorkspaceID(repoName, parent)
+		if _, err := mgr.CreateWorkspace(ctx, repoName, child, CreateOptions{NoFetch: true, NoAttach: true, On: parentID}); err != nil {
+			t.Fatalf("CreateWorkspace %s: %v", child, err)
+		}
+		return parentID
+	}
+
+	// The child's PR moves onto main before its base branch is deleted.
+	stub := &stubForge{}
+	mgr.forges = map[string]forge.Provider{repoDir: stub}
+	parentID := stack("feature/a", "feature/b")
+	if err := mgr.RemoveWorkspace(ctx, parentID, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if len(stub.retargeted) != 1 || stub.retargeted[0] != "feature/b feature/a main" {
+		t.Fatalf("expected feature/b's PR re-targeted onto main, got %v", stub.retargeted)
+	}
+	if exists, _ := git.RemoteBranchExists(repoDir, "origin", "feature/a"); exists {
+		t.Fatalf("expected origin/feature/a to be deleted")
+	}
+
+	// A forge that can't re-target keeps the branch the PRs are based on.
+	mgr.forges = map[string]forge.Provider{repoDir: struct{ forge.Provider }{stub}}
+	parentID = stack("feature/c", "feature/d")
+	if err := mgr.RemoveWorkspace(ctx, parentID, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if exists, _ := git.RemoteBranchExists(repoDir, "origin", "feature/c"); !exists {
+		t.Fatalf("expected origin/feature/c to be kept for the stacked PR")
+	}
+	if !strings.Contains(out.String(), "kept origin/feature/c") {
+		t.Fatalf("expected a warning about the kept branch, got %q", out.String())
+	}
+}
@@ -0,0 +1,257 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+// SyncOptions configures SyncWorkspaces.
+type SyncOptions struct {
+	// Strategy is git.SyncRebase or git.SyncMerge. Empty uses the
+	// sync_strategy config, which defaults to rebase.
+	Strategy string
+	// Push pushes updated branches with --force-with-lease. Branches
+	// missing commits that are on origin are not synced.
+	Push    bool
+	NoFetch bool
+}
+
+// Outcomes of syncing one workspace.
+const (
+	SyncUpToDate = "up-to-date"
+	SyncUpdated  = "updated"
+	SyncConflict = "conflict"
+	SyncDirty    = "dirty"
+	// SyncBehindUpstream is a branch that would be pushed but lacks commits
+	// already on origin, such as a teammate's.
+	SyncBehindUpstream = "behind-upstream"
+	SyncSkipped        = "skipped"
+	SyncFailed         = "failed"
+)
+
+// SyncResult reports what SyncWorkspaces did to one workspace.
+type SyncResult struct {
+	ID     string `json:"id"`
+	Branch string `json:"branch"`
+	// Base is the ref the branch was synced onto, e.g. origin/main.
+	Base   string `json:"base"`
+	Status string `json:"status"`
+	// Ahead and Behind compare the branch with Base afterwards.
+	Ahead  int  `json:"ahead"`
+	Behind int  `json:"behind"`
+	Pushed bool `json:"pushed"`
+	// Conflicts lists the conflicting files when Status is SyncConflict.
+	Conflicts []string `json:"conflicts,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// Failed reports whether the workspace still needs attention.
+func (r SyncResult) Failed() bool {
+	switch r.Status {
+	case SyncConflict, SyncDirty, SyncBehindUpstream, SyncFailed:
+		return true
+	}
+	return false
+}
+
+// SyncWorkspaces rebases (or merges) each workspace's branch onto its
+// updated base branch. Every repo involved is fetched once first. Dirty
+// worktrees are refused and conflicting syncs aborted; either way the
+// worktree is left untouched and the outcome recorded in its SyncResult, so
+// one bad workspace doesn't stop the rest. With no ids, every workspace is
+// synced.
+//
+// Syncs cascade down stacks: workspaces stacked on a synced one are synced
+// after it, onto its local branch, replaying only their own commits. They
+// are skipped if their parent couldn't be synced.
+func (m *Manager) SyncWorkspaces(ctx context.Context, ids []string, opts SyncOptions) ([]SyncResult, error) {
+	if err := m.checkDepsByName("git"); err != nil {
+		return nil, err
+	}
+
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = m.cfg.SyncStrategy
+	}
+	if strategy == "" {
+		strategy = git.SyncRebase
+	}
+	if strategy != git.SyncRebase && strategy != git.SyncMerge {
+		return nil, fmt.Errorf("unknown sync strategy %q (want %s or %s)", strategy, git.SyncRebase, git.SyncMerge)
+	}
+
+	reg, err := m.regStore.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := m.syncOrder(ctx, reg, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := make(map[string]error)
+	byID := make(map[string]SyncResult, len(order))
+	// oldTips holds the pre-sync tip of every branch the sync rewrote, for
+	// the workspaces stacked on it.
+	oldTips := make(map[string]string)
+	results := make([]SyncResult, 0, len(order))
+	for _, id := range order {
+		ws := reg.Workspaces[id]
+		res := SyncResult{ID: id, Branch: ws.Branch}
+		parent, stacked := reg.Workspaces[ws.Parent]
+		switch {
+		case ws.Archived:
+			res.Status = SyncSkipped
+			res.Message = "archived"
+		case stacked && byID[ws.Parent].Failed():
+			res.Status = SyncSkipped
+			res.Message = fmt.Sprintf("parent %s was not synced", ws.Parent)
+		default:
+			fetchErr, ok := fetched[ws.RepoPath]
+			if !ok && !opts.NoFetch {
+				fetchErr = git.Fetch(ws.RepoPath, true)
+				fetched[ws.RepoPath] = fetchErr
+			}
+			if fetchErr != nil {
+				res.Status = SyncFailed
+				res.Message = fmt.Sprintf("fetch: %v", fetchErr)
+				break
+			}
+
+			// A fork PR's local copy has no branch on origin to push to.
+			plan := syncPlan{strategy: strategy, push: opts.Push && ws.PRRef == "", oldBase: ws.ForkPoint}
+			if stacked {
+				plan.baseRef = parent.Branch
+				if tip, ok := oldTips[ws.Parent]; ok {
+					plan.oldBase = tip
+				}
+			}
+			before, _ := git.ResolveRef(ctx, ws.RepoPath, ws.Branch)
+			res = m.syncWorkspace(ctx, ws, res, plan)
+			if res.Status == SyncUpdated && before != "" {
+				oldTips[id] = before
+			}
+			if !res.Failed() && ws.ForkPoint != "" {
+				if _, err := m.updateWorkspace(ctx, id, func(w *Workspace) error {
+					w.ForkPoint = ""
+					return nil
+				}); err != nil {
+					fmt.Fprintf(m.out, "warning: could not clear fork point of %s: %v\n", id, err)
+				}
+			}
+		}
+		byID[id] = res
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// syncOrder resolves ids (all workspaces when empty) and adds the
+// workspaces stacked on them, parents first.
+func (m *Manager) syncOrder(ctx context.Context, reg Registry, ids []string) ([]string, error) {
+	var resolved []string
+	if len(ids) == 0 {
+		for id := range reg.Workspaces {
+			resolved = append(resolved, id)
+		}
+		sort.Strings(resolved)
+	} else {
+		for _, q := range ids {
+			id, _, err := m.lookupWorkspace(ctx, q)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, id)
+		}
+	}
+	return reg.withDescendants(resolved), nil
+}
+
+type syncPlan struct {
+	strategy string
+	push     bool
+	// baseRef overrides the workspace's base; set for stacked workspaces.
+	baseRef string
+	// oldBase is where the base used to be, if it was rewritten.
+	oldBase string
+}
+
+func (m *Manager) syncWorkspace(ctx context.Context, ws Workspace, res SyncResult, plan syncPlan) SyncResult {
+	fail := func(err error) SyncResult {
+		res.Status = SyncFailed
+		res.Message = err.Error()
+		return res
+	}
+
+	changes, err := m.uncommittedWork(ctx, ws)
+	if err != nil {
+		return fail(err)
+	}
+	if len(changes) > 0 {
+		res.Status = SyncDirty
+		res.Message = fmt.Sprintf("uncommitted changes: %s", summarizePaths(changes, 5))
+		return res
+	}
+
+	// Pushing would overwrite origin/<branch>, so it must hold nothing the
+	// branch doesn't. Empty means the branch isn't on origin yet.
+	var upstream string
+	if plan.push {
+		upstream, _ = git.ResolveRef(ctx, ws.RepoPath, "refs/remotes/origin/"+ws.Branch)
+		if upstream != "" {
+			_, behind, err := git.AheadBehind(ctx, ws.RepoPath, ws.Branch, upstream)
+			if err != nil {
+				return fail(err)
+			}
+			if behind > 0 {
+				res.Status = SyncBehindUpstream
+				res.Message = fmt.Sprintf("origin/%s has %d commit(s) the branch lacks; pull them first", ws.Branch, behind)
+				return res
+			}
+		}
+	}
+
+	baseRef := plan.baseRef
+	if baseRef == "" {
+		// Keep the local base branch in step too, for tools that look at it.
+		if ws.BaseBranch != "" {
+			_ = git.SyncLocalBranch(ws.RepoPath, ws.BaseBranch)
+		}
+		baseRef, err = git.BaseRef(ctx, ws.RepoPath, ws.BaseBranch)
+		if err != nil {
+			return fail(err)
+		}
+	}
+	res.Base = baseRef
+
+	updated, err := git.SyncWorktreeOnto(ctx, ws.WorktreePath, baseRef, plan.oldBase, plan.strategy)
+	var conflict *git.ConflictError
+	if errors.As(err, &conflict) {
+		res.Status = SyncConflict
+		res.Conflicts = conflict.Files
+		res.Message = fmt.Sprintf("%s aborted; resolve by hand in %s", plan.strategy, ws.WorktreePath)
+		return res
+	}
+	if err != nil {
+		return fail(err)
+	}
+	res.Status = SyncUpToDate
+	if updated {
+		res.Status = SyncUpdated
+	}
+	res.Ahead, res.Behind, _ = git.AheadBehind(ctx, ws.RepoPath, ws.Branch, baseRef)
+
+	if plan.push && updated {
+		if err := git.PushForceWithLease(ws.WorktreePath, ws.Branch, upstream); err != nil {
+			res.Status = SyncFailed
+			res.Message = fmt.Sprintf("push: %v", err)
+			return res
+		}
+		res.Pushed = true
+	}
+	return res
+}
@@ -0,0 +1,140 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccw/ccw/internal/git"
+)
+
+func TestSyncWorkspaces(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+	repoDir := filepath.Join(reposRoot, repoName)
+
+	commitFile := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		runGitCmd(t, dir, "add", name)
+		runGitCmd(t, dir, "commit", "-m", "edit "+name)
+	}
+
+	commitFile(repoDir, "shared.txt", "base\n")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+
+	var paths []string
+	for _, branch := range []string{"feature/clean", "feature/conflict", "feature/dirty"} {
+		ws, err := mgr.CreateWorkspace(ctx, repoName, branch, CreateOptions{NoFetch: true, NoAttach: true})
+		if err != nil {
+			t.Fatalf("CreateWorkspace %s: %v", branch, err)
+		}
+		paths = append(paths, ws.WorktreePath)
+	}
+	commitFile(paths[0], "clean.txt", "clean\n")
+	commitFile(paths[1], "shared.txt", "feature side\n")
+	if err := os.WriteFile(filepath.Join(paths[2], "shared.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mgr.cfg.SyncStrategy = git.SyncMerge
+
+	commitFile(repoDir, "shared.txt", "main side\n")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+
+	results, err := mgr.SyncWorkspaces(ctx, nil, SyncOptions{Push: true})
+	if err != nil {
+		t.Fatalf("SyncWorkspaces: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+
+	clean, conflict, dirty := results[0], results[1], results[2]
+	if clean.Status != SyncUpdated || clean.Base != "origin/main" || clean.Behind != 0 || clean.Ahead != 2 || !clean.Pushed {
+		t.Fatalf("unexpected clean result: %+v", clean)
+	}
+	if ahead, behind, err := git.AheadBehind(ctx, repoDir, "feature/clean", "origin/feature/clean"); err != nil || ahead != 0 || behind != 0 {
+		t.Fatalf("expected the synced branch to be pushed, got +%d/-%d (%v)", ahead, behind, err)
+	}
+	if conflict.Status != SyncConflict || len(conflict.Conflicts) != 1 || conflict.Conflicts[0] != "shared.txt" || !conflict.Failed() {
+		t.Fatalf("unexpected conflict result: %+v", conflict)
+	}
+	if dirty.Status != SyncDirty || !dirty.Failed() {
+		t.Fatalf("unexpected dirty result: %+v", dirty)
+	}
+
+	results, err = mgr.SyncWorkspaces(ctx, []string{WorkspaceID(repoName, "feature/clean")}, SyncOptions{NoFetch: true})
+	if err != nil {
+		t.Fatalf("SyncWorkspaces: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != SyncUpToDate {
+		t.Fatalf("expected the clean workspace to be up to date, got %+v", results)
+	}
+
+	if _, err := mgr.SyncWorkspaces(ctx, nil, SyncOptions{Strategy: "squash"}); err == nil {
+		t.Fatalf("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestSyncKeepsCommitsOnlyOnOrigin(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+	repoDir := filepath.Join(reposRoot, repoName)
+
+	commitFile := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		runGitCmd(t, dir, "add", name)
+		runGitCmd(t, dir, "commit", "-m", "edit "+name)
+	}
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/team", CreateOptions{NoFetch: true, NoAttach: true})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	commitFile(ws.WorktreePath, "mine.txt", "mine\n")
+	runGitCmd(t, ws.WorktreePath, "push", "-u", "origin", "feature/team")
+	pushed, err := git.ResolveRef(ctx, repoDir, "feature/team")
+	if err != nil {
+		t.Fatalf("ResolveRef: %v", err)
+	}
+
+	// A teammate adds to the branch, and main moves on.
+	origin := filepath.Join(reposRoot, "origin.git")
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGitCmd(t, reposRoot, "clone", "-q", "-b", "feature/team", origin, clone)
+	runGitCmd(t, clone, "config", "user.email", "teammate@example.com")
+	runGitCmd(t, clone, "config", "user.name", "Teammate")
+	commitFile(clone, "theirs.txt", "theirs\n")
+	runGitCmd(t, clone, "push", "origin", "feature/team")
+	theirs, err := git.ResolveRef(ctx, clone, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRef: %v", err)
+	}
+	commitFile(repoDir, "main.txt", "main\n")
+	runGitCmd(t, repoDir, "push", "origin", "main")
+
+	results, err := mgr.SyncWorkspaces(ctx, []string{WorkspaceID(repoName, "feature/team")}, SyncOptions{Push: true})
+	if err != nil {
+		t.Fatalf("SyncWorkspaces: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != SyncBehindUpstream || results[0].Pushed || !results[0].Failed() {
+		t.Fatalf("expected the branch to be held back, got %+v", results)
+	}
+	if got, _ := git.ResolveRef(ctx, origin, "feature/team"); got != theirs {
+		t.Fatalf("expected the teammate's commit to stay on origin, got %q", got)
+	}
+
+	// The lease is taken on the commit the branch was checked against, not
+	// on whatever the last fetch left in origin/<branch>.
+	if err := git.PushForceWithLease(ws.WorktreePath, "feature/team", pushed); err == nil {
+		t.Fatalf("expected a push with a stale lease to be rejected")
+	}
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// normalizeTags trims, lowercases, de-duplicates and sorts tags, rejecting
+// ones that couldn't be typed back on a command line.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("tag cannot be empty")
+		}
+		if strings.ContainsAny(tag, " \t\n,") {
+			return nil, fmt.Errorf("tag %q cannot contain spaces or commas", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// HasTags reports whether the workspace carries every one of tags.
+func (ws Workspace) HasTags(tags ...string) bool {
+	for _, want := range tags {
+		want = strings.ToLower(strings.TrimSpace(want))
+		found := false
+		for _, have := range ws.Tags {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateTags adds and removes tags on a workspace and returns the result.
+// Removing a tag the workspace doesn't have is not an error.
+func (m *Manager) UpdateTags(ctx context.Context, id string, add, remove []string) (Workspace, error) {
+	add, err := normalizeTags(add)
+	if err != nil {
+		return Workspace{}, err
+	}
+	remove, err = normalizeTags(remove)
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	return m.updateWorkspace(ctx, id, func(ws *Workspace) error {
+		drop := make(map[string]bool, len(remove))
+		for _, tag := range remove {
+			drop[tag] = true
+		}
+		var kept []string
+		for _, tag := range append(ws.Tags, add...) {
+			if !drop[tag] {
+				kept = append(kept, tag)
+			}
+		}
+		tags, err := normalizeTags(kept)
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			tags = nil
+		}
+		ws.Tags = tags
+		return nil
+	})
+}
+
+// SetNotes replaces a workspace's notes. Empty notes clear them.
+func (m *Manager) SetNotes(ctx context.Context, id, notes string) (Workspace, error) {
+	return m.updateWorkspace(ctx, id, func(ws *Workspace) error {
+		ws.Notes = strings.TrimSpace(notes)
+		return nil
+	})
+}
+
+// updateWorkspace resolves id and applies fn to the stored workspace under
+// the registry lock.
+func (m *Manager) updateWorkspace(ctx context.Context, id string, fn func(*Workspace) error) (Workspace, error) {
+	resolvedID, _, err := m.lookupWorkspace(ctx, id)
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	var updated Workspace
+	err = m.regStore.Update(ctx, func(reg *Registry) error {
+		ws, ok := reg.Workspaces[resolvedID]
+		if !ok {
+			return fmt.Errorf("workspace %s not found", resolvedID)
+		}
+		if err := fn(&ws); err != nil {
+			return err
+		}
+		reg.Workspaces[resolvedID] = ws
+		updated = ws
+		return nil
+	})
+	return updated, err
+}
@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Review", "urgent", "review"})
+	if err != nil {
+		t.Fatalf("normalizeTags: %v", err)
+	}
+	if strings.Join(tags, ",") != "review,urgent" {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	for _, bad := range []string{"", "two words", "a,b"} {
+		if _, err := normalizeTags([]string{bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestWorkspaceTagsAndNotes(t *testing.T) {
+	reposRoot, repoName := initRepoForManager(t)
+	mgr := newManagerForTest(t, reposRoot, newStubTmux())
+	ctx := context.Background()
+	id := WorkspaceID(repoName, "feature/tags")
+
+	ws, err := mgr.CreateWorkspace(ctx, repoName, "feature/tags", CreateOptions{
+		NoFetch:  true,
+		NoAttach: true,
+		Message:  "fix the flaky login test",
+		Tags:     []string{"Bug", "auth"},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if strings.Join(ws.Tags, ",") != "auth,bug" {
+		t.Fatalf("unexpected tags: %v", ws.Tags)
+	}
+	if ws.Notes != "fix the flaky login test" {
+		t.Fatalf("expected the message to be the default notes, got %q", ws.Notes)
+	}
+	if !ws.HasTags("bug") || !ws.HasTags("AUTH", "bug") || ws.HasTags("bug", "ui") {
+		t.Fatalf("unexpected HasTags results for %v", ws.Tags)
+	}
+
+	ws, err = mgr.UpdateTags(ctx, id, []string{"review"}, []string{"bug", "missing"})
+	if err != nil {
+		t.Fatalf("UpdateTags: %v", err)
+	}
+	if strings.Join(ws.Tags, ",") != "auth,review" {
+		t.Fatalf("unexpected tags after update: %v", ws.Tags)
+	}
+
+	if _, err := mgr.SetNotes(ctx, id, "  waiting on design  "); err != nil {
+		t.Fatalf("SetNotes: %v", err)
+	}
+	info, err := mgr.WorkspaceInfo(ctx, id)
+	if err != nil {
+		t.Fatalf("WorkspaceInfo: %v", err)
+	}
+	if info.Workspace.Notes != "waiting on design" || info.Workspace.Message != "fix the flaky login test" {
+		t.Fatalf("unexpected notes/message: %+v", info.Workspace)
+	}
+	if _, got, err := mgr.GetWorkspace(ctx, id); err != nil || got.Notes != "waiting on design" {
+		t.Fatalf("GetWorkspace notes = %q, %v", got.Notes, err)
+	}
+
+	if ws, err = mgr.UpdateTags(ctx, id, nil, []string{"auth", "review"}); err != nil || ws.Tags != nil {
+		t.Fatalf("expected all tags removed, got %v (%v)", ws.Tags, err)
+	}
+}